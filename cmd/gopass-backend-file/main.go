@@ -0,0 +1,40 @@
+// Command gopass-backend-file is the reference storage plugin. It serves the
+// file vault at the path of a plugin+file:///path address over stdin and
+// stdout.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+
+	"github.com/vitalvas/gopass/internal/vault"
+	"github.com/vitalvas/gopass/internal/vault/filevault"
+	"github.com/vitalvas/gopass/internal/vault/pluginvault"
+)
+
+func open(address string) (vault.Vault, error) {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse address: %w", err)
+	}
+
+	if parsed.Path == "" {
+		return nil, errors.New("address has no path")
+	}
+
+	if err := os.MkdirAll(parsed.Path, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create vault directory: %w", err)
+	}
+
+	return filevault.New(parsed.Path), nil
+}
+
+func main() {
+	// Stdout carries the protocol, logs go to stderr.
+	if err := pluginvault.Serve(os.Stdin, os.Stdout, open); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,225 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/encryptor"
+)
+
+const dialTimeout = time.Second
+
+type Client struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func Dial(socketPath string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+
+	if _, _, err := client.readResponse(); err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("failed to read agent greeting: %w", err)
+	}
+
+	return client, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) Locked() (bool, error) {
+	message, _, err := c.call("STATUS")
+	if err != nil {
+		return false, err
+	}
+
+	return message == "locked", nil
+}
+
+func (c *Client) Unlock(passphrase []byte) error {
+	if len(passphrase) == 0 {
+		_, _, err := c.call("UNLOCK")
+		return err
+	}
+
+	_, _, err := c.call("UNLOCK", passphrase)
+
+	return err
+}
+
+func (c *Client) Lock() error {
+	_, _, err := c.call("LOCK")
+
+	return err
+}
+
+func (c *Client) Kill() error {
+	_, _, err := c.call("KILLAGENT")
+
+	return err
+}
+
+// Keys returns the public part of the unlocked keys: the public key and the
+// id key needed to derive key IDs.
+func (c *Client) Keys() (*encryptor.Keys, error) {
+	_, data, err := c.call("KEYS")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) != 2 {
+		return nil, errors.New("unexpected agent response")
+	}
+
+	return &encryptor.Keys{
+		PublicKey: string(data[0]),
+		IDKey:     string(data[1]),
+	}, nil
+}
+
+func (c *Client) DecryptKey(text []byte) (string, error) {
+	_, data, err := c.call("DECRYPTKEY", text)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) != 1 {
+		return "", errors.New("unexpected agent response")
+	}
+
+	return string(data[0]), nil
+}
+
+func (c *Client) DecryptValue(key string, text []byte) ([]byte, error) {
+	if key == "" {
+		return nil, errors.New("empty key")
+	}
+
+	if text == nil {
+		return nil, errors.New("empty text")
+	}
+
+	_, data, err := c.call("DECRYPTVALUE", []byte(key), text)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) != 1 {
+		return nil, errors.New("unexpected agent response")
+	}
+
+	return data[0], nil
+}
+
+func (c *Client) call(cmd string, args ...[]byte) (string, [][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var line strings.Builder
+
+	line.WriteString(cmd)
+
+	for _, arg := range args {
+		line.WriteByte(' ')
+		line.WriteString(base64.StdEncoding.EncodeToString(arg))
+	}
+
+	line.WriteByte('\n')
+
+	if _, err := c.conn.Write([]byte(line.String())); err != nil {
+		return "", nil, fmt.Errorf("failed to write to agent: %w", err)
+	}
+
+	return c.readResponse()
+}
+
+func (c *Client) readResponse() (string, [][]byte, error) {
+	var data [][]byte
+
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read from agent: %w", err)
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "OK":
+			return "", data, nil
+
+		case strings.HasPrefix(line, "OK "):
+			return strings.TrimPrefix(line, "OK "), data, nil
+
+		case strings.HasPrefix(line, "ERR "):
+			message := strings.TrimPrefix(line, "ERR ")
+			if message == ErrLocked.Error() {
+				return "", nil, ErrLocked
+			}
+
+			return "", nil, errors.New(message)
+
+		case strings.HasPrefix(line, "D "):
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "D "))
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid agent data: %w", err)
+			}
+
+			data = append(data, decoded)
+
+		default:
+			return "", nil, fmt.Errorf("unexpected agent response: %s", line)
+		}
+	}
+}
+
+// Encryptor combines local public key operations with decryption through
+// the agent, so the private key never leaves the agent process.
+type Encryptor struct {
+	*encryptor.Encryptor
+
+	client *Client
+}
+
+func NewEncryptor(client *Client) (*Encryptor, error) {
+	keys, err := client.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := encryptor.NewPublicEncryptor(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Encryptor{
+		Encryptor: enc,
+		client:    client,
+	}, nil
+}
+
+func (e *Encryptor) DecryptKey(text []byte) (string, error) {
+	return e.client.DecryptKey(text)
+}
+
+func (e *Encryptor) DecryptValue(key string, text []byte) ([]byte, error) {
+	return e.client.DecryptValue(key, text)
+}
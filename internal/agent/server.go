@@ -0,0 +1,394 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/encryptor"
+)
+
+var (
+	ErrLocked = errors.New("agent is locked")
+
+	errQuit = errors.New("quit")
+)
+
+type Server struct {
+	socketPath  string
+	listener    net.Listener
+	keys        *encryptor.Keys
+	idleTimeout time.Duration
+
+	mu        sync.Mutex
+	running   bool
+	unsealed  *encryptor.Keys
+	encryptor *encryptor.Encryptor
+	lastUsed  time.Time
+	done      chan struct{}
+}
+
+func NewServer(socketPath string, keys *encryptor.Keys, idleTimeout time.Duration) *Server {
+	return &Server{
+		socketPath:  socketPath,
+		keys:        keys,
+		idleTimeout: idleTimeout,
+		done:        make(chan struct{}),
+	}
+}
+
+func (s *Server) SocketPath() string {
+	return s.socketPath
+}
+
+func (s *Server) Unlock(passphrase []byte) error {
+	keys := s.keys
+
+	if keys.IsSealed() {
+		var err error
+
+		keys, err = keys.Unseal(passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	enc, err := encryptor.NewEncryptor(keys)
+	if err != nil {
+		return fmt.Errorf("failed to create encryptor: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unsealed = keys
+	s.encryptor = enc
+	s.lastUsed = time.Now()
+
+	return nil
+}
+
+func (s *Server) Lock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unsealed = nil
+	s.encryptor = nil
+}
+
+func (s *Server) Locked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.encryptor == nil
+}
+
+func (s *Server) Start() error {
+	if err := os.MkdirAll(filepath.Dir(s.socketPath), 0700); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if err := os.Remove(s.socketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove existing socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	if err := os.Chmod(s.socketPath, 0600); err != nil {
+		listener.Close()
+
+		return fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	s.mu.Lock()
+	s.listener = listener
+	s.running = true
+	s.mu.Unlock()
+
+	if s.idleTimeout > 0 {
+		go s.idleLoop()
+	}
+
+	return nil
+}
+
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !s.isRunning() {
+				return nil
+			}
+
+			continue
+		}
+
+		go s.handleConnection(conn)
+	}
+}
+
+func (s *Server) Stop() error {
+	s.mu.Lock()
+
+	if !s.running {
+		s.mu.Unlock()
+
+		return nil
+	}
+
+	s.running = false
+	s.unsealed = nil
+	s.encryptor = nil
+	close(s.done)
+
+	s.mu.Unlock()
+
+	if s.listener != nil {
+		s.listener.Close()
+	}
+
+	os.Remove(s.socketPath)
+
+	return nil
+}
+
+func (s *Server) isRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.running
+}
+
+func (s *Server) idleLoop() {
+	interval := s.idleTimeout / 10
+	if interval < 100*time.Millisecond {
+		interval = 100 * time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+
+		case <-ticker.C:
+			s.mu.Lock()
+			if s.encryptor != nil && time.Since(s.lastUsed) >= s.idleTimeout {
+				s.unsealed = nil
+				s.encryptor = nil
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// use returns the unlocked encryptor and resets the idle timer.
+func (s *Server) use() (*encryptor.Encryptor, *encryptor.Keys, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.encryptor == nil {
+		return nil, nil, ErrLocked
+	}
+
+	s.lastUsed = time.Now()
+
+	return s.encryptor, s.unsealed, nil
+}
+
+func (s *Server) handleConnection(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	if err := writeOK(conn, "gopass agent ready"); err != nil {
+		return
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err != io.EOF {
+				writeError(conn, "read error")
+			}
+
+			return
+		}
+
+		cmd, args := parseCommand(strings.TrimSuffix(line, "\n"))
+
+		if err := s.handleCommand(conn, cmd, args); err != nil {
+			if err == errQuit {
+				return
+			}
+
+			writeError(conn, err.Error())
+		}
+	}
+}
+
+func (s *Server) handleCommand(w io.Writer, cmd string, args []string) error {
+	switch cmd {
+	case "NOP":
+		return writeOK(w, "")
+
+	case "STATUS":
+		if s.Locked() {
+			return writeOK(w, "locked")
+		}
+
+		return writeOK(w, "unlocked")
+
+	case "UNLOCK":
+		var passphrase []byte
+
+		if len(args) > 0 {
+			data, err := decodeArgs(args, 1)
+			if err != nil {
+				return err
+			}
+
+			passphrase = data[0]
+		}
+
+		if err := s.Unlock(passphrase); err != nil {
+			return err
+		}
+
+		return writeOK(w, "")
+
+	case "LOCK":
+		s.Lock()
+
+		return writeOK(w, "")
+
+	case "KEYS":
+		_, keys, err := s.use()
+		if err != nil {
+			return err
+		}
+
+		writeData(w, []byte(keys.PublicKey))
+		writeData(w, []byte(keys.IDKey))
+
+		return writeOK(w, "")
+
+	case "DECRYPTKEY":
+		data, err := decodeArgs(args, 1)
+		if err != nil {
+			return err
+		}
+
+		enc, _, err := s.use()
+		if err != nil {
+			return err
+		}
+
+		plaintext, err := enc.DecryptKey(data[0])
+		if err != nil {
+			return err
+		}
+
+		writeData(w, []byte(plaintext))
+
+		return writeOK(w, "")
+
+	case "DECRYPTVALUE":
+		data, err := decodeArgs(args, 2)
+		if err != nil {
+			return err
+		}
+
+		enc, _, err := s.use()
+		if err != nil {
+			return err
+		}
+
+		plaintext, err := enc.DecryptValue(string(data[0]), data[1])
+		if err != nil {
+			return err
+		}
+
+		writeData(w, plaintext)
+
+		return writeOK(w, "")
+
+	case "BYE":
+		writeOK(w, "closing connection")
+
+		return errQuit
+
+	case "KILLAGENT":
+		writeOK(w, "agent will terminate")
+		s.Stop()
+
+		return errQuit
+
+	default:
+		return fmt.Errorf("unknown command: %s", cmd)
+	}
+}
+
+func parseCommand(line string) (string, []string) {
+	parts := strings.Fields(line)
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	return strings.ToUpper(parts[0]), parts[1:]
+}
+
+func decodeArgs(args []string, count int) ([][]byte, error) {
+	if len(args) != count {
+		return nil, fmt.Errorf("expected %d arguments, got %d", count, len(args))
+	}
+
+	result := make([][]byte, 0, count)
+
+	for _, arg := range args {
+		decoded, err := base64.StdEncoding.DecodeString(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument encoding: %w", err)
+		}
+
+		result = append(result, decoded)
+	}
+
+	return result, nil
+}
+
+func writeOK(w io.Writer, message string) error {
+	if message == "" {
+		_, err := fmt.Fprintf(w, "OK\n")
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "OK %s\n", message)
+
+	return err
+}
+
+func writeError(w io.Writer, message string) error {
+	_, err := fmt.Fprintf(w, "ERR %s\n", message)
+
+	return err
+}
+
+func writeData(w io.Writer, data []byte) error {
+	_, err := fmt.Fprintf(w, "D %s\n", base64.StdEncoding.EncodeToString(data))
+
+	return err
+}
@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/encryptor"
+)
+
+func startTestServer(t *testing.T, keys *encryptor.Keys, idleTimeout time.Duration) *Server {
+	t.Helper()
+
+	tmpDir, err := os.MkdirTemp("", "agent-test")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	server := NewServer(filepath.Join(tmpDir, "S.agent"), keys, idleTimeout)
+	require.NoError(t, server.Start())
+
+	go server.Serve()
+
+	t.Cleanup(func() { server.Stop() })
+
+	return server
+}
+
+func TestServer_StartStop(t *testing.T) {
+	keys, err := encryptor.GenerateKeys()
+	require.NoError(t, err)
+
+	tmpDir, err := os.MkdirTemp("", "agent-test")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(tmpDir)
+
+	socketPath := filepath.Join(tmpDir, "S.agent")
+	server := NewServer(socketPath, keys, 0)
+
+	require.NoError(t, server.Start())
+
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, server.Stop())
+
+	_, err = os.Stat(socketPath)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, server.Stop())
+}
+
+func TestServer_LockUnlock(t *testing.T) {
+	keys, err := encryptor.GenerateKeys()
+	require.NoError(t, err)
+
+	require.NoError(t, keys.Seal([]byte("passphrase")))
+
+	server := NewServer("/tmp/unused.sock", keys, 0)
+	assert.True(t, server.Locked())
+
+	assert.ErrorIs(t, server.Unlock([]byte("wrong")), encryptor.ErrInvalidPassphrase)
+	assert.True(t, server.Locked())
+
+	require.NoError(t, server.Unlock([]byte("passphrase")))
+	assert.False(t, server.Locked())
+
+	server.Lock()
+	assert.True(t, server.Locked())
+}
+
+func TestClient(t *testing.T) {
+	keys, err := encryptor.GenerateKeys()
+	require.NoError(t, err)
+
+	local, err := encryptor.NewEncryptor(keys)
+	require.NoError(t, err)
+
+	sealed := *keys
+	require.NoError(t, sealed.Seal([]byte("passphrase")))
+
+	server := startTestServer(t, &sealed, 0)
+
+	client, err := Dial(server.SocketPath())
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	t.Run("locked", func(t *testing.T) {
+		locked, err := client.Locked()
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		_, err = client.Keys()
+		assert.ErrorIs(t, err, ErrLocked)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		assert.Error(t, client.Unlock([]byte("wrong")))
+	})
+
+	t.Run("unlock", func(t *testing.T) {
+		require.NoError(t, client.Unlock([]byte("passphrase")))
+
+		locked, err := client.Locked()
+		require.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("decrypt through agent", func(t *testing.T) {
+		enc, err := NewEncryptor(client)
+		require.NoError(t, err)
+
+		assert.Equal(t, local.KeyID("/test/key"), enc.KeyID("/test/key"))
+
+		encKey, err := enc.EncryptKey("/test/key")
+		require.NoError(t, err)
+
+		name, err := enc.DecryptKey(encKey)
+		require.NoError(t, err)
+		assert.Equal(t, "/test/key", name)
+
+		encValue, err := local.EncryptValue("/test/key", []byte("secret value"))
+		require.NoError(t, err)
+
+		value, err := enc.DecryptValue("/test/key", encValue)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret value"), value)
+
+		_, err = enc.DecryptValue("/other/key", encValue)
+		assert.Error(t, err)
+	})
+
+	t.Run("lock", func(t *testing.T) {
+		require.NoError(t, client.Lock())
+
+		_, err := client.DecryptKey([]byte("data"))
+		assert.ErrorIs(t, err, ErrLocked)
+	})
+
+	t.Run("kill", func(t *testing.T) {
+		require.NoError(t, client.Kill())
+
+		_, err := os.Stat(server.SocketPath())
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestServer_IdleTimeout(t *testing.T) {
+	keys, err := encryptor.GenerateKeys()
+	require.NoError(t, err)
+
+	server := startTestServer(t, keys, 200*time.Millisecond)
+
+	require.NoError(t, server.Unlock(nil))
+	assert.False(t, server.Locked())
+
+	assert.Eventually(t, server.Locked, 2*time.Second, 50*time.Millisecond)
+}
+
+func TestServer_UnknownCommand(t *testing.T) {
+	keys, err := encryptor.GenerateKeys()
+	require.NoError(t, err)
+
+	server := startTestServer(t, keys, 0)
+
+	client, err := Dial(server.SocketPath())
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	_, _, err = client.call("FOO")
+	assert.Error(t, err)
+
+	_, _, err = client.call("DECRYPTVALUE", []byte("only-one"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,298 @@
+// Package bundle reads and writes encrypted vault backups. A bundle is a
+// JSON header line with metadata, followed by the encrypted entries. The
+// header is authenticated as associated data, so it cannot be changed
+// without breaking decryption.
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/encryptor"
+	"github.com/vitalvas/gopass/internal/password"
+	"github.com/vitalvas/gopass/internal/vault"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	Format  = "gopass-bundle"
+	Version = 1
+
+	EncryptionPassphrase = "passphrase"
+	EncryptionRecipient  = "mlkem768"
+
+	nonceSize      = 12
+	maxHeaderSize  = 64 * 1024
+	recipientIDLen = 8
+)
+
+var ErrWrongRecipient = errors.New("bundle is encrypted to a different key")
+
+// Header is the unencrypted metadata of a bundle.
+type Header struct {
+	Format     string               `json:"format"`
+	Version    int                  `json:"version"`
+	Vault      string               `json:"vault"`
+	CreatedAt  time.Time            `json:"created_at"`
+	Entries    int                  `json:"entries"`
+	Generator  string               `json:"generator,omitempty"`
+	Encryption string               `json:"encryption"`
+	KDF        *encryptor.KDFParams `json:"kdf,omitempty"`
+	Recipient  string               `json:"recipient,omitempty"`
+}
+
+// Settings are the vault settings carried over on restore. Keys and the
+// storage address belong to the restored vault and are not included.
+type Settings struct {
+	History   *vault.HistoryConfig         `json:"history,omitempty"`
+	Clipboard *vault.ClipboardConfig       `json:"clipboard,omitempty"`
+	Profiles  map[string]*password.Profile `json:"profiles,omitempty"`
+}
+
+type Entry struct {
+	Name    string         `json:"name"`
+	Payload *vault.Payload `json:"payload"`
+}
+
+// Contents is the encrypted part of a bundle.
+type Contents struct {
+	Settings *Settings `json:"settings,omitempty"`
+	Entries  []Entry   `json:"entries"`
+}
+
+// Bundle is a read bundle, the contents are decrypted on demand.
+type Bundle struct {
+	Header Header
+
+	rawHeader []byte
+	data      []byte
+}
+
+// WriteWithPassphrase writes the contents encrypted with a key derived from
+// the passphrase.
+func WriteWithPassphrase(w io.Writer, header Header, contents *Contents, passphrase []byte) error {
+	if len(passphrase) == 0 {
+		return errors.New("empty passphrase")
+	}
+
+	kdf, err := encryptor.NewKDFParams()
+	if err != nil {
+		return err
+	}
+
+	header.Encryption = EncryptionPassphrase
+	header.KDF = kdf
+
+	return write(w, header, contents, func(rawHeader, plaintext []byte) ([]byte, error) {
+		aead, err := passphraseCipher(kdf, passphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		nonce := make([]byte, nonceSize)
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, fmt.Errorf("failed to generate nonce: %w", err)
+		}
+
+		return aead.Seal(nonce, nonce, plaintext, rawHeader), nil
+	})
+}
+
+// WriteForRecipient writes the contents encrypted to an ML-KEM-768 public
+// key, as stored in the keys of a vault config.
+func WriteForRecipient(w io.Writer, header Header, contents *Contents, publicKey string) error {
+	enc, err := encryptor.NewPublicEncryptor(&encryptor.Keys{PublicKey: publicKey})
+	if err != nil {
+		return fmt.Errorf("invalid recipient key: %w", err)
+	}
+
+	recipient, err := RecipientID(publicKey)
+	if err != nil {
+		return err
+	}
+
+	header.Encryption = EncryptionRecipient
+	header.Recipient = recipient
+
+	return write(w, header, contents, func(rawHeader, plaintext []byte) ([]byte, error) {
+		return enc.EncryptValue(string(rawHeader), plaintext)
+	})
+}
+
+// RecipientID is a short identifier of a public key, stored in the header
+// to tell which key a bundle is encrypted to.
+func RecipientID(publicKey string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipient key: %w", err)
+	}
+
+	hash := blake2b.Sum256(raw)
+
+	return hex.EncodeToString(hash[:recipientIDLen]), nil
+}
+
+func write(w io.Writer, header Header, contents *Contents, seal func(rawHeader, plaintext []byte) ([]byte, error)) error {
+	header.Format = Format
+	header.Version = Version
+	header.Entries = len(contents.Entries)
+
+	rawHeader, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("failed to marshal header: %w", err)
+	}
+
+	var plaintext bytes.Buffer
+
+	gz := gzip.NewWriter(&plaintext)
+
+	if err := json.NewEncoder(gz).Encode(contents); err != nil {
+		return fmt.Errorf("failed to marshal contents: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress contents: %w", err)
+	}
+
+	data, err := seal(rawHeader, plaintext.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt contents: %w", err)
+	}
+
+	if _, err := w.Write(append(rawHeader, '\n')); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	return nil
+}
+
+// Read reads a bundle and its header without decrypting it.
+func Read(r io.Reader) (*Bundle, error) {
+	reader := bufio.NewReader(io.LimitReader(r, maxHeaderSize))
+
+	rawHeader, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, errors.New("not a gopass bundle")
+	}
+
+	rawHeader = bytes.TrimSuffix(rawHeader, []byte("\n"))
+
+	b := &Bundle{rawHeader: rawHeader}
+
+	if err := json.Unmarshal(rawHeader, &b.Header); err != nil || b.Header.Format != Format {
+		return nil, errors.New("not a gopass bundle")
+	}
+
+	if b.Header.Version != Version {
+		return nil, fmt.Errorf("unsupported bundle version: %d", b.Header.Version)
+	}
+
+	// The limit only applies to the header.
+	b.data, err = io.ReadAll(io.MultiReader(reader, r))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	return b, nil
+}
+
+// DecryptWithPassphrase decrypts a bundle written with WriteWithPassphrase.
+func (b *Bundle) DecryptWithPassphrase(passphrase []byte) (*Contents, error) {
+	if b.Header.Encryption != EncryptionPassphrase {
+		return nil, fmt.Errorf("bundle is not encrypted with a passphrase")
+	}
+
+	if b.Header.KDF == nil {
+		return nil, fmt.Errorf("bundle has no kdf parameters")
+	}
+
+	if len(b.data) < nonceSize {
+		return nil, fmt.Errorf("bundle is truncated")
+	}
+
+	aead, err := passphraseCipher(b.Header.KDF, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, b.data[:nonceSize], b.data[nonceSize:], b.rawHeader)
+	if err != nil {
+		return nil, encryptor.ErrInvalidPassphrase
+	}
+
+	return decode(plaintext)
+}
+
+// DecryptWithKeys decrypts a bundle written with WriteForRecipient using the
+// unsealed keys of a vault.
+func (b *Bundle) DecryptWithKeys(keys *encryptor.Keys) (*Contents, error) {
+	if b.Header.Encryption != EncryptionRecipient {
+		return nil, fmt.Errorf("bundle is not encrypted to a key")
+	}
+
+	recipient, err := RecipientID(keys.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if recipient != b.Header.Recipient {
+		return nil, ErrWrongRecipient
+	}
+
+	enc, err := encryptor.NewEncryptor(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := enc.DecryptValue(string(b.rawHeader), b.data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt bundle: %w", err)
+	}
+
+	return decode(plaintext)
+}
+
+func decode(plaintext []byte) (*Contents, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(plaintext))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress contents: %w", err)
+	}
+	defer gz.Close()
+
+	var contents Contents
+
+	if err := json.NewDecoder(gz).Decode(&contents); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal contents: %w", err)
+	}
+
+	return &contents, nil
+}
+
+func passphraseCipher(kdf *encryptor.KDFParams, passphrase []byte) (cipher.AEAD, error) {
+	key, err := kdf.DeriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,121 @@
+package bundle
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/encryptor"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func testContents() *Contents {
+	return &Contents{
+		Settings: &Settings{Clipboard: &vault.ClipboardConfig{Timeout: 30}},
+		Entries: []Entry{
+			{Name: "/mail", Payload: &vault.Payload{Data: "s3cret", Username: "alice"}},
+			{Name: "/bank", Payload: &vault.Payload{Data: "1234", OTP: &vault.OTP{Secret: "JBSWY3DPEHPK3PXP"}}},
+		},
+	}
+}
+
+func testHeader() Header {
+	return Header{
+		Vault:     "default",
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestPassphraseBundle(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, WriteWithPassphrase(&buf, testHeader(), testContents(), []byte("bundle pass")))
+
+	b, err := Read(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	assert.Equal(t, Format, b.Header.Format)
+	assert.Equal(t, Version, b.Header.Version)
+	assert.Equal(t, "default", b.Header.Vault)
+	assert.Equal(t, 2, b.Header.Entries)
+	assert.Equal(t, EncryptionPassphrase, b.Header.Encryption)
+	assert.NotNil(t, b.Header.KDF)
+	assert.NotContains(t, buf.String(), "s3cret")
+
+	contents, err := b.DecryptWithPassphrase([]byte("bundle pass"))
+	require.NoError(t, err)
+	assert.Equal(t, testContents(), contents)
+
+	_, err = b.DecryptWithPassphrase([]byte("wrong"))
+	assert.ErrorIs(t, err, encryptor.ErrInvalidPassphrase)
+
+	_, err = b.DecryptWithKeys(&encryptor.Keys{})
+	assert.Error(t, err)
+
+	assert.Error(t, WriteWithPassphrase(&buf, testHeader(), testContents(), nil))
+}
+
+func TestRecipientBundle(t *testing.T) {
+	keys, err := encryptor.GenerateKeys()
+	require.NoError(t, err)
+
+	other, err := encryptor.GenerateKeys()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, WriteForRecipient(&buf, testHeader(), testContents(), keys.PublicKey))
+
+	b, err := Read(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	recipient, err := RecipientID(keys.PublicKey)
+	require.NoError(t, err)
+
+	assert.Equal(t, EncryptionRecipient, b.Header.Encryption)
+	assert.Equal(t, recipient, b.Header.Recipient)
+	assert.Nil(t, b.Header.KDF)
+
+	contents, err := b.DecryptWithKeys(keys)
+	require.NoError(t, err)
+	assert.Equal(t, testContents(), contents)
+
+	_, err = b.DecryptWithKeys(other)
+	assert.ErrorIs(t, err, ErrWrongRecipient)
+
+	_, err = b.DecryptWithPassphrase([]byte("pass"))
+	assert.Error(t, err)
+
+	assert.Error(t, WriteForRecipient(&buf, testHeader(), testContents(), "invalid"))
+}
+
+func TestTamperedHeader(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, WriteWithPassphrase(&buf, testHeader(), testContents(), []byte("bundle pass")))
+
+	tampered := bytes.Replace(buf.Bytes(), []byte(`"vault":"default"`), []byte(`"vault":"other00"`), 1)
+
+	b, err := Read(bytes.NewReader(tampered))
+	require.NoError(t, err)
+	assert.Equal(t, "other00", b.Header.Vault)
+
+	_, err = b.DecryptWithPassphrase([]byte("bundle pass"))
+	assert.ErrorIs(t, err, encryptor.ErrInvalidPassphrase)
+}
+
+func TestReadInvalid(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"not a bundle\n",
+		`{"format":"other","version":1}` + "\n",
+	} {
+		_, err := Read(bytes.NewReader([]byte(data)))
+		assert.Error(t, err, data)
+	}
+
+	_, err := Read(bytes.NewReader([]byte(`{"format":"gopass-bundle","version":2}` + "\n")))
+	assert.ErrorContains(t, err, "unsupported bundle version")
+}
@@ -0,0 +1,143 @@
+package clipboard
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const DefaultTimeout = 45 * time.Second
+
+var ErrNotFound = errors.New("no clipboard tool found, install wl-clipboard, xclip or xsel")
+
+type Clipboard struct {
+	CopyCommand  []string `json:"copy"`
+	PasteCommand []string `json:"paste,omitempty"`
+}
+
+type tool struct {
+	env   string
+	copy  []string
+	paste []string
+}
+
+var tools = []tool{
+	{env: "WAYLAND_DISPLAY", copy: []string{"wl-copy"}, paste: []string{"wl-paste", "--no-newline"}},
+	{env: "DISPLAY", copy: []string{"xclip", "-selection", "clipboard", "-in"}, paste: []string{"xclip", "-selection", "clipboard", "-out"}},
+	{env: "DISPLAY", copy: []string{"xsel", "--clipboard", "--input"}, paste: []string{"xsel", "--clipboard", "--output"}},
+}
+
+var lookPath = exec.LookPath
+
+// New creates a clipboard from user supplied commands. The paste command is
+// optional, without it the clipboard is cleared unconditionally.
+func New(copyCommand, pasteCommand string) (*Clipboard, error) {
+	copyArgs := strings.Fields(copyCommand)
+	if len(copyArgs) == 0 {
+		return nil, errors.New("clipboard copy command is empty")
+	}
+
+	return &Clipboard{
+		CopyCommand:  copyArgs,
+		PasteCommand: strings.Fields(pasteCommand),
+	}, nil
+}
+
+// Detect picks the clipboard tool for the current session.
+func Detect() (*Clipboard, error) {
+	if runtime.GOOS == "darwin" {
+		return &Clipboard{
+			CopyCommand:  []string{"pbcopy"},
+			PasteCommand: []string{"pbpaste"},
+		}, nil
+	}
+
+	for _, t := range tools {
+		if os.Getenv(t.env) == "" {
+			continue
+		}
+
+		if _, err := lookPath(t.copy[0]); err != nil {
+			continue
+		}
+
+		return &Clipboard{
+			CopyCommand:  t.copy,
+			PasteCommand: t.paste,
+		}, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+func (c *Clipboard) CanPaste() bool {
+	return len(c.PasteCommand) > 0
+}
+
+func (c *Clipboard) Write(data []byte) error {
+	cmd := exec.Command(c.CopyCommand[0], c.CopyCommand[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+
+	// Tools like xclip keep running in the background to serve the
+	// selection, so their output must not be attached to a pipe.
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Clipboard) Read() ([]byte, error) {
+	if !c.CanPaste() {
+		return nil, errors.New("clipboard paste command is not configured")
+	}
+
+	data, err := exec.Command(c.PasteCommand[0], c.PasteCommand[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clipboard: %w", err)
+	}
+
+	return data, nil
+}
+
+func Hash(data []byte) []byte {
+	sum := sha256.Sum256(data)
+
+	return sum[:]
+}
+
+// Restore puts the previous clipboard content back after a timeout, but only
+// if the clipboard still holds the value with the given hash.
+type Restore struct {
+	Clipboard *Clipboard    `json:"clipboard"`
+	Timeout   time.Duration `json:"timeout"`
+	Hash      []byte        `json:"hash"`
+	Previous  []byte        `json:"previous,omitempty"`
+}
+
+func (r *Restore) Run() error {
+	time.Sleep(r.Timeout)
+
+	if r.Clipboard.CanPaste() {
+		current, err := r.Clipboard.Read()
+		if err != nil {
+			return err
+		}
+
+		if subtle.ConstantTimeCompare(Hash(current), r.Hash) != 1 {
+			return nil
+		}
+	}
+
+	return r.Clipboard.Write(r.Previous)
+}
@@ -0,0 +1,148 @@
+package clipboard
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeClipboard(t *testing.T) (*Clipboard, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	storage := filepath.Join(dir, "clipboard")
+
+	copyScript := filepath.Join(dir, "copy")
+	require.NoError(t, os.WriteFile(copyScript, []byte("#!/bin/sh\ncat > "+storage+"\n"), 0700))
+
+	pasteScript := filepath.Join(dir, "paste")
+	require.NoError(t, os.WriteFile(pasteScript, []byte("#!/bin/sh\ncat "+storage+"\n"), 0700))
+
+	clip, err := New(copyScript, pasteScript)
+	require.NoError(t, err)
+
+	return clip, storage
+}
+
+func TestNew(t *testing.T) {
+	t.Run("with arguments", func(t *testing.T) {
+		clip, err := New("xclip -selection clipboard", "xclip -selection clipboard -out")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"xclip", "-selection", "clipboard"}, clip.CopyCommand)
+		assert.Equal(t, []string{"xclip", "-selection", "clipboard", "-out"}, clip.PasteCommand)
+		assert.True(t, clip.CanPaste())
+	})
+
+	t.Run("without paste command", func(t *testing.T) {
+		clip, err := New("wl-copy", "")
+		require.NoError(t, err)
+
+		assert.False(t, clip.CanPaste())
+	})
+
+	t.Run("empty copy command", func(t *testing.T) {
+		_, err := New(" ", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestDetect(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("clipboard is fixed on darwin")
+	}
+
+	defer func() { lookPath = exec.LookPath }()
+
+	lookPath = func(file string) (string, error) {
+		if file == "xsel" {
+			return "/usr/bin/xsel", nil
+		}
+
+		return "", exec.ErrNotFound
+	}
+
+	t.Run("x11", func(t *testing.T) {
+		t.Setenv("WAYLAND_DISPLAY", "")
+		t.Setenv("DISPLAY", ":0")
+
+		clip, err := Detect()
+		require.NoError(t, err)
+
+		assert.Equal(t, "xsel", clip.CopyCommand[0])
+	})
+
+	t.Run("no session", func(t *testing.T) {
+		t.Setenv("WAYLAND_DISPLAY", "")
+		t.Setenv("DISPLAY", "")
+
+		_, err := Detect()
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestReadWrite(t *testing.T) {
+	clip, _ := fakeClipboard(t)
+
+	require.NoError(t, clip.Write([]byte("secret")))
+
+	data, err := clip.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), data)
+}
+
+func TestRestore(t *testing.T) {
+	t.Run("restores previous value", func(t *testing.T) {
+		clip, storage := fakeClipboard(t)
+		require.NoError(t, clip.Write([]byte("secret")))
+
+		restore := &Restore{
+			Clipboard: clip,
+			Hash:      Hash([]byte("secret")),
+			Previous:  []byte("previous"),
+		}
+		require.NoError(t, restore.Run())
+
+		data, err := os.ReadFile(storage)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("previous"), data)
+	})
+
+	t.Run("keeps changed value", func(t *testing.T) {
+		clip, storage := fakeClipboard(t)
+		require.NoError(t, clip.Write([]byte("copied by user")))
+
+		restore := &Restore{
+			Clipboard: clip,
+			Hash:      Hash([]byte("secret")),
+			Previous:  []byte("previous"),
+		}
+		require.NoError(t, restore.Run())
+
+		data, err := os.ReadFile(storage)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("copied by user"), data)
+	})
+
+	t.Run("clears without paste command", func(t *testing.T) {
+		fake, storage := fakeClipboard(t)
+		require.NoError(t, fake.Write([]byte("secret")))
+
+		clip := &Clipboard{CopyCommand: fake.CopyCommand}
+
+		restore := &Restore{
+			Clipboard: clip,
+			Hash:      Hash([]byte("secret")),
+		}
+		require.NoError(t, restore.Run())
+
+		data, err := os.ReadFile(storage)
+		require.NoError(t, err)
+		assert.Empty(t, data)
+	})
+}
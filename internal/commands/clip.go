@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/clipboard"
+)
+
+const clipboardRestoreCommand = "clipboard-restore"
+
+func clipboardConfig() (*clipboard.Clipboard, time.Duration, error) {
+	timeout := clipboard.DefaultTimeout
+
+	if vaultConfig == nil || vaultConfig.Clipboard == nil {
+		clip, err := clipboard.Detect()
+
+		return clip, timeout, err
+	}
+
+	cfg := vaultConfig.Clipboard
+
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	if cfg.CopyCommand == "" {
+		clip, err := clipboard.Detect()
+
+		return clip, timeout, err
+	}
+
+	clip, err := clipboard.New(cfg.CopyCommand, cfg.PasteCommand)
+
+	return clip, timeout, err
+}
+
+// copyToClipboard copies the value and starts a detached helper process that
+// puts the previous clipboard content back after the timeout.
+func copyToClipboard(name, value string) error {
+	clip, timeout, err := clipboardConfig()
+	if err != nil {
+		return err
+	}
+
+	var previous []byte
+
+	if clip.CanPaste() {
+		// An empty clipboard makes some tools fail, nothing to restore then.
+		previous, _ = clip.Read()
+	}
+
+	if err := clip.Write([]byte(value)); err != nil {
+		return err
+	}
+
+	restore := &clipboard.Restore{
+		Clipboard: clip,
+		Timeout:   timeout,
+		Hash:      clipboard.Hash([]byte(value)),
+		Previous:  previous,
+	}
+
+	if err := startClipboardRestore(restore); err != nil {
+		return err
+	}
+
+	fmt.Printf("Copied %s to clipboard. Will clear in %s.\n", name, timeout)
+
+	return nil
+}
+
+func startClipboardRestore(restore *clipboard.Restore) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find executable: %w", err)
+	}
+
+	data, err := json.Marshal(restore)
+	if err != nil {
+		return fmt.Errorf("failed to marshal clipboard state: %w", err)
+	}
+
+	// The state is passed on stdin, so that it never shows up in the
+	// process list.
+	stdin, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create pipe: %w", err)
+	}
+
+	defer stdin.Close()
+
+	cmd := exec.Command(executable, clipboardRestoreCommand)
+	cmd.Stdin = stdin
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	if err := cmd.Start(); err != nil {
+		stdinWriter.Close()
+
+		return fmt.Errorf("failed to start clipboard helper: %w", err)
+	}
+
+	_, err = stdinWriter.Write(data)
+	stdinWriter.Close()
+
+	if err != nil {
+		return fmt.Errorf("failed to write clipboard state: %w", err)
+	}
+
+	return cmd.Process.Release()
+}
+
+var clipboardRestoreCmd = &cobra.Command{
+	Use:    clipboardRestoreCommand,
+	Short:  "Restore the clipboard after a copied secret times out",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		var restore clipboard.Restore
+
+		if err := json.NewDecoder(os.Stdin).Decode(&restore); err != nil {
+			return fmt.Errorf("failed to read clipboard state: %w", err)
+		}
+
+		if restore.Clipboard == nil || len(restore.Clipboard.CopyCommand) == 0 {
+			return fmt.Errorf("invalid clipboard state")
+		}
+
+		return restore.Run()
+	},
+}
@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/agent"
+)
+
+var agentTimeout time.Duration
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Run an agent that caches the unlocked vault keys",
+	Long: `Run an agent that caches the unlocked vault keys in memory.
+
+While the agent is running, other commands use it to derive key IDs and
+decrypt stored keys instead of asking for the vault passphrase every time.
+The keys are dropped after the idle timeout or on "gopass agent lock".`,
+	PreRunE: configLoader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		server := agent.NewServer(agentSocketPath(), vaultConfig.Keys, agentTimeout)
+
+		var passphrase []byte
+
+		if vaultConfig.Keys.IsSealed() {
+			var err error
+
+			passphrase, err = readPassphrase(fmt.Sprintf("Enter passphrase for vault %s: ", vaultName))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := server.Unlock(passphrase); err != nil {
+			return fmt.Errorf("failed to unlock vault: %w", err)
+		}
+
+		if err := server.Start(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Agent started on %s\n", server.SocketPath())
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		go func() {
+			<-sigChan
+			fmt.Println("\nShutting down...")
+			server.Stop()
+		}()
+
+		return server.Serve()
+	},
+}
+
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show agent status",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		client, err := agent.Dial(agentSocketPath())
+		if err != nil {
+			fmt.Println("Agent is not running")
+			return nil
+		}
+
+		defer client.Close()
+
+		locked, err := client.Locked()
+		if err != nil {
+			return fmt.Errorf("failed to get agent status: %w", err)
+		}
+
+		if locked {
+			fmt.Println("Agent is running: locked")
+		} else {
+			fmt.Println("Agent is running: unlocked")
+		}
+
+		return nil
+	},
+}
+
+var agentLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Drop the cached keys from the agent",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		client, err := agent.Dial(agentSocketPath())
+		if err != nil {
+			return fmt.Errorf("agent is not running: %w", err)
+		}
+
+		defer client.Close()
+
+		if err := client.Lock(); err != nil {
+			return fmt.Errorf("failed to lock agent: %w", err)
+		}
+
+		fmt.Println("Agent locked")
+
+		return nil
+	},
+}
+
+var agentUnlockCmd = &cobra.Command{
+	Use:     "unlock",
+	Short:   "Unlock the agent with the vault passphrase",
+	PreRunE: configLoader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		client, err := agent.Dial(agentSocketPath())
+		if err != nil {
+			return fmt.Errorf("agent is not running: %w", err)
+		}
+
+		defer client.Close()
+
+		var passphrase []byte
+
+		if vaultConfig.Keys.IsSealed() {
+			passphrase, err = readPassphrase(fmt.Sprintf("Enter passphrase for vault %s: ", vaultName))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := client.Unlock(passphrase); err != nil {
+			return fmt.Errorf("failed to unlock agent: %w", err)
+		}
+
+		fmt.Println("Agent unlocked")
+
+		return nil
+	},
+}
+
+var agentStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the agent",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if !stopAgent() {
+			return fmt.Errorf("agent is not running")
+		}
+
+		fmt.Println("Agent stopped")
+
+		return nil
+	},
+}
+
+func agentSocketPath() string {
+	return fmt.Sprintf("%s/.gopass/S.agent.%s", os.Getenv("HOME"), vaultName)
+}
+
+// stopAgent terminates a running agent, so it does not keep serving keys
+// that no longer match the vault config.
+func stopAgent() bool {
+	client, err := agent.Dial(agentSocketPath())
+	if err != nil {
+		return false
+	}
+
+	defer client.Close()
+
+	return client.Kill() == nil
+}
+
+func init() {
+	agentCmd.Flags().DurationVar(&agentTimeout, "timeout", 15*time.Minute, "Lock the agent after this idle time (0 to disable)")
+
+	agentCmd.AddCommand(agentStatusCmd)
+	agentCmd.AddCommand(agentLockCmd)
+	agentCmd.AddCommand(agentUnlockCmd)
+	agentCmd.AddCommand(agentStopCmd)
+}
@@ -0,0 +1,291 @@
+package commands
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/strength"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+const (
+	auditIssueWeak   = "weak"
+	auditIssueShort  = "short"
+	auditIssueReused = "reused"
+	auditIssueOld    = "old"
+)
+
+var (
+	auditJSON      bool
+	auditPrefix    string
+	auditMinLength int
+	auditMinScore  int
+	auditMaxAge    int
+)
+
+type auditEntry struct {
+	Name       string     `json:"name"`
+	Length     int        `json:"length"`
+	Score      int        `json:"score"`
+	Entropy    float64    `json:"entropy_bits"`
+	Patterns   []string   `json:"patterns"`
+	ChangedAt  *time.Time `json:"changed_at,omitempty"`
+	ReuseGroup int        `json:"reuse_group,omitempty"`
+	Issues     []string   `json:"issues"`
+
+	hash string
+}
+
+type auditReport struct {
+	Entries []*auditEntry  `json:"entries"`
+	Summary map[string]int `json:"summary"`
+	Failed  []string       `json:"failed,omitempty"`
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check stored passwords for weak, reused and old entries",
+	Long: `Check stored passwords for weak, reused and old entries.
+
+Every password is scored from 0 to 4 by a zxcvbn-style estimator. Reused
+passwords are found by comparing keyed hashes, values are never printed. The
+last change of an entry is taken from its history.`,
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		keyIDs, err := store.ListKeys()
+		if err != nil {
+			return err
+		}
+
+		// Passwords are compared by a MAC with a key that only lives for
+		// this run, so the hashes cannot be used to guess the passwords.
+		hashKey := make([]byte, 32)
+		if _, err := rand.Read(hashKey); err != nil {
+			return fmt.Errorf("failed to generate hash key: %w", err)
+		}
+
+		report := &auditReport{
+			Entries: make([]*auditEntry, 0, len(keyIDs)),
+			Summary: make(map[string]int),
+		}
+
+		for _, keyID := range keyIDs {
+			entry, err := auditKey(keyID, hashKey)
+			if err != nil {
+				report.Failed = append(report.Failed, err.Error())
+				continue
+			}
+
+			if entry != nil {
+				report.Entries = append(report.Entries, entry)
+			}
+		}
+
+		sort.Slice(report.Entries, func(i, j int) bool {
+			return report.Entries[i].Name < report.Entries[j].Name
+		})
+
+		markReused(report.Entries)
+
+		for _, entry := range report.Entries {
+			for _, issue := range entry.Issues {
+				report.Summary[issue]++
+			}
+		}
+
+		report.Summary["checked"] = len(report.Entries)
+
+		if auditJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+
+			return encoder.Encode(report)
+		}
+
+		printAuditReport(report)
+
+		return nil
+	},
+}
+
+func auditKey(keyID []byte, hashKey []byte) (*auditEntry, error) {
+	encKey, encValue, err := store.GetKey(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	name, err := encrypt.DecryptKey(encKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt key: %w", err)
+	}
+
+	if !strings.HasPrefix(name, auditPrefix) {
+		return nil, nil
+	}
+
+	value, err := encrypt.DecryptValue(name, encValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to decrypt value: %w", name, err)
+	}
+
+	payload, err := vault.PayloadUnmarshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to unmarshal payload: %w", name, err)
+	}
+
+	pass, _, _ := strings.Cut(payload.Data, "\n")
+	if pass == "" {
+		return nil, nil
+	}
+
+	result := strength.Estimate(pass)
+
+	mac := hmac.New(sha256.New, hashKey)
+	mac.Write([]byte(pass))
+
+	entry := &auditEntry{
+		Name:     name,
+		Length:   len([]rune(pass)),
+		Score:    result.Score,
+		Entropy:  result.Entropy,
+		Patterns: result.Patterns,
+		Issues:   []string{},
+		hash:     string(mac.Sum(nil)),
+	}
+
+	if entry.Score < auditMinScore {
+		entry.Issues = append(entry.Issues, auditIssueWeak)
+	}
+
+	if entry.Length < auditMinLength {
+		entry.Issues = append(entry.Issues, auditIssueShort)
+	}
+
+	if changedAt := keyChangedAt(keyID, payload); changedAt != nil {
+		entry.ChangedAt = changedAt
+
+		if auditMaxAge > 0 && time.Since(*changedAt) > time.Duration(auditMaxAge)*24*time.Hour {
+			entry.Issues = append(entry.Issues, auditIssueOld)
+		}
+	}
+
+	return entry, nil
+}
+
+// keyChangedAt returns when the current value was written. Entries written
+// before payloads recorded it fall back to the time the previous value was
+// moved to the history.
+func keyChangedAt(keyID []byte, payload *vault.Payload) *time.Time {
+	if !payload.ModifiedAt.IsZero() {
+		changedAt := payload.ModifiedAt
+		return &changedAt
+	}
+
+	history, ok := store.(vault.HistoryVault)
+	if !ok {
+		return nil
+	}
+
+	revisions, err := history.ListRevisions(keyID)
+	if err != nil || len(revisions) == 0 {
+		return nil
+	}
+
+	changedAt := revisions[len(revisions)-1].CreatedAt
+
+	return &changedAt
+}
+
+func markReused(entries []*auditEntry) {
+	groups := make(map[string][]*auditEntry)
+
+	for _, entry := range entries {
+		groups[entry.hash] = append(groups[entry.hash], entry)
+	}
+
+	group := 0
+
+	for _, entry := range entries {
+		reused := groups[entry.hash]
+		if len(reused) < 2 || reused[0].ReuseGroup != 0 {
+			continue
+		}
+
+		group++
+
+		for _, e := range reused {
+			e.ReuseGroup = group
+			e.Issues = append(e.Issues, auditIssueReused)
+		}
+	}
+}
+
+func printAuditReport(report *auditReport) {
+	sections := []struct {
+		issue string
+		title string
+	}{
+		{auditIssueWeak, fmt.Sprintf("Weak passwords (score below %d of 4)", auditMinScore)},
+		{auditIssueShort, fmt.Sprintf("Short passwords (less than %d characters)", auditMinLength)},
+		{auditIssueReused, "Reused passwords"},
+		{auditIssueOld, fmt.Sprintf("Old passwords (not changed for %d days)", auditMaxAge)},
+	}
+
+	for _, section := range sections {
+		if report.Summary[section.issue] == 0 {
+			continue
+		}
+
+		fmt.Printf("%s:\n", section.title)
+
+		for _, entry := range report.Entries {
+			if !slices.Contains(entry.Issues, section.issue) {
+				continue
+			}
+
+			switch section.issue {
+			case auditIssueWeak:
+				fmt.Printf("  %s: score %d, ~%.0f bits (%s)\n", entry.Name, entry.Score, entry.Entropy, strings.Join(entry.Patterns, ", "))
+			case auditIssueShort:
+				fmt.Printf("  %s: %d characters\n", entry.Name, entry.Length)
+			case auditIssueReused:
+				fmt.Printf("  %s: group %d\n", entry.Name, entry.ReuseGroup)
+			case auditIssueOld:
+				fmt.Printf("  %s: last changed %s\n", entry.Name, entry.ChangedAt.Format(time.DateOnly))
+			}
+		}
+
+		fmt.Println()
+	}
+
+	for _, failed := range report.Failed {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", failed)
+	}
+
+	issues := 0
+
+	for _, entry := range report.Entries {
+		if len(entry.Issues) > 0 {
+			issues++
+		}
+	}
+
+	fmt.Printf("Checked %d entries, %d with issues\n", len(report.Entries), issues)
+}
+
+func init() {
+	auditCmd.Flags().BoolVar(&auditJSON, "json", false, "Print the report as JSON")
+	auditCmd.Flags().StringVarP(&auditPrefix, "prefix", "p", "", "Only check keys with this prefix")
+	auditCmd.Flags().IntVar(&auditMinLength, "min-length", 12, "Report passwords shorter than this")
+	auditCmd.Flags().IntVar(&auditMinScore, "min-score", 3, "Report passwords with a lower strength score (0-4)")
+	auditCmd.Flags().IntVar(&auditMaxAge, "max-age", 365, "Report passwords not changed for this many days (0 to disable)")
+}
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/hibp"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+var breachcheckPrefix string
+
+var breachcheckCmd = &cobra.Command{
+	Use:   "breachcheck <hash file>",
+	Short: "Check stored passwords against a local Have I Been Pwned hash list",
+	Long: `Check stored passwords against a local Have I Been Pwned hash list.
+
+The hash file is the SHA-1 list ordered by hash in the "HASH:COUNT" text
+format, or a binary index built with "gopass breachcheck index". Lookups use
+a binary search, no network access is needed.`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, args []string) error {
+		index, err := hibp.Open(args[0])
+		if err != nil {
+			return err
+		}
+
+		defer index.Close()
+
+		keyIDs, err := store.ListKeys()
+		if err != nil {
+			return err
+		}
+
+		type breach struct {
+			name  string
+			count int
+		}
+
+		var (
+			breaches []breach
+			failed   []string
+			checked  int
+		)
+
+		for _, keyID := range keyIDs {
+			name, payload, err := breachcheckKey(keyID)
+			if err != nil {
+				failed = append(failed, err.Error())
+				continue
+			}
+
+			if payload == nil {
+				continue
+			}
+
+			count, err := breachCount(index, payload.Data)
+			if err != nil {
+				return err
+			}
+
+			checked++
+
+			if count > 0 {
+				breaches = append(breaches, breach{name: name, count: count})
+			}
+		}
+
+		sort.Slice(breaches, func(i, j int) bool {
+			return breaches[i].name < breaches[j].name
+		})
+
+		for _, b := range breaches {
+			fmt.Printf("%s: seen %d times in breaches\n", b.name, b.count)
+		}
+
+		for _, f := range failed {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", f)
+		}
+
+		fmt.Printf("Checked %d entries, %d found in breaches", checked, len(breaches))
+
+		if len(failed) > 0 {
+			fmt.Printf(", %d failed", len(failed))
+		}
+
+		fmt.Println()
+
+		return nil
+	},
+}
+
+// breachcheckKey reads an entry, a nil payload means the key does not match
+// the prefix.
+func breachcheckKey(keyID []byte) (string, *vault.Payload, error) {
+	encKey, encValue, err := store.GetKey(keyID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	name, err := encrypt.DecryptKey(encKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decrypt key: %w", err)
+	}
+
+	if !strings.HasPrefix(name, breachcheckPrefix) {
+		return name, nil, nil
+	}
+
+	value, err := encrypt.DecryptValue(name, encValue)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: failed to decrypt value: %w", name, err)
+	}
+
+	payload, err := vault.PayloadUnmarshal(value)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: failed to unmarshal payload: %w", name, err)
+	}
+
+	return name, payload, nil
+}
+
+// breachCount checks the whole data and, for multi-line entries, the
+// password on the first line.
+func breachCount(index hibp.Index, data string) (int, error) {
+	candidates := []string{data}
+
+	if first, _, found := strings.Cut(data, "\n"); found {
+		candidates = append(candidates, first)
+	}
+
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+
+		count, err := index.Lookup(hibp.HashPassword(candidate))
+		if err != nil {
+			return 0, fmt.Errorf("failed to look up hash: %w", err)
+		}
+
+		if count > 0 {
+			return count, nil
+		}
+	}
+
+	return 0, nil
+}
+
+var breachcheckIndexCmd = &cobra.Command{
+	Use:   "index <hash file> <index file>",
+	Short: "Build a binary index from a Have I Been Pwned hash list",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		src, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open hash file: %w", err)
+		}
+
+		defer src.Close()
+
+		tmpPath := args[1] + ".tmp"
+
+		dst, err := os.Create(tmpPath)
+		if err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+
+		records, err := hibp.BuildIndex(src, dst)
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			os.Remove(tmpPath)
+			return err
+		}
+
+		if err := os.Rename(tmpPath, args[1]); err != nil {
+			return fmt.Errorf("failed to replace index: %w", err)
+		}
+
+		fmt.Printf("Index with %d hashes written to %s\n", records, args[1])
+
+		return nil
+	},
+}
+
+func init() {
+	breachcheckCmd.Flags().StringVarP(&breachcheckPrefix, "prefix", "p", "", "Only check keys with this prefix")
+
+	breachcheckCmd.AddCommand(breachcheckIndexCmd)
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/hibp"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestBreachcheckFailedEntry(t *testing.T) {
+	setupTestVault(t)
+
+	require.NoError(t, savePayload("/mail", &vault.Payload{Data: "password"}))
+
+	encKey, err := encrypt.EncryptKey("/broken")
+	require.NoError(t, err)
+	require.NoError(t, store.SetKey(encrypt.KeyID("/broken"), encKey, []byte("not encrypted")))
+
+	hashFile := filepath.Join(t.TempDir(), "hashes.txt")
+	line := fmt.Sprintf("%X:42\n", hibp.HashPassword("password"))
+	require.NoError(t, os.WriteFile(hashFile, []byte(line), 0600))
+
+	stdout := captureOutput(t, &os.Stdout)
+	stderr := captureOutput(t, &os.Stderr)
+
+	require.NoError(t, breachcheckCmd.RunE(breachcheckCmd, []string{hashFile}))
+
+	assert.Equal(t, "/mail: seen 42 times in breaches\nChecked 1 entries, 1 found in breaches, 1 failed\n", stdout())
+	assert.True(t, strings.HasPrefix(stderr(), "Warning: /broken: failed to decrypt value: "))
+}
+
+// captureOutput redirects *file to a pipe and returns a function that
+// restores it and returns everything written.
+func captureOutput(t *testing.T, file **os.File) func() string {
+	t.Helper()
+
+	original := *file
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	*file = w
+
+	var restored bool
+
+	restore := func() {
+		if !restored {
+			restored = true
+			*file = original
+			w.Close()
+		}
+	}
+
+	t.Cleanup(restore)
+
+	done := make(chan string, 1)
+
+	go func() {
+		data, _ := io.ReadAll(r)
+		r.Close()
+		done <- string(data)
+	}()
+
+	return func() string {
+		restore()
+		return <-done
+	}
+}
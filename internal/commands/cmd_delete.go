@@ -1,13 +1,17 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
 	"github.com/vitalvas/gopass/internal/vault"
 )
 
-var deleteForce bool
+var (
+	deleteForce bool
+	deletePurge bool
+)
 
 var deleteCmd = &cobra.Command{
 	Use:     "delete <key name>",
@@ -34,8 +38,30 @@ var deleteCmd = &cobra.Command{
 		if confirm == "y" || deleteForce {
 			keyID := encrypt.KeyID(keyName)
 
-			if err := store.DeleteKey(keyID); err != nil {
-				return fmt.Errorf("failed to delete key: %w", err)
+			// With --purge the key may already be deleted and only have history left.
+			deleteErr := store.DeleteKey(keyID)
+			if deleteErr != nil && (!deletePurge || !errors.Is(deleteErr, vault.ErrKeyNotFound)) {
+				return fmt.Errorf("failed to delete key: %w", deleteErr)
+			}
+
+			if deletePurge {
+				history, err := historyStore()
+				if err != nil {
+					return err
+				}
+
+				revisions, err := history.ListRevisions(keyID)
+				if err != nil {
+					return fmt.Errorf("failed to list history: %w", err)
+				}
+
+				if deleteErr != nil && len(revisions) == 0 {
+					return fmt.Errorf("failed to delete key: %w", deleteErr)
+				}
+
+				if err := history.PurgeRevisions(keyID); err != nil {
+					return fmt.Errorf("failed to purge history: %w", err)
+				}
 			}
 
 			fmt.Println("Key deleted:", keyName)
@@ -49,4 +75,5 @@ var deleteCmd = &cobra.Command{
 
 func init() {
 	deleteCmd.Flags().BoolVarP(&deleteForce, "force", "f", false, "Force delete key")
+	deleteCmd.Flags().BoolVar(&deletePurge, "purge", false, "Also delete the key history")
 }
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestDeletePurge(t *testing.T) {
+	setupTestVault(t)
+
+	deleteForce = true
+	deletePurge = true
+	t.Cleanup(func() { deleteForce = false; deletePurge = false })
+
+	captureOutput(t, &os.Stdout)
+
+	err := deleteCmd.RunE(deleteCmd, []string{"/missing"})
+	assert.ErrorIs(t, err, vault.ErrKeyNotFound)
+
+	require.NoError(t, savePayload("/mail", &vault.Payload{Data: "secret"}))
+	require.NoError(t, store.DeleteKey(encrypt.KeyID("/mail")))
+
+	// Only the history of the deleted key is left.
+	require.NoError(t, deleteCmd.RunE(deleteCmd, []string{"/mail"}))
+
+	history, err := historyStore()
+	require.NoError(t, err)
+
+	revisions, err := history.ListRevisions(encrypt.KeyID("/mail"))
+	require.NoError(t, err)
+	assert.Empty(t, revisions)
+
+	err = deleteCmd.RunE(deleteCmd, []string{"/mail"})
+	assert.ErrorIs(t, err, vault.ErrKeyNotFound)
+}
@@ -11,74 +11,129 @@ import (
 	"github.com/vitalvas/gopass/internal/vault"
 )
 
-var editMultiline bool
+var (
+	editMultiline     bool
+	editFields        []string
+	editClearSections bool
+
+	editExpiryFlags expiryFlags
+)
 
 var editCmd = &cobra.Command{
-	Use:     "edit <key name>",
-	Short:   "Edit an existing password",
+	Use:   "edit <key name>",
+	Short: "Edit an existing password",
+	Long: `Edit an existing password.
+
+With --multiline the whole entry is edited in the pass format: the password
+on the first line, then "login:", "url:" and other "name: value" lines, the
+otpauth:// URI and the notes. On a terminal it is opened in $EDITOR (vi by
+default). The temporary file is created with 0600 permissions under /dev/shm
+or $XDG_RUNTIME_DIR, wiped afterwards, and the entry is only written back if
+the content changed. Otherwise the new content is read from stdin.
+
+With --field, --expires or --max-age only those are changed and the password
+is not asked for.`,
 	Args:    cobra.ExactArgs(1),
 	PreRunE: loader,
-	RunE: func(_ *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		keyName := args[0]
 		if err := vault.ValidateKeyName(keyName); err != nil {
 			return err
 		}
 
-		keyID := encrypt.KeyID(keyName)
-
-		if _, _, err := store.GetKey(keyID); err != nil {
-			return fmt.Errorf("key does not exist: %s", keyName)
-		}
-
-		var password string
-
-		if editMultiline {
-			fmt.Printf("Enter new contents for %s (Ctrl+D to finish):\n", keyName)
-
-			data, err := io.ReadAll(os.Stdin)
-			if err != nil {
-				return fmt.Errorf("failed to read input: %w", err)
+		for _, field := range editFields {
+			if _, _, err := vault.ParseField(field); err != nil {
+				return err
 			}
+		}
 
-			password = strings.TrimSuffix(string(data), "\n")
-		} else {
-			fmt.Printf("Enter new password for %s: ", keyName)
+		if err := editExpiryFlags.validate(cmd); err != nil {
+			return err
+		}
 
-			reader := bufio.NewReader(os.Stdin)
-			line, err := reader.ReadString('\n')
-			if err != nil && err != io.EOF {
-				return fmt.Errorf("failed to read password: %w", err)
-			}
+		keyID := encrypt.KeyID(keyName)
 
-			password = strings.TrimSuffix(line, "\n")
-			password = strings.TrimSuffix(password, "\r")
+		if _, _, err := store.GetKey(keyID); err != nil {
+			return fmt.Errorf("key does not exist: %s", keyName)
 		}
 
-		if password == "" {
-			return fmt.Errorf("password cannot be empty")
+		existing, err := loadPayload(keyName)
+		if err != nil {
+			return err
 		}
 
-		payload := vault.Payload{
-			Data: password,
+		// Only the changed parts are replaced, OTP, passkey and GPG sections
+		// are kept unless they are cleared on purpose.
+		payload := existing
+
+		if len(editFields) == 0 && !editExpiryFlags.changed(cmd) {
+			if editMultiline {
+				var content string
+
+				if useEditor() {
+					edited, changed, err := editContent(keyName, entryContent(keyName, existing))
+					if err != nil {
+						return err
+					}
+
+					if !changed && !editClearSections {
+						fmt.Println("Password unchanged:", keyName)
+						return nil
+					}
+
+					content = edited
+				} else {
+					fmt.Printf("Enter new contents for %s (Ctrl+D to finish):\n", keyName)
+
+					data, err := io.ReadAll(os.Stdin)
+					if err != nil {
+						return fmt.Errorf("failed to read input: %w", err)
+					}
+
+					content = string(data)
+				}
+
+				if err := applyEntryContent(payload, content); err != nil {
+					return err
+				}
+			} else {
+				fmt.Printf("Enter new password for %s: ", keyName)
+
+				reader := bufio.NewReader(os.Stdin)
+				line, err := reader.ReadString('\n')
+				if err != nil && err != io.EOF {
+					return fmt.Errorf("failed to read password: %w", err)
+				}
+
+				password := strings.TrimSuffix(line, "\n")
+				password = strings.TrimSuffix(password, "\r")
+
+				// Field and expiry changes keep the existing password, which
+				// is empty for OTP, passkey and GPG only entries.
+				if password == "" {
+					return fmt.Errorf("password cannot be empty")
+				}
+
+				payload.Data = password
+			}
 		}
 
-		payloadEncoded, err := payload.Marshal()
-		if err != nil {
-			return fmt.Errorf("failed to marshal payload: %w", err)
+		if editClearSections {
+			payload.OTP = nil
+			payload.Passkey = nil
+			payload.GPGKey = nil
 		}
 
-		encKeyName, err := encrypt.EncryptKey(keyName)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt key name: %w", err)
+		if err := applyFields(payload, editFields); err != nil {
+			return err
 		}
 
-		encValue, err := encrypt.EncryptValue(keyName, payloadEncoded)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt value: %w", err)
+		if err := editExpiryFlags.apply(cmd, payload); err != nil {
+			return err
 		}
 
-		if err := store.SetKey(keyID, encKeyName, encValue); err != nil {
-			return fmt.Errorf("failed to store key: %w", err)
+		if err := savePayload(keyName, payload); err != nil {
+			return err
 		}
 
 		fmt.Println("Password updated successfully:", keyName)
@@ -88,5 +143,9 @@ var editCmd = &cobra.Command{
 }
 
 func init() {
-	editCmd.Flags().BoolVarP(&editMultiline, "multiline", "m", false, "Read multi-line input until Ctrl+D")
+	editCmd.Flags().BoolVarP(&editMultiline, "multiline", "m", false, "Read multi-line input, from $EDITOR on a terminal or until Ctrl+D")
+	editCmd.Flags().BoolVar(&editClearSections, "clear-sections", false, "Remove the OTP, passkey and GPG sections of the entry")
+	editCmd.Flags().StringArrayVar(&editFields, "field", nil, "Set a field as name=value instead of the password (repeatable, empty value removes it)")
+
+	editExpiryFlags.register(editCmd)
 }
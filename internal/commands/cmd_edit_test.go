@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestEditFieldOnly(t *testing.T) {
+	setupTestVault(t)
+
+	otp := &vault.OTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 6, Period: 30}
+	require.NoError(t, savePayload("/otp/only", &vault.Payload{OTP: otp}))
+
+	editFields = []string{"username=z"}
+	t.Cleanup(func() { editFields = nil })
+
+	require.NoError(t, editCmd.RunE(editCmd, []string{"/otp/only"}))
+
+	payload, err := loadPayload("/otp/only")
+	require.NoError(t, err)
+
+	assert.Empty(t, payload.Data)
+	assert.Equal(t, "z", payload.Username)
+	assert.Equal(t, otp, payload.OTP)
+}
+
+func TestEntryContentRoundTrip(t *testing.T) {
+	otp := &vault.OTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 6, Period: 30}
+	passkey := &vault.Passkey{ID: "id", RPID: "example.com"}
+
+	payload := &vault.Payload{
+		Data:     "secret",
+		Username: "alice",
+		URL:      "https://example.com",
+		Notes:    "first note\nsecond note",
+		Fields:   map[string]string{"pin": "1234"},
+		OTP:      otp,
+		Passkey:  passkey,
+	}
+
+	content := entryContent("/mail", payload)
+	assert.True(t, strings.HasPrefix(content, "secret\nlogin: alice\nurl: https://example.com\npin: 1234\notpauth://"))
+	assert.True(t, strings.HasSuffix(content, "first note\nsecond note\n"))
+
+	edited := strings.Replace(content, "pin: 1234\n", "pin: 4321\nrecovery: abcd\n", 1)
+	edited = strings.Replace(edited, "second note", "changed note\nthird note", 1)
+	edited = strings.Replace(edited, "login: alice", "login: bob", 1)
+
+	require.NoError(t, applyEntryContent(payload, edited))
+
+	assert.Equal(t, "secret", payload.Data)
+	assert.Equal(t, "bob", payload.Username)
+	assert.Equal(t, "https://example.com", payload.URL)
+	assert.Equal(t, "first note\nchanged note\nthird note", payload.Notes)
+	assert.Equal(t, map[string]string{"pin": "4321", "recovery": "abcd"}, payload.Fields)
+	assert.Equal(t, otp, payload.OTP)
+	assert.Equal(t, passkey, payload.Passkey)
+
+	assert.Error(t, applyEntryContent(payload, ""))
+}
+
+func TestEditMultilineStdin(t *testing.T) {
+	setupTestVault(t)
+
+	require.NoError(t, savePayload("/mail", &vault.Payload{Data: "old", Username: "alice", Notes: "old note"}))
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	_, err = w.WriteString("new\nlogin: bob\npin: 1234\nnew note\nsecond line\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = stdin; r.Close() })
+
+	editMultiline = true
+	t.Cleanup(func() { editMultiline = false })
+
+	captureOutput(t, &os.Stdout)
+
+	require.NoError(t, editCmd.RunE(editCmd, []string{"/mail"}))
+
+	payload, err := loadPayload("/mail")
+	require.NoError(t, err)
+
+	assert.Equal(t, "new", payload.Data)
+	assert.Equal(t, "bob", payload.Username)
+	assert.Equal(t, map[string]string{"pin": "1234"}, payload.Fields)
+	assert.Equal(t, "new note\nsecond line", payload.Notes)
+}
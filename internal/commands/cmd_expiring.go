@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+var (
+	expiringWithin string
+	expiringPrefix string
+)
+
+var expiringCmd = &cobra.Command{
+	Use:   "expiring",
+	Short: "List entries that are past or close to their expiry",
+	Long: `List entries that are past or close to their expiry.
+
+An entry expires on its --expires date or when it was not changed for its
+--max-age, whichever comes first. Overdue entries are listed first, followed
+by the entries that expire within the given period.`,
+	Args:    cobra.NoArgs,
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		within, err := parseDays(expiringWithin)
+		if err != nil {
+			return fmt.Errorf("invalid period: %w", err)
+		}
+
+		keyIDs, err := store.ListKeys()
+		if err != nil {
+			return err
+		}
+
+		type expiring struct {
+			name   string
+			expiry time.Time
+		}
+
+		var (
+			entries []expiring
+			failed  []string
+		)
+
+		now := time.Now()
+		deadline := now.AddDate(0, 0, within)
+
+		for _, keyID := range keyIDs {
+			name, payload, err := expiringKey(keyID)
+			if err != nil {
+				failed = append(failed, err.Error())
+				continue
+			}
+
+			if payload == nil {
+				continue
+			}
+
+			expiry, ok := payload.Expiry()
+			if !ok || expiry.After(deadline) {
+				continue
+			}
+
+			entries = append(entries, expiring{name: name, expiry: expiry})
+		}
+
+		for _, f := range failed {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", f)
+		}
+
+		sort.Slice(entries, func(i, j int) bool {
+			if !entries[i].expiry.Equal(entries[j].expiry) {
+				return entries[i].expiry.Before(entries[j].expiry)
+			}
+
+			return entries[i].name < entries[j].name
+		})
+
+		if len(entries) == 0 {
+			fmt.Printf("No entries expire within %d days\n", within)
+		}
+
+		for _, entry := range entries {
+			date := entry.expiry.Local().Format(expiryDateFormat)
+
+			if now.Before(entry.expiry) {
+				fmt.Printf("due      %s  %s (in %s)\n", date, entry.name, formatDays(entry.expiry.Sub(now)))
+			} else {
+				fmt.Printf("overdue  %s  %s (%s ago)\n", date, entry.name, formatDays(now.Sub(entry.expiry)))
+			}
+		}
+
+		if len(failed) > 0 {
+			fmt.Printf("%d entries could not be checked\n", len(failed))
+		}
+
+		return nil
+	},
+}
+
+// expiringKey reads an entry, a nil payload means the key does not match the
+// prefix.
+func expiringKey(keyID []byte) (string, *vault.Payload, error) {
+	encKey, encValue, err := store.GetKey(keyID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	name, err := encrypt.DecryptKey(encKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decrypt key: %w", err)
+	}
+
+	if !strings.HasPrefix(name, expiringPrefix) {
+		return name, nil, nil
+	}
+
+	value, err := encrypt.DecryptValue(name, encValue)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: failed to decrypt value: %w", name, err)
+	}
+
+	payload, err := vault.PayloadUnmarshal(value)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: failed to unmarshal payload: %w", name, err)
+	}
+
+	return name, payload, nil
+}
+
+func formatDays(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+
+	switch days {
+	case 0:
+		return "less than a day"
+	case 1:
+		return "1 day"
+	default:
+		return fmt.Sprintf("%d days", days)
+	}
+}
+
+func init() {
+	expiringCmd.Flags().StringVar(&expiringWithin, "within", "14d", "List entries that expire within this period, e.g. 14d or 4w")
+	expiringCmd.Flags().StringVarP(&expiringPrefix, "prefix", "p", "", "Only list keys with this prefix")
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestExpiringFailedEntry(t *testing.T) {
+	setupTestVault(t)
+
+	expiresAt := time.Now().AddDate(0, 0, -3)
+	require.NoError(t, savePayload("/mail", &vault.Payload{Data: "secret", ExpiresAt: expiresAt}))
+
+	encKey, err := encrypt.EncryptKey("/broken")
+	require.NoError(t, err)
+	require.NoError(t, store.SetKey(encrypt.KeyID("/broken"), encKey, []byte("not encrypted")))
+
+	stdout := captureOutput(t, &os.Stdout)
+	stderr := captureOutput(t, &os.Stderr)
+
+	require.NoError(t, expiringCmd.RunE(expiringCmd, nil))
+
+	output := stdout()
+	assert.Contains(t, output, "overdue")
+	assert.Contains(t, output, "/mail")
+	assert.True(t, strings.HasSuffix(output, "1 entries could not be checked\n"))
+	assert.True(t, strings.HasPrefix(stderr(), "Warning: /broken: failed to decrypt value: "))
+}
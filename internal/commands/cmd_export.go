@@ -0,0 +1,290 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/bundle"
+	"github.com/vitalvas/gopass/internal/exporter"
+	"github.com/vitalvas/gopass/internal/gpgagent"
+	"github.com/vitalvas/gopass/internal/vault"
+	"github.com/vitalvas/gopass/internal/version"
+)
+
+const (
+	exportFormatBundle = "bundle"
+	exportFormatJSON   = "json"
+	exportFormatCSV    = "csv"
+	exportFormatPass   = "pass"
+)
+
+var (
+	exportOut       string
+	exportFormat    string
+	exportRecipient string
+	exportPrefix    string
+	exportForce     bool
+	exportPlaintext bool
+	exportGPGKey    string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the vault to an encrypted bundle or a plaintext format",
+	Long: `Export all entries and the vault settings to a single encrypted file.
+
+The bundle is encrypted with a bundle passphrase, or with --recipient to the
+ML-KEM public key of another vault ("keys.pub" in its config), given as the
+base64 key or a file containing it. Use "gopass restore" to rebuild a vault
+from the bundle. History is not included.
+
+To move to another password manager, --format json or csv writes all entries
+decrypted, to --out or to stdout with "--out -". --format pass writes a pass
+password store into the directory --out, encrypted with the GPG key stored in
+the vault at --gpg-key. The plaintext formats require --i-understand-plaintext.`,
+	Args:    cobra.NoArgs,
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if exportOut == "" {
+			return fmt.Errorf("--out is required")
+		}
+
+		switch exportFormat {
+		case exportFormatBundle:
+			return exportBundle()
+
+		case exportFormatJSON, exportFormatCSV, exportFormatPass:
+			if !exportPlaintext {
+				return fmt.Errorf("--format %s writes decrypted secrets, confirm with --i-understand-plaintext", exportFormat)
+			}
+
+			return exportPlaintextFormat()
+		}
+
+		return fmt.Errorf("unsupported format: %s", exportFormat)
+	},
+}
+
+func exportBundle() error {
+	if _, err := os.Stat(exportOut); err == nil && !exportForce {
+		return fmt.Errorf("file already exists: %s, use --force to overwrite", exportOut)
+	}
+
+	recipient, err := exportRecipientKey()
+	if err != nil {
+		return err
+	}
+
+	contents, err := exportContents()
+	if err != nil {
+		return err
+	}
+
+	header := bundle.Header{
+		Vault:     vaultName,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Generator: "gopass " + version.Version(),
+	}
+
+	var buf bytes.Buffer
+
+	if recipient != "" {
+		err = bundle.WriteForRecipient(&buf, header, contents, recipient)
+	} else {
+		var passphrase []byte
+
+		passphrase, err = readConfirmedPassphrase("bundle passphrase")
+		if err != nil {
+			return err
+		}
+
+		err = bundle.WriteWithPassphrase(&buf, header, contents, passphrase)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := writeExportFile(buf.Bytes()); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d entries to %s\n", len(contents.Entries), exportOut)
+
+	return nil
+}
+
+func exportPlaintextFormat() error {
+	var encryptPass exporter.Encrypter
+
+	var gpgID string
+
+	if exportFormat == exportFormatPass {
+		if exportGPGKey == "" {
+			return fmt.Errorf("--format pass requires --gpg-key")
+		}
+
+		if err := vault.ValidateKeyName(exportGPGKey); err != nil {
+			return err
+		}
+
+		gpgKey, err := loadGPGKeyFromVault(exportGPGKey)
+		if err != nil {
+			return err
+		}
+
+		gpgID = gpgKey.Fingerprint
+		encryptPass = func(plaintext []byte) ([]byte, error) {
+			return gpgagent.Encrypt(gpgKey.PublicKey, plaintext, false)
+		}
+	} else if exportOut != "-" {
+		if _, err := os.Stat(exportOut); err == nil && !exportForce {
+			return fmt.Errorf("file already exists: %s, use --force to overwrite", exportOut)
+		}
+	}
+
+	contents, err := exportContents()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]exporter.Entry, 0, len(contents.Entries))
+	for _, entry := range contents.Entries {
+		entries = append(entries, exporter.Entry{Name: entry.Name, Payload: entry.Payload})
+	}
+
+	var buf bytes.Buffer
+
+	switch exportFormat {
+	case exportFormatPass:
+		if err := exporter.WritePass(exportOut, entries, encryptPass, gpgID); err != nil {
+			return err
+		}
+
+	case exportFormatJSON:
+		err = exporter.WriteJSON(&buf, entries, time.Now().UTC().Truncate(time.Second))
+
+	case exportFormatCSV:
+		err = exporter.WriteCSV(&buf, entries)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if exportFormat != exportFormatPass {
+		if exportOut == "-" {
+			_, err := os.Stdout.Write(buf.Bytes())
+			return err
+		}
+
+		if err := writeExportFile(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d entries to %s\n", len(entries), exportOut)
+
+	return nil
+}
+
+// writeExportFile replaces the --out file, readable only by the owner.
+func writeExportFile(data []byte) error {
+	tmpPath := exportOut + ".tmp"
+
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, exportOut); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return nil
+}
+
+// exportRecipientKey returns the --recipient key, read from a file if the
+// value is a path.
+func exportRecipientKey() (string, error) {
+	if exportRecipient == "" {
+		return "", nil
+	}
+
+	if data, err := os.ReadFile(exportRecipient); err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	if _, err := bundle.RecipientID(exportRecipient); err != nil {
+		return "", fmt.Errorf("--recipient is neither a public key nor a readable file")
+	}
+
+	return exportRecipient, nil
+}
+
+// exportContents decrypts all entries below the export prefix. Any entry that
+// cannot be read fails the export, a backup must be complete.
+func exportContents() (*bundle.Contents, error) {
+	keyIDs, err := store.ListKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	contents := &bundle.Contents{
+		Settings: &bundle.Settings{
+			History:   vaultConfig.History,
+			Clipboard: vaultConfig.Clipboard,
+			Profiles:  vaultConfig.Profiles,
+		},
+		Entries: make([]bundle.Entry, 0, len(keyIDs)),
+	}
+
+	for _, keyID := range keyIDs {
+		encKey, encValue, err := store.GetKey(keyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get key: %w", err)
+		}
+
+		name, err := encrypt.DecryptKey(encKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt key: %w", err)
+		}
+
+		if !strings.HasPrefix(name, exportPrefix) {
+			continue
+		}
+
+		value, err := encrypt.DecryptValue(name, encValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+
+		payload, err := vault.PayloadUnmarshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", name, err)
+		}
+
+		contents.Entries = append(contents.Entries, bundle.Entry{Name: name, Payload: payload})
+	}
+
+	sort.Slice(contents.Entries, func(i, j int) bool {
+		return contents.Entries[i].Name < contents.Entries[j].Name
+	})
+
+	return contents, nil
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportOut, "out", "o", "", "File to write, or the directory for --format pass")
+	exportCmd.Flags().StringVar(&exportFormat, "format", exportFormatBundle, "Export format: bundle, json, csv or pass")
+	exportCmd.Flags().StringVar(&exportRecipient, "recipient", "", "Encrypt to this ML-KEM public key, or a file containing it, instead of a passphrase")
+	exportCmd.Flags().StringVarP(&exportPrefix, "prefix", "p", "", "Export only keys with this prefix")
+	exportCmd.Flags().BoolVarP(&exportForce, "force", "f", false, "Overwrite an existing file")
+	exportCmd.Flags().BoolVar(&exportPlaintext, "i-understand-plaintext", false, "Confirm writing decrypted secrets for --format json, csv and pass")
+	exportCmd.Flags().StringVar(&exportGPGKey, "gpg-key", "", "GPG key stored in the vault to encrypt --format pass to")
+}
@@ -2,64 +2,221 @@ package commands
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/vitalvas/gopass/internal/password"
 	"github.com/vitalvas/gopass/internal/vault"
 )
 
-var generateForce bool
+var (
+	generateForce   bool
+	generateClip    bool
+	generateInPlace bool
+	generatePrint   bool
+	generateLength  int
+	generateSpecial int
+	generateNumbers int
+	generateString  bool
+	generateProfile string
+
+	generatePassphraseFlags passphraseFlags
+	generateExpiryFlags     expiryFlags
+)
 
 var generateCmd = &cobra.Command{
-	Use:     "generate <key name>",
-	Short:   "Generate and store a new password",
+	Use:   "generate <key name>",
+	Short: "Generate and store a new password",
+	Long: `Generate and store a new password.
+
+With --in-place only the password line of an existing entry is replaced, the
+rest of the content and all other fields are kept.
+
+Profiles from the vault config set the length and character rules for sites
+with password policies. A profile is used with --profile or automatically
+when one of its prefixes matches the key name.`,
 	Args:    cobra.ExactArgs(1),
 	PreRunE: loader,
-	RunE: func(_ *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		keyName := args[0]
 		if err := vault.ValidateKeyName(keyName); err != nil {
 			return err
 		}
 
+		if generateInPlace && generateForce {
+			return fmt.Errorf("--in-place and --force cannot be used together")
+		}
+
+		if err := generateExpiryFlags.validate(cmd); err != nil {
+			return err
+		}
+
 		keyID := encrypt.KeyID(keyName)
 
-		if _, _, err := store.GetKey(keyID); err == nil {
-			if !generateForce {
-				return fmt.Errorf("key already exists, use --force to overwrite")
-			}
+		_, _, err := store.GetKey(keyID)
+		exists := err == nil
+
+		switch {
+		case generateInPlace && !exists:
+			return fmt.Errorf("key does not exist: %s", keyName)
+
+		case !generateInPlace && exists && !generateForce:
+			return fmt.Errorf("key already exists, use --force or --in-place to overwrite")
 		}
 
-		pass := password.Generate(password.DefaultLength, password.DefaultLength, password.DefaultLength)
+		var (
+			pass    string
+			entropy float64
+		)
+
+		if generatePassphraseFlags.enabled {
+			opts, err := generatePassphraseFlags.options()
+			if err != nil {
+				return err
+			}
+
+			pass, entropy, err = generatePassphrase(opts)
+			if err != nil {
+				return err
+			}
+		} else {
+			name, profile, err := findProfile(keyName, generateProfile)
+			if err != nil {
+				return err
+			}
+
+			if profile != nil {
+				for _, flag := range []string{"special", "numbers", "string"} {
+					if cmd.Flags().Changed(flag) {
+						return fmt.Errorf("--%s cannot be used with profile %s", flag, name)
+					}
+				}
+
+				length := password.DefaultLength
+				if cmd.Flags().Changed("length") {
+					length = generateLength
+				}
+
+				fmt.Printf("Using profile %s\n", name)
 
-		payload := vault.Payload{
+				pass, entropy, err = generateProfilePassword(profile, length)
+			} else {
+				pass, entropy, err = generatePassword(generateLength, generateSpecial, generateNumbers, generateString)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		payload := &vault.Payload{
 			Data: pass,
 		}
 
-		payloadEncoded, err := payload.Marshal()
-		if err != nil {
-			return fmt.Errorf("failed to marshal payload: %w", err)
+		if generateInPlace {
+			payload, err = loadPayload(keyName)
+			if err != nil {
+				return err
+			}
+
+			payload.Data = replaceFirstLine(payload.Data, pass)
 		}
 
-		encKeyName, err := encrypt.EncryptKey(keyName)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt key name: %w", err)
+		if err := generateExpiryFlags.apply(cmd, payload); err != nil {
+			return err
 		}
 
-		encValue, err := encrypt.EncryptValue(keyName, payloadEncoded)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt value: %w", err)
+		if err := savePayload(keyName, payload); err != nil {
+			return err
 		}
 
-		if err := store.SetKey(keyID, encKeyName, encValue); err != nil {
-			return fmt.Errorf("failed to store key: %w", err)
+		fmt.Printf("Password generated and stored successfully: %s (%.0f bits of entropy)\n", keyName, entropy)
+
+		if generatePrint {
+			fmt.Println(pass)
 		}
 
-		fmt.Println("Password generated and stored successfully:", keyName)
+		if generateClip {
+			return copyToClipboard(keyName, pass)
+		}
 
 		return nil
 	},
 }
 
+// findProfile returns the named profile, or without a name the profile with
+// the longest prefix matching the key name.
+func findProfile(keyName, profileName string) (string, *password.Profile, error) {
+	profiles := vaultConfig.Profiles
+
+	if profileName != "" {
+		profile, ok := profiles[profileName]
+		if !ok || profile == nil {
+			return "", nil, fmt.Errorf("profile not found: %s", profileName)
+		}
+
+		return profileName, profile, nil
+	}
+
+	var (
+		bestName   string
+		bestLength = -1
+	)
+
+	for _, name := range slices.Sorted(maps.Keys(profiles)) {
+		if profiles[name] == nil {
+			continue
+		}
+
+		if length, ok := profiles[name].MatchesKey(keyName); ok && length > bestLength {
+			bestName = name
+			bestLength = length
+		}
+	}
+
+	if bestName == "" {
+		return "", nil, nil
+	}
+
+	return bestName, profiles[bestName], nil
+}
+
+func generateProfilePassword(profile *password.Profile, length int) (string, float64, error) {
+	pass, err := password.GenerateProfile(profile, length)
+	if err != nil {
+		return "", 0, err
+	}
+
+	entropy, err := password.ProfileEntropy(profile, length)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return pass, entropy, nil
+}
+
+func replaceFirstLine(data, line string) string {
+	_, rest, found := strings.Cut(data, "\n")
+	if !found {
+		return line
+	}
+
+	return line + "\n" + rest
+}
+
 func init() {
 	generateCmd.Flags().BoolVarP(&generateForce, "force", "f", false, "Force overwrite existing key")
+	generateCmd.Flags().BoolVarP(&generateClip, "clip", "c", false, "Copy the generated password to clipboard")
+	generateCmd.Flags().BoolVarP(&generateInPlace, "in-place", "i", false, "Replace only the password line of an existing key")
+	generateCmd.Flags().BoolVarP(&generatePrint, "print", "p", false, "Print the generated password")
+	generateCmd.Flags().IntVarP(&generateLength, "length", "l", password.DefaultPasswordLength, "Password length")
+	generateCmd.Flags().IntVar(&generateSpecial, "special", password.DefaultSpecialCharsLength, "Number of special characters")
+	generateCmd.Flags().IntVar(&generateNumbers, "numbers", password.DefaultNumbersLength, "Number of numbers")
+	generateCmd.Flags().BoolVarP(&generateString, "string", "s", false, "String only characters")
+	generateCmd.Flags().StringVar(&generateProfile, "profile", "", "Generation profile from the vault config")
+
+	generatePassphraseFlags.register(generateCmd)
+	generateExpiryFlags.register(generateCmd)
 }
@@ -3,18 +3,29 @@ package commands
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/vitalvas/gopass/internal/qrcode"
 	"github.com/vitalvas/gopass/internal/vault"
 )
 
-var getQRCode bool
+var (
+	getQRCode   bool
+	getRevision int
+	getAll      bool
+	getClip     bool
+)
 
 var getCmd = &cobra.Command{
-	Use:     "get <key name>",
+	Use:     "get <key name> [field]",
+	Aliases: []string{"show"},
 	Short:   "Get a stored key",
-	Args:    cobra.ExactArgs(1),
+	Long: `Get a stored key.
+
+Without a field the password is printed. A field can be one of password,
+username, url, notes or the name of a custom field.`,
+	Args:    cobra.RangeArgs(1, 2),
 	PreRunE: loader,
 	RunE: func(_ *cobra.Command, args []string) error {
 		keyName := args[0]
@@ -22,16 +33,27 @@ var getCmd = &cobra.Command{
 			return err
 		}
 
-		keyID := encrypt.KeyID(keyName)
+		var value []byte
 
-		_, encValue, err := store.GetKey(keyID)
-		if err != nil {
-			return fmt.Errorf("failed to get key: %w", err)
-		}
+		if getRevision > 0 {
+			var err error
 
-		value, err := encrypt.DecryptValue(keyName, encValue)
-		if err != nil {
-			return fmt.Errorf("failed to decrypt value: %w", err)
+			value, err = getRevisionValue(keyName, getRevision)
+			if err != nil {
+				return err
+			}
+		} else {
+			keyID := encrypt.KeyID(keyName)
+
+			_, encValue, err := store.GetKey(keyID)
+			if err != nil {
+				return fmt.Errorf("failed to get key: %w", err)
+			}
+
+			value, err = encrypt.DecryptValue(keyName, encValue)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt value: %w", err)
+			}
 		}
 
 		payload, err := vault.PayloadUnmarshal(value)
@@ -39,11 +61,38 @@ var getCmd = &cobra.Command{
 			return fmt.Errorf("failed to unmarshal payload: %w", err)
 		}
 
+		if getRevision == 0 && payload.Expired(time.Now()) {
+			expiry, _ := payload.Expiry()
+			fmt.Fprintf(os.Stderr, "Warning: %s expired on %s\n", keyName, expiry.Local().Format(expiryDateFormat))
+		}
+
+		output := payload.Data
+
+		if len(args) > 1 {
+			field, ok := payload.GetField(args[1])
+			if !ok {
+				return fmt.Errorf("field not found: %s", args[1])
+			}
+
+			output = field
+		}
+
 		if getQRCode {
-			return qrcode.Print(os.Stdout, payload.Data)
+			return qrcode.Print(os.Stdout, output)
 		}
 
-		fmt.Println(payload.Data)
+		if getClip {
+			return copyToClipboard(keyName, output)
+		}
+
+		fmt.Println(output)
+
+		if getAll && len(args) == 1 {
+			for _, name := range payload.FieldNames() {
+				value, _ := payload.GetField(name)
+				fmt.Printf("%s: %s\n", name, value)
+			}
+		}
 
 		return nil
 	},
@@ -51,4 +100,7 @@ var getCmd = &cobra.Command{
 
 func init() {
 	getCmd.Flags().BoolVarP(&getQRCode, "qrcode", "q", false, "Display as QR code")
+	getCmd.Flags().BoolVarP(&getClip, "clip", "c", false, "Copy to clipboard instead of printing")
+	getCmd.Flags().BoolVarP(&getAll, "all", "a", false, "Also print all other fields")
+	getCmd.Flags().IntVarP(&getRevision, "revision", "r", 0, "Show a previous revision (see history)")
 }
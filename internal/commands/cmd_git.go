@@ -0,0 +1,314 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+const (
+	gitStrategyAsk    = "ask"
+	gitStrategyOurs   = "ours"
+	gitStrategyTheirs = "theirs"
+)
+
+var (
+	gitInitRemote string
+	gitRemote     string
+	gitStrategy   string
+	gitLogLimit   int
+)
+
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Sync the vault storage with git",
+	Long: `Sync the vault storage with git.
+
+With git enabled, every change of the file storage is committed. Commit
+messages never contain key names.`,
+}
+
+var gitInitCmd = &cobra.Command{
+	Use:     "init",
+	Short:   "Enable git for the vault storage",
+	Args:    cobra.NoArgs,
+	PreRunE: storeLoader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		git, err := gitStore()
+		if err != nil {
+			return err
+		}
+
+		if err := git.GitInit(gitInitRemote); err != nil {
+			return fmt.Errorf("failed to enable git: %w", err)
+		}
+
+		fmt.Printf("Git enabled for vault %s\n", vaultName)
+
+		return nil
+	},
+}
+
+var gitPushCmd = &cobra.Command{
+	Use:     "push",
+	Short:   "Push the vault storage to the remote",
+	Args:    cobra.NoArgs,
+	PreRunE: storeLoader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		git, err := gitStore()
+		if err != nil {
+			return err
+		}
+
+		if err := git.GitPush(gitRemote); err != nil {
+			return fmt.Errorf("failed to push: %w", err)
+		}
+
+		return nil
+	},
+}
+
+var gitPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Pull and merge the vault storage from the remote",
+	Long: `Pull and merge the vault storage from the remote.
+
+Keys changed on both sides are decrypted and shown side by side, and you are
+asked which version to keep. Use --strategy ours or theirs to resolve all
+conflicts without asking.`,
+	Args:    cobra.NoArgs,
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		switch gitStrategy {
+		case gitStrategyAsk, gitStrategyOurs, gitStrategyTheirs:
+		default:
+			return fmt.Errorf("invalid strategy: %s", gitStrategy)
+		}
+
+		git, err := gitStore()
+		if err != nil {
+			return err
+		}
+
+		conflicts, err := git.GitPull(gitRemote)
+		if err != nil {
+			return fmt.Errorf("failed to pull: %w", err)
+		}
+
+		if len(conflicts) == 0 {
+			return nil
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+
+		for _, conflict := range conflicts {
+			resolution, err := resolveGitConflict(reader, conflict)
+			if err != nil {
+				return errorWithAbort(git, err)
+			}
+
+			if resolution == 0 {
+				if err := git.AbortMerge(); err != nil {
+					return fmt.Errorf("failed to abort merge: %w", err)
+				}
+
+				fmt.Println("Merge aborted")
+
+				return nil
+			}
+
+			if err := git.ResolveConflict(conflict, resolution); err != nil {
+				return errorWithAbort(git, fmt.Errorf("failed to resolve conflict: %w", err))
+			}
+		}
+
+		if err := git.FinishMerge(); err != nil {
+			return fmt.Errorf("failed to finish merge: %w", err)
+		}
+
+		fmt.Printf("Resolved %d conflicts\n", len(conflicts))
+
+		return nil
+	},
+}
+
+var gitLogCmd = &cobra.Command{
+	Use:     "log",
+	Short:   "Show the commits of the vault storage",
+	Args:    cobra.NoArgs,
+	PreRunE: storeLoader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		git, err := gitStore()
+		if err != nil {
+			return err
+		}
+
+		commits, err := git.GitLog(gitLogLimit)
+		if err != nil {
+			return fmt.Errorf("failed to read log: %w", err)
+		}
+
+		for _, commit := range commits {
+			fmt.Printf("%s\t%s\t%s\t%s\n",
+				commit.Hash[:min(len(commit.Hash), 12)],
+				commit.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+				commit.Author,
+				commit.Message,
+			)
+		}
+
+		return nil
+	},
+}
+
+func errorWithAbort(git vault.GitVault, err error) error {
+	if abortErr := git.AbortMerge(); abortErr != nil {
+		return fmt.Errorf("%w (failed to abort merge: %v)", err, abortErr)
+	}
+
+	return err
+}
+
+// resolveGitConflict returns the resolution for a conflict, or 0 to abort
+// the merge.
+func resolveGitConflict(reader *bufio.Reader, conflict vault.Conflict) (vault.Resolution, error) {
+	switch gitStrategy {
+	case gitStrategyOurs:
+		return vault.ResolveOurs, nil
+	case gitStrategyTheirs:
+		return vault.ResolveTheirs, nil
+	}
+
+	ours, err := decryptConflictSide(conflict.Ours)
+	if err != nil {
+		return 0, err
+	}
+
+	theirs, err := decryptConflictSide(conflict.Theirs)
+	if err != nil {
+		return 0, err
+	}
+
+	keyName := ours.name
+	if keyName == "" {
+		keyName = theirs.name
+	}
+
+	fmt.Printf("\nConflict in %s\n", keyName)
+	fmt.Printf("  ours:   %s\n", describeConflictSide(ours, theirs))
+	fmt.Printf("  theirs: %s\n", describeConflictSide(theirs, ours))
+
+	for {
+		fmt.Print("Keep [o]urs, [t]heirs or [a]bort? ")
+
+		input, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("failed to read input: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "o", "ours":
+			return vault.ResolveOurs, nil
+		case "t", "theirs":
+			return vault.ResolveTheirs, nil
+		case "a", "abort":
+			return 0, nil
+		}
+
+		if err == io.EOF {
+			return 0, nil
+		}
+	}
+}
+
+type conflictEntry struct {
+	name    string
+	payload *vault.Payload
+}
+
+// decryptConflictSide decrypts one side of a conflict, a deleted side has no
+// payload.
+func decryptConflictSide(side *vault.ConflictSide) (conflictEntry, error) {
+	if side == nil {
+		return conflictEntry{}, nil
+	}
+
+	name, err := encrypt.DecryptKey(side.EncryptedKey)
+	if err != nil {
+		return conflictEntry{}, fmt.Errorf("failed to decrypt key name: %w", err)
+	}
+
+	value, err := encrypt.DecryptValue(name, side.EncryptedValue)
+	if err != nil {
+		return conflictEntry{}, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+
+	payload, err := vault.PayloadUnmarshal(value)
+	if err != nil {
+		return conflictEntry{}, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	return conflictEntry{name: name, payload: payload}, nil
+}
+
+// describeConflictSide summarizes one side of a conflict without printing
+// secrets: the password is only compared with the other side.
+func describeConflictSide(entry, other conflictEntry) string {
+	if entry.payload == nil {
+		return "deleted"
+	}
+
+	payload := entry.payload
+
+	var parts []string
+
+	if !payload.ModifiedAt.IsZero() {
+		parts = append(parts, "modified "+payload.ModifiedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	if other.payload != nil {
+		if payload.Data == other.payload.Data {
+			parts = append(parts, "same password")
+		} else {
+			parts = append(parts, "different password")
+		}
+	}
+
+	if payload.Username != "" {
+		parts = append(parts, "username "+payload.Username)
+	}
+
+	if payload.URL != "" {
+		parts = append(parts, "url "+payload.URL)
+	}
+
+	if len(payload.Fields) > 0 {
+		parts = append(parts, "fields "+strings.Join(slices.Sorted(maps.Keys(payload.Fields)), ","))
+	}
+
+	if payload.OTP != nil {
+		parts = append(parts, "otp")
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	gitInitCmd.Flags().StringVar(&gitInitRemote, "remote", "", "URL of the remote repository")
+	gitPushCmd.Flags().StringVar(&gitRemote, "remote", "", "Remote name or URL (default origin)")
+	gitPullCmd.Flags().StringVar(&gitRemote, "remote", "", "Remote name or URL (default origin)")
+	gitPullCmd.Flags().StringVar(&gitStrategy, "strategy", gitStrategyAsk, "Conflict strategy: ask, ours or theirs")
+	gitLogCmd.Flags().IntVarP(&gitLogLimit, "limit", "n", 20, "Number of commits to show, 0 for all")
+
+	gitCmd.AddCommand(gitInitCmd)
+	gitCmd.AddCommand(gitPushCmd)
+	gitCmd.AddCommand(gitPullCmd)
+	gitCmd.AddCommand(gitLogCmd)
+}
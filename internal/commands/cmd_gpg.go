@@ -75,25 +75,10 @@ var gpgExportCmd = &cobra.Command{
 			},
 		}
 
-		data, err := payload.Marshal()
-		if err != nil {
+		if err := savePayload(vaultKey, payload); err != nil {
 			return err
 		}
 
-		encKeyName, err := encrypt.EncryptKey(vaultKey)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt key name: %w", err)
-		}
-
-		encValue, err := encrypt.EncryptValue(vaultKey, data)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt value: %w", err)
-		}
-
-		if err := store.SetKey(keyID, encKeyName, encValue); err != nil {
-			return fmt.Errorf("failed to store key: %w", err)
-		}
-
 		fmt.Printf("GPG key exported to vault: %s\n", vaultKey)
 		fmt.Printf("Key ID: %s\n", keyInfo.KeyID)
 		fmt.Printf("User ID: %s\n", keyInfo.UserID)
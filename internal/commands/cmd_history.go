@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+var historyCmd = &cobra.Command{
+	Use:     "history <key name>",
+	Short:   "Show previous revisions of a key",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, args []string) error {
+		keyName := args[0]
+		if err := vault.ValidateKeyName(keyName); err != nil {
+			return err
+		}
+
+		history, err := historyStore()
+		if err != nil {
+			return err
+		}
+
+		keyID := encrypt.KeyID(keyName)
+
+		revisions, err := history.ListRevisions(keyID)
+		if err != nil {
+			return fmt.Errorf("failed to list revisions: %w", err)
+		}
+
+		_, _, getErr := store.GetKey(keyID)
+
+		if len(revisions) == 0 && getErr != nil {
+			return fmt.Errorf("key does not exist: %s", keyName)
+		}
+
+		for _, rev := range revisions {
+			fmt.Printf("%d\t%s\n", rev.Number, rev.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+		}
+
+		if getErr == nil {
+			fmt.Println("current")
+		} else {
+			fmt.Println("deleted")
+		}
+
+		return nil
+	},
+}
+
+var revertCmd = &cobra.Command{
+	Use:     "revert <key name> <revision>",
+	Short:   "Restore a key to a previous revision",
+	Args:    cobra.ExactArgs(2),
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, args []string) error {
+		keyName := args[0]
+		if err := vault.ValidateKeyName(keyName); err != nil {
+			return err
+		}
+
+		revision, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid revision: %s", args[1])
+		}
+
+		value, err := getRevisionValue(keyName, revision)
+		if err != nil {
+			return err
+		}
+
+		payload, err := vault.PayloadUnmarshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+
+		// A revert is a change, so the modification time is refreshed and
+		// password age checks start over.
+		if err := savePayload(keyName, payload); err != nil {
+			return err
+		}
+
+		fmt.Printf("Key %s reverted to revision %d\n", keyName, revision)
+
+		return nil
+	},
+}
+
+func getRevisionValue(keyName string, revision int) ([]byte, error) {
+	history, err := historyStore()
+	if err != nil {
+		return nil, err
+	}
+
+	encKeyName, encValue, err := history.GetRevision(encrypt.KeyID(keyName), revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision %d: %w", revision, err)
+	}
+
+	name, err := encrypt.DecryptKey(encKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt key name: %w", err)
+	}
+
+	if name != keyName {
+		return nil, fmt.Errorf("revision %d belongs to a different key", revision)
+	}
+
+	value, err := encrypt.DecryptValue(keyName, encValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+
+	return value, nil
+}
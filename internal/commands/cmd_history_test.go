@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestRevertRefreshesModifiedAt(t *testing.T) {
+	setupTestVault(t)
+
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, storePayload("/mail", &vault.Payload{Data: "old", CreatedAt: old, ModifiedAt: old}))
+	require.NoError(t, savePayload("/mail", &vault.Payload{Data: "new"}))
+
+	captureOutput(t, &os.Stdout)
+
+	require.NoError(t, revertCmd.RunE(revertCmd, []string{"/mail", "1"}))
+
+	payload, err := loadPayload("/mail")
+	require.NoError(t, err)
+
+	assert.Equal(t, "old", payload.Data)
+	assert.Equal(t, old, payload.CreatedAt)
+	assert.WithinDuration(t, time.Now(), payload.ModifiedAt, time.Minute)
+}
@@ -0,0 +1,279 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/gpgagent"
+	"github.com/vitalvas/gopass/internal/importer"
+	"github.com/vitalvas/gopass/internal/kdbx"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+var (
+	importForce  bool
+	importPrefix string
+	importDryRun bool
+
+	importPassGPGKey string
+
+	importKeePassKeyFile string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import entries from other password managers",
+}
+
+var importPassCmd = &cobra.Command{
+	Use:   "pass <password store dir>",
+	Short: "Import entries from a pass password store",
+	Long: `Import entries from a pass password store.
+
+All .gpg files are decrypted with the system gpg, or with --gpg-key using a
+GPG key stored in the vault. The first line is the password, otpauth:// lines
+become the OTP secret and "name: value" lines become fields. Paths that are
+not valid key names are renamed, the import prints a report of renamed and
+skipped entries.`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, args []string) error {
+		decrypt := decryptWithSystemGPG
+
+		if importPassGPGKey != "" {
+			if err := vault.ValidateKeyName(importPassGPGKey); err != nil {
+				return err
+			}
+
+			gpgKey, err := loadGPGKeyFromVault(importPassGPGKey)
+			if err != nil {
+				return err
+			}
+
+			if gpgKey.PrivateKey == "" {
+				return fmt.Errorf("no private key stored for %s", importPassGPGKey)
+			}
+
+			decrypt = func(ciphertext []byte) ([]byte, error) {
+				return gpgagent.Decrypt(gpgKey.PrivateKey, ciphertext)
+			}
+		}
+
+		result, err := importer.ReadPass(args[0], decrypt)
+		if err != nil {
+			return err
+		}
+
+		return storeImported(result)
+	},
+}
+
+var importKeePassCmd = &cobra.Command{
+	Use:   "keepass <file.kdbx>",
+	Short: "Import entries from a KeePass database",
+	Long: `Import entries from a KeePass KDBX 4 database.
+
+Groups become key paths and entries keep the password, username, URL, notes
+and custom fields. TOTP settings of KeePassXC and KeePass are stored as the
+OTP secret and attachments are stored as separate keys below their entry.
+Entries in the recycle bin are not imported. Leave the password empty to
+open a database protected with a key file only.`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, args []string) error {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer file.Close()
+
+		var creds kdbx.Credentials
+
+		if importKeePassKeyFile != "" {
+			creds.KeyFile, err = os.ReadFile(importKeePassKeyFile)
+			if err != nil {
+				return fmt.Errorf("failed to read key file: %w", err)
+			}
+		}
+
+		password, err := readPassphrase("Enter KeePass password: ")
+		if err != nil {
+			return err
+		}
+
+		if len(password) > 0 || creds.KeyFile == nil {
+			creds.Password = password
+		}
+
+		db, err := kdbx.Read(file, creds)
+		if errors.Is(err, kdbx.ErrInvalidCredentials) {
+			return fmt.Errorf("invalid password or key file")
+		} else if err != nil {
+			return fmt.Errorf("failed to read database: %w", err)
+		}
+
+		return storeImported(importer.ReadKeePass(db))
+	},
+}
+
+var importBitwardenCmd = &cobra.Command{
+	Use:   "bitwarden <export.json>",
+	Short: "Import entries from a Bitwarden JSON export",
+	Long: `Import entries from an unencrypted Bitwarden JSON export.
+
+Folders, or collections for organization exports, become key paths. Logins
+keep the username, password, URIs, TOTP secret, passkeys and custom fields,
+secure notes are stored as notes. Cards, identities and SSH keys are
+reported as skipped.`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, args []string) error {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open export: %w", err)
+		}
+		defer file.Close()
+
+		result, err := importer.ReadBitwarden(file)
+		if err != nil {
+			return err
+		}
+
+		return storeImported(result)
+	},
+}
+
+var importOnePasswordCmd = &cobra.Command{
+	Use:   "1password <export.1pux|export.csv>",
+	Short: "Import entries from a 1Password 1PUX or CSV export",
+	Long: `Import entries from a 1Password 1PUX or CSV export.
+
+1PUX exports keep vaults as the first segment of the key path, section
+fields become fields and one-time passwords the OTP secret. CSV exports are
+stored under the item title. 1Password does not export passkey private keys
+or attachments, items with passkeys are reported.`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: loader,
+	RunE: func(_ *cobra.Command, args []string) error {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open export: %w", err)
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to open export: %w", err)
+		}
+
+		// 1PUX exports are zip archives.
+		signature := make([]byte, 4)
+		if _, err := file.ReadAt(signature, 0); err == nil && bytes.Equal(signature, []byte("PK\x03\x04")) {
+			result, err := importer.ReadOnePUX(file, info.Size())
+			if err != nil {
+				return err
+			}
+
+			return storeImported(result)
+		}
+
+		result, err := importer.ReadOnePasswordCSV(file)
+		if err != nil {
+			return err
+		}
+
+		return storeImported(result)
+	},
+}
+
+func decryptWithSystemGPG(ciphertext []byte) ([]byte, error) {
+	cmd := exec.Command("gpg", "--quiet", "--decrypt")
+	cmd.Stdin = bytes.NewReader(ciphertext)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		return nil, fmt.Errorf("%s", lines[0])
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// storeImported writes the imported entries under the import prefix and
+// prints a report of renamed and skipped entries. With --dry-run nothing is
+// written and every key name is printed.
+func storeImported(result *importer.Result) error {
+	skipped := result.Skipped
+
+	var imported, renamed int
+
+	for _, entry := range result.Entries {
+		name := strings.TrimSuffix(importPrefix, "/") + entry.Name
+
+		if err := vault.ValidateKeyName(name); err != nil {
+			skipped = append(skipped, importer.Skipped{Source: entry.Source, Reason: err.Error()})
+			continue
+		}
+
+		if _, _, err := store.GetKey(encrypt.KeyID(name)); err == nil && !importForce {
+			skipped = append(skipped, importer.Skipped{Source: entry.Source, Reason: "key already exists, use --force to overwrite"})
+			continue
+		}
+
+		if importDryRun {
+			if entry.Renamed() {
+				fmt.Printf("Would import %s as %s\n", entry.Source, name)
+			} else {
+				fmt.Printf("Would import %s\n", name)
+			}
+		} else if err := savePayload(name, entry.Payload); err != nil {
+			return fmt.Errorf("failed to import %s: %w", entry.Source, err)
+		}
+
+		imported++
+
+		if entry.Renamed() {
+			renamed++
+
+			if !importDryRun {
+				fmt.Printf("Imported %s as %s\n", entry.Source, name)
+			}
+		}
+	}
+
+	for _, s := range skipped {
+		fmt.Printf("Skipped %s: %s\n", s.Source, s.Reason)
+	}
+
+	if importDryRun {
+		fmt.Printf("Would import %d entries, %d renamed, %d skipped\n", imported, renamed, len(skipped))
+		return nil
+	}
+
+	fmt.Printf("Imported %d entries, %d renamed, %d skipped\n", imported, renamed, len(skipped))
+
+	return nil
+}
+
+func init() {
+	importCmd.PersistentFlags().BoolVarP(&importForce, "force", "f", false, "Overwrite existing keys")
+	importCmd.PersistentFlags().StringVarP(&importPrefix, "prefix", "p", "", "Store the imported entries under this key prefix")
+	importCmd.PersistentFlags().BoolVarP(&importDryRun, "dry-run", "n", false, "Print the key names that would be created without writing them")
+
+	importPassCmd.Flags().StringVar(&importPassGPGKey, "gpg-key", "", "Decrypt with a GPG key stored in the vault instead of the system gpg")
+
+	importKeePassCmd.Flags().StringVar(&importKeePassKeyFile, "key-file", "", "Key file of the database")
+
+	importCmd.AddCommand(importPassCmd)
+	importCmd.AddCommand(importKeePassCmd)
+	importCmd.AddCommand(importBitwardenCmd)
+	importCmd.AddCommand(importOnePasswordCmd)
+}
@@ -13,101 +13,202 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/vitalvas/gopass/internal/encryptor"
 	"github.com/vitalvas/gopass/internal/vault"
-	"github.com/vitalvas/gopass/internal/vault/filevault"
+	"github.com/vitalvas/gopass/internal/vault/pluginvault"
 )
 
-var initAddress string
+var (
+	initAddress      string
+	initNoPassphrase bool
+)
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize a new password store",
 	RunE: func(_ *cobra.Command, _ []string) error {
-		parsed, err := url.Parse(initAddress)
+		address, err := newVaultAddress(initAddress)
 		if err != nil {
-			return fmt.Errorf("failed to parse store address: %w", err)
+			return err
+		}
+
+		var passphrase []byte
+
+		if !initNoPassphrase {
+			passphrase, err = readNewPassphrase()
+			if err != nil {
+				return err
+			}
 		}
 
-		if parsed.Scheme != "file" {
-			return fmt.Errorf("unsupported scheme: %s", parsed.Scheme)
+		if err := createVault(address, passphrase); err != nil {
+			return err
 		}
 
-		parsed.Path = strings.ReplaceAll(parsed.Path, "{{vault}}", vaultName)
+		fmt.Println("Vault initialized successfully")
+
+		return nil
+	},
+}
 
-		vaultConfigPath := fmt.Sprintf("%s/.gopass/%s.json", os.Getenv("HOME"), vaultName)
+// newVaultAddress expands the store address of a new vault and checks that
+// neither the vault nor its config exist yet.
+func newVaultAddress(address string) (string, error) {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse store address: %w", err)
+	}
 
+	parsed.Path = strings.ReplaceAll(parsed.Path, "{{vault}}", vaultName)
+
+	switch {
+	case parsed.Scheme == "file", parsed.Scheme == "sqlite":
 		if _, err := os.Stat(parsed.Path); err == nil {
-			return fmt.Errorf("vault already exists: %s", parsed.Path)
+			return "", fmt.Errorf("vault already exists: %s", parsed.Path)
 		}
 
 		if _, err := os.Stat(fmt.Sprintf("%s.json", parsed.Path)); err == nil {
-			return fmt.Errorf("vault config already exists: %s", parsed.Path)
+			return "", fmt.Errorf("vault config already exists: %s", parsed.Path)
 		}
 
-		if err := os.MkdirAll(parsed.Path, 0700); err != nil {
-			return fmt.Errorf("failed to create vault directory: %w", err)
+	case parsed.Scheme == "s3", parsed.Scheme == "https", pluginvault.IsAddress(parsed):
+		if err := checkRemoteVault(parsed); err != nil {
+			return "", err
 		}
 
-		keys, err := encryptor.GenerateKeys()
-		if err != nil {
-			return fmt.Errorf("failed to generate encryption keys: %w", err)
-		}
+	default:
+		return "", fmt.Errorf("unsupported scheme: %s", parsed.Scheme)
+	}
 
-		vaultConfig := vault.Config{
-			Name:    vaultName,
-			Address: parsed.String(),
-			Keys:    keys,
-		}
+	if _, err := os.Stat(configPath()); err == nil {
+		return "", fmt.Errorf("vault config already exists: %s", configPath())
+	}
 
-		configDir := strings.TrimRight(vaultConfigPath, filepath.Base(vaultConfigPath))
-		if _, err := os.Stat(configDir); os.IsNotExist(err) {
-			log.Printf("creating vault config directory: %s", configDir)
+	return parsed.String(), nil
+}
 
-			if err := os.MkdirAll(configDir, 0700); err != nil {
-				return fmt.Errorf("failed to create vault config directory: %w", err)
-			}
-		} else if err != nil {
-			return fmt.Errorf("failed to check vault config directory: %w", err)
-		}
+// checkRemoteVault checks that a remote store holds no vault yet.
+func checkRemoteVault(address *url.URL) error {
+	remote, err := openStore(address)
+	if err != nil {
+		return err
+	}
+	defer remote.Close()
+
+	// Bad credentials or an unreachable server would otherwise look like a
+	// missing vault.
+	if _, err := remote.ListKeys(); err != nil {
+		return err
+	}
+
+	if _, err := remote.GetTestKey(); err == nil {
+		return fmt.Errorf("vault already exists: %s", address.Redacted())
+	}
+
+	return nil
+}
 
-		file, err := os.OpenFile(vaultConfigPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-		if err != nil {
-			return fmt.Errorf("failed to open vault config file: %w", err)
+// createVault generates the keys of a new vault, writes its config and
+// initializes the store. The new vault is loaded as the current one.
+func createVault(address string, passphrase []byte) error {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse store address: %w", err)
+	}
+
+	vaultConfigPath := configPath()
+
+	if err := createStorageDir(parsed); err != nil {
+		return err
+	}
+
+	keys, err := encryptor.GenerateKeys()
+	if err != nil {
+		return fmt.Errorf("failed to generate encryption keys: %w", err)
+	}
+
+	enc, err := encryptor.NewEncryptor(keys)
+	if err != nil {
+		return fmt.Errorf("failed to create encryptor: %w", err)
+	}
+
+	if passphrase != nil {
+		if err := keys.Seal(passphrase); err != nil {
+			return fmt.Errorf("failed to seal encryption keys: %w", err)
 		}
+	}
 
-		defer file.Close()
+	vaultConfig = &vault.Config{
+		Name:    vaultName,
+		Address: parsed.String(),
+		Keys:    keys,
+	}
 
-		if err := json.NewEncoder(file).Encode(vaultConfig); err != nil {
-			return fmt.Errorf("failed to write vault config: %w", err)
-		}
+	configDir := strings.TrimRight(vaultConfigPath, filepath.Base(vaultConfigPath))
+	if _, err := os.Stat(configDir); os.IsNotExist(err) {
+		log.Printf("creating vault config directory: %s", configDir)
 
-		enc, err := encryptor.NewEncryptor(keys)
-		if err != nil {
-			return fmt.Errorf("failed to create encryptor: %w", err)
+		if err := os.MkdirAll(configDir, 0700); err != nil {
+			return fmt.Errorf("failed to create vault config directory: %w", err)
 		}
+	} else if err != nil {
+		return fmt.Errorf("failed to check vault config directory: %w", err)
+	}
 
-		store = filevault.New(parsed.Path)
+	file, err := os.OpenFile(vaultConfigPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open vault config file: %w", err)
+	}
 
-		testEncrypted, err := enc.EncryptKey("test")
-		if err != nil {
-			return fmt.Errorf("failed to encrypt key: %w", err)
-		}
+	defer file.Close()
 
-		if err := store.SetTestKey(testEncrypted); err != nil {
-			return fmt.Errorf("failed to set test key: %w", err)
-		}
+	if err := json.NewEncoder(file).Encode(vaultConfig); err != nil {
+		return fmt.Errorf("failed to write vault config: %w", err)
+	}
+
+	store, err = openStore(parsed)
+	if err != nil {
+		return err
+	}
+
+	encrypt = enc
+
+	testEncrypted, err := enc.EncryptKey("test")
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key: %w", err)
+	}
+
+	if err := store.SetTestKey(testEncrypted); err != nil {
+		return fmt.Errorf("failed to set test key: %w", err)
+	}
 
-		if resp, err := store.GetTestKey(); err != nil {
-			return fmt.Errorf("failed to get test key: %w", err)
-		} else if !bytes.Equal(resp, testEncrypted) {
-			return fmt.Errorf("test key mismatch")
+	if resp, err := store.GetTestKey(); err != nil {
+		return fmt.Errorf("failed to get test key: %w", err)
+	} else if !bytes.Equal(resp, testEncrypted) {
+		return fmt.Errorf("test key mismatch")
+	}
+
+	return nil
+}
+
+// createStorageDir creates the directory of a local store. The sqlite backend
+// stores the vault in a file inside the directory, remote backends need no
+// directory.
+func createStorageDir(address *url.URL) error {
+	switch address.Scheme {
+	case "file":
+		if err := os.MkdirAll(address.Path, 0700); err != nil {
+			return fmt.Errorf("failed to create vault directory: %w", err)
 		}
 
-		fmt.Println("Vault initialized successfully")
+	case "sqlite":
+		if err := os.MkdirAll(filepath.Dir(address.Path), 0700); err != nil {
+			return fmt.Errorf("failed to create vault directory: %w", err)
+		}
+	}
 
-		return nil
-	},
+	return nil
 }
 
 func init() {
 	initCmd.Flags().StringVar(&initAddress, "address", fmt.Sprintf("file://%s/.gopass/{{vault}}", os.Getenv("HOME")), "Store address")
+	initCmd.Flags().BoolVar(&initNoPassphrase, "no-passphrase", false, "Store the private key unencrypted in the vault config")
 }
@@ -14,20 +14,38 @@ import (
 var (
 	insertForce     bool
 	insertMultiline bool
+	insertFields    []string
+
+	insertExpiryFlags expiryFlags
 )
 
 var insertCmd = &cobra.Command{
 	Use:     "insert <key name>",
 	Aliases: []string{"set", "add"},
 	Short:   "Insert a new password",
+	Long: `Insert a new password.
+
+With --multiline the whole entry is read in the pass format: the password on
+the first line, then "login:", "url:" and other "name: value" lines, the
+otpauth:// URI and the notes. On a terminal it is written in $EDITOR.`,
 	Args:    cobra.ExactArgs(1),
 	PreRunE: loader,
-	RunE: func(_ *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		keyName := args[0]
 		if err := vault.ValidateKeyName(keyName); err != nil {
 			return err
 		}
 
+		for _, field := range insertFields {
+			if _, _, err := vault.ParseField(field); err != nil {
+				return err
+			}
+		}
+
+		if err := insertExpiryFlags.validate(cmd); err != nil {
+			return err
+		}
+
 		keyID := encrypt.KeyID(keyName)
 
 		if _, _, err := store.GetKey(keyID); err == nil {
@@ -36,17 +54,32 @@ var insertCmd = &cobra.Command{
 			}
 		}
 
-		var password string
+		payload := &vault.Payload{}
 
 		if insertMultiline {
-			fmt.Printf("Enter contents for %s (Ctrl+D to finish):\n", keyName)
+			var content string
+
+			if useEditor() {
+				edited, _, err := editContent(keyName, "")
+				if err != nil {
+					return err
+				}
+
+				content = edited
+			} else {
+				fmt.Printf("Enter contents for %s (Ctrl+D to finish):\n", keyName)
+
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("failed to read input: %w", err)
+				}
 
-			data, err := io.ReadAll(os.Stdin)
-			if err != nil {
-				return fmt.Errorf("failed to read input: %w", err)
+				content = string(data)
 			}
 
-			password = strings.TrimSuffix(string(data), "\n")
+			if err := applyEntryContent(payload, content); err != nil {
+				return err
+			}
 		} else {
 			fmt.Printf("Enter password for %s: ", keyName)
 
@@ -56,35 +89,26 @@ var insertCmd = &cobra.Command{
 				return fmt.Errorf("failed to read password: %w", err)
 			}
 
-			password = strings.TrimSuffix(line, "\n")
+			password := strings.TrimSuffix(line, "\n")
 			password = strings.TrimSuffix(password, "\r")
-		}
 
-		if password == "" {
-			return fmt.Errorf("password cannot be empty")
-		}
-
-		payload := vault.Payload{
-			Data: password,
-		}
+			if password == "" {
+				return fmt.Errorf("password cannot be empty")
+			}
 
-		payloadEncoded, err := payload.Marshal()
-		if err != nil {
-			return fmt.Errorf("failed to marshal payload: %w", err)
+			payload.Data = password
 		}
 
-		encKeyName, err := encrypt.EncryptKey(keyName)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt key name: %w", err)
+		if err := applyFields(payload, insertFields); err != nil {
+			return err
 		}
 
-		encValue, err := encrypt.EncryptValue(keyName, payloadEncoded)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt value: %w", err)
+		if err := insertExpiryFlags.apply(cmd, payload); err != nil {
+			return err
 		}
 
-		if err := store.SetKey(keyID, encKeyName, encValue); err != nil {
-			return fmt.Errorf("failed to store key: %w", err)
+		if err := savePayload(keyName, payload); err != nil {
+			return err
 		}
 
 		fmt.Println("Password stored successfully:", keyName)
@@ -95,5 +119,8 @@ var insertCmd = &cobra.Command{
 
 func init() {
 	insertCmd.Flags().BoolVarP(&insertForce, "force", "f", false, "Force overwrite existing key")
-	insertCmd.Flags().BoolVarP(&insertMultiline, "multiline", "m", false, "Read multi-line input until Ctrl+D")
+	insertCmd.Flags().BoolVarP(&insertMultiline, "multiline", "m", false, "Read multi-line input, from $EDITOR on a terminal or until Ctrl+D")
+	insertCmd.Flags().StringArrayVar(&insertFields, "field", nil, "Set a field as name=value (repeatable)")
+
+	insertExpiryFlags.register(insertCmd)
 }
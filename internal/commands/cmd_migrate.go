@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/encryptor"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+var migrateForce bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate stored keys to keyed key IDs",
+	Long: `Migrate stored keys from unkeyed to keyed key IDs.
+
+Vaults created before keyed key IDs use a plain hash of the key name as the
+storage ID, which lets anyone with the storage directory confirm whether a
+key name exists. This command will:
+1. Generate a vault-specific id key and store it next to the encryption keys
+2. Create a backup of the old configuration
+3. Rename every stored key to its keyed ID
+
+The command is safe to run again if it was interrupted.`,
+	PreRunE: localLoader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		allKeys, err := store.ListKeys()
+		if err != nil {
+			return fmt.Errorf("failed to list keys: %w", err)
+		}
+
+		fmt.Printf("Found %d keys to migrate\n", len(allKeys))
+
+		if !migrateForce {
+			fmt.Print("Continue? [y/N]: ")
+
+			confirm, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read confirmation: %w", err)
+			}
+
+			confirm = strings.TrimSpace(strings.ToLower(confirm))
+			if confirm != "y" && confirm != "yes" {
+				fmt.Println("Aborted")
+				return nil
+			}
+		}
+
+		newEncryptor := encrypt
+
+		if !encrypt.HasIDKey() {
+			newEncryptor, err = migrateConfigIDKey()
+			if err != nil {
+				return err
+			}
+		}
+
+		migrated := 0
+		failed := 0
+
+		for _, keyID := range allKeys {
+			encKeyName, _, err := store.GetKey(keyID)
+			if err != nil {
+				fmt.Printf("Warning: failed to get key, skipping: %v\n", err)
+				failed++
+
+				continue
+			}
+
+			keyName, err := encrypt.DecryptKey(encKeyName)
+			if err != nil {
+				fmt.Printf("Warning: failed to decrypt key name, skipping: %v\n", err)
+				failed++
+
+				continue
+			}
+
+			newKeyID := newEncryptor.KeyID(keyName)
+			if bytes.Equal(keyID, newKeyID) {
+				continue
+			}
+
+			if err := renameKey(keyID, newKeyID); err != nil {
+				fmt.Printf("Error: failed to migrate key %s: %v\n", keyName, err)
+				failed++
+
+				continue
+			}
+
+			migrated++
+		}
+
+		fmt.Printf("\nMigration complete:\n")
+		fmt.Printf("  Keys migrated: %d\n", migrated)
+		if failed > 0 {
+			fmt.Printf("  Keys failed: %d\n", failed)
+		}
+
+		return nil
+	},
+}
+
+func migrateConfigIDKey() (vaultEncryptor, error) {
+	idKey, err := encryptor.GenerateIDKey()
+	if err != nil {
+		return nil, err
+	}
+
+	newKeys := *vaultKeys
+	newKeys.IDKey = idKey
+
+	newEncryptor, err := encryptor.NewEncryptor(&newKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new encryptor: %w", err)
+	}
+
+	configData, err := os.ReadFile(configPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	backupPath := fmt.Sprintf("%s.backup.%d", configPath(), time.Now().Unix())
+	if err := os.WriteFile(backupPath, configData, 0600); err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	fmt.Printf("Config backup created: %s\n", backupPath)
+
+	sealedKeys := newKeys
+	if vaultPassphrase != nil {
+		if err := sealedKeys.Seal(vaultPassphrase); err != nil {
+			return nil, fmt.Errorf("failed to seal keys: %w", err)
+		}
+	}
+
+	vaultConfig.Keys = &sealedKeys
+
+	if err := configSave(); err != nil {
+		return nil, err
+	}
+
+	vaultKeys = &newKeys
+
+	if stopAgent() {
+		fmt.Println("Agent stopped, restart it to use the new keys")
+	}
+
+	return newEncryptor, nil
+}
+
+func renameKey(keyID, newKeyID []byte) error {
+	if renamer, ok := store.(vault.KeyRenamer); ok {
+		return renamer.RenameKey(keyID, newKeyID)
+	}
+
+	encKeyName, encValue, err := store.GetKey(keyID)
+	if err != nil {
+		return err
+	}
+
+	if err := store.SetKey(newKeyID, encKeyName, encValue); err != nil {
+		return err
+	}
+
+	return store.DeleteKey(keyID)
+}
+
+func init() {
+	migrateCmd.Flags().BoolVarP(&migrateForce, "force", "f", false, "Skip confirmation prompt")
+}
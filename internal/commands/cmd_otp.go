@@ -18,6 +18,8 @@ var otpCmd = &cobra.Command{
 	Short: "Manage OTP/TOTP secrets",
 }
 
+var otpCodeClip bool
+
 var otpCodeCmd = &cobra.Command{
 	Use:     "code <key name>",
 	Aliases: []string{"show", "get"},
@@ -69,6 +71,10 @@ var otpCodeCmd = &cobra.Command{
 			return fmt.Errorf("failed to generate OTP: %w", err)
 		}
 
+		if otpCodeClip {
+			return copyToClipboard(keyName+" OTP code", code)
+		}
+
 		fmt.Printf("%s (%ds remaining)\n", code, totp.RemainingSeconds())
 
 		return nil
@@ -171,31 +177,16 @@ Examples:
 			return fmt.Errorf("invalid OTP secret: %w", err)
 		}
 
-		payload := &vault.Payload{
-			OTP: otpData,
-		}
-
-		if existingPayload != nil {
-			payload.Data = existingPayload.Data
-		}
-
-		payloadEncoded, err := payload.Marshal()
-		if err != nil {
-			return fmt.Errorf("failed to marshal payload: %w", err)
-		}
-
-		encKeyName, err := encrypt.EncryptKey(keyName)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt key name: %w", err)
+		// The rest of an existing entry, including its timestamps, is kept.
+		payload := existingPayload
+		if payload == nil {
+			payload = &vault.Payload{}
 		}
 
-		newEncValue, err := encrypt.EncryptValue(keyName, payloadEncoded)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt value: %w", err)
-		}
+		payload.OTP = otpData
 
-		if err := store.SetKey(keyID, encKeyName, newEncValue); err != nil {
-			return fmt.Errorf("failed to store key: %w", err)
+		if err := savePayload(keyName, payload); err != nil {
+			return err
 		}
 
 		fmt.Println("OTP secret stored successfully:", keyName)
@@ -236,18 +227,7 @@ var otpURICmd = &cobra.Command{
 			return fmt.Errorf("key does not contain OTP secret")
 		}
 
-		digits := payload.OTP.Digits
-		if digits == 0 {
-			digits = otp.DefaultDigits
-		}
-
-		period := payload.OTP.Period
-		if period == 0 {
-			period = otp.DefaultPeriod
-		}
-
-		uri := fmt.Sprintf("otpauth://totp/%s?secret=%s&digits=%d&period=%d",
-			keyName, payload.OTP.Secret, digits, period)
+		uri := otp.FormatTOTPURI(keyName, payload.OTP.Secret, payload.OTP.Digits, payload.OTP.Period)
 
 		if otpURIQRCode {
 			return qrcode.Print(os.Stdout, uri)
@@ -260,6 +240,7 @@ var otpURICmd = &cobra.Command{
 }
 
 func init() {
+	otpCodeCmd.Flags().BoolVarP(&otpCodeClip, "clip", "c", false, "Copy the code to clipboard")
 	otpInsertCmd.Flags().BoolVarP(&otpInsertForce, "force", "f", false, "Force overwrite existing OTP")
 	otpURICmd.Flags().BoolVarP(&otpURIQRCode, "qrcode", "q", false, "Display as QR code")
 
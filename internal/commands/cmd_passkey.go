@@ -91,25 +91,10 @@ var passkeyCreateCmd = &cobra.Command{
 			},
 		}
 
-		data, err := payload.Marshal()
-		if err != nil {
+		if err := savePayload(key, payload); err != nil {
 			return err
 		}
 
-		encKeyName, err := encrypt.EncryptKey(key)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt key name: %w", err)
-		}
-
-		encValue, err := encrypt.EncryptValue(key, data)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt value: %w", err)
-		}
-
-		if err := store.SetKey(keyID, encKeyName, encValue); err != nil {
-			return fmt.Errorf("failed to store key: %w", err)
-		}
-
 		fmt.Printf("Passkey created for %s\n", rpID)
 		fmt.Printf("Credential ID: %s\n", cred.ID)
 
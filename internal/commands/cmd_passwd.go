@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var passwdNoPassphrase bool
+
+var passwdCmd = &cobra.Command{
+	Use:   "passwd",
+	Short: "Change the vault passphrase",
+	Long: `Change the passphrase protecting the vault private key.
+
+Only the vault config is rewritten; stored keys are not re-encrypted.
+An unprotected vault config is sealed with the new passphrase.`,
+	PreRunE: configLoader,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		keys, err := unsealKeys()
+		if err != nil {
+			return err
+		}
+
+		if passwdNoPassphrase {
+			vaultConfig.Keys = keys
+
+			if err := configSave(); err != nil {
+				return err
+			}
+
+			if stopAgent() {
+				fmt.Println("Agent stopped, restart it to use the new passphrase")
+			}
+
+			fmt.Println("Passphrase removed, private key is stored unencrypted")
+
+			return nil
+		}
+
+		passphrase, err := readNewPassphrase()
+		if err != nil {
+			return err
+		}
+
+		newKeys := *keys
+		if err := newKeys.Seal(passphrase); err != nil {
+			return fmt.Errorf("failed to seal encryption keys: %w", err)
+		}
+
+		vaultConfig.Keys = &newKeys
+
+		if err := configSave(); err != nil {
+			return err
+		}
+
+		if stopAgent() {
+			fmt.Println("Agent stopped, restart it to use the new passphrase")
+		}
+
+		fmt.Println("Passphrase changed successfully")
+
+		return nil
+	},
+}
+
+func init() {
+	passwdCmd.Flags().BoolVar(&passwdNoPassphrase, "no-passphrase", false, "Remove the passphrase and store the private key unencrypted")
+}
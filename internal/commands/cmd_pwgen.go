@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/vitalvas/gopass/internal/password"
@@ -13,35 +14,142 @@ var (
 	pwgenNumbers  int
 	pwgenVariants int
 	pwgenString   bool
+
+	pwgenPassphrase passphraseFlags
 )
 
 var pwgenCmd = &cobra.Command{
 	Use:   "pwgen",
-	Short: "Generate a random password",
+	Short: "Generate a random password or passphrase",
 	RunE: func(_ *cobra.Command, _ []string) error {
+		var (
+			entropy float64
+			opts    password.PassphraseOptions
+			err     error
+		)
+
+		if pwgenPassphrase.enabled {
+			opts, err = pwgenPassphrase.options()
+			if err != nil {
+				return err
+			}
+		}
+
 		for i := 0; i < pwgenVariants; i++ {
 			var (
-				length  = pwgenLength
-				special = pwgenSpecial
-				numbers = pwgenNumbers
+				pass string
+				bits float64
 			)
 
-			if pwgenString {
-				special = 0
-				numbers = 0
+			if pwgenPassphrase.enabled {
+				pass, bits, err = generatePassphrase(opts)
+			} else {
+				pass, bits, err = generatePassword(pwgenLength, pwgenSpecial, pwgenNumbers, pwgenString)
+			}
+
+			if err != nil {
+				return err
 			}
 
-			pass := password.Generate(length, special, numbers)
 			fmt.Println(pass)
+
+			entropy = bits
 		}
+
+		if entropy > 0 {
+			fmt.Fprintf(os.Stderr, "Entropy: %.1f bits\n", entropy)
+		}
+
 		return nil
 	},
 }
 
+// passphraseFlags are the word list passphrase options shared by pwgen and
+// generate.
+type passphraseFlags struct {
+	enabled    bool
+	words      int
+	separator  string
+	capitalize bool
+	digits     int
+	wordList   string
+}
+
+func (f *passphraseFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&f.enabled, "passphrase", "P", false, "Generate a passphrase from a word list")
+	cmd.Flags().IntVar(&f.words, "words", password.DefaultWords, "Number of words in a passphrase")
+	cmd.Flags().StringVar(&f.separator, "separator", password.DefaultSeparator, "Separator between passphrase words")
+	cmd.Flags().BoolVar(&f.capitalize, "capitalize", false, "Capitalize passphrase words")
+	cmd.Flags().IntVar(&f.digits, "digits", 0, "Number of random digits added to passphrase words")
+	cmd.Flags().StringVar(&f.wordList, "wordlist", "", "Word list file, one word per line (default EFF large word list)")
+}
+
+func (f *passphraseFlags) options() (password.PassphraseOptions, error) {
+	opts := password.PassphraseOptions{
+		Words:      f.words,
+		Separator:  f.separator,
+		Capitalize: f.capitalize,
+		Digits:     f.digits,
+	}
+
+	if f.wordList != "" {
+		file, err := os.Open(f.wordList)
+		if err != nil {
+			return opts, fmt.Errorf("failed to open word list: %w", err)
+		}
+
+		defer file.Close()
+
+		opts.WordList, err = password.ParseWordList(file)
+		if err != nil {
+			return opts, err
+		}
+	}
+
+	return opts, nil
+}
+
+// generatePassphrase returns a new passphrase and its entropy in bits.
+func generatePassphrase(opts password.PassphraseOptions) (string, float64, error) {
+	pass, err := password.GeneratePassphrase(opts)
+	if err != nil {
+		return "", 0, err
+	}
+
+	entropy, err := password.PassphraseEntropy(opts)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return pass, entropy, nil
+}
+
+// generatePassword returns a new password and its entropy in bits.
+func generatePassword(length, special, numbers int, stringOnly bool) (string, float64, error) {
+	if stringOnly {
+		special = 0
+		numbers = 0
+	}
+
+	pass, err := password.Generate(length, special, numbers)
+	if err != nil {
+		return "", 0, err
+	}
+
+	entropy, err := password.Entropy(length, special, numbers)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return pass, entropy, nil
+}
+
 func init() {
 	pwgenCmd.Flags().IntVarP(&pwgenLength, "length", "l", password.DefaultPasswordLength, "Password length")
 	pwgenCmd.Flags().IntVar(&pwgenSpecial, "special", password.DefaultSpecialCharsLength, "Number of special characters")
 	pwgenCmd.Flags().IntVar(&pwgenNumbers, "numbers", password.DefaultNumbersLength, "Number of numbers")
 	pwgenCmd.Flags().IntVarP(&pwgenVariants, "variants", "v", 5, "Number of variants")
 	pwgenCmd.Flags().BoolVarP(&pwgenString, "string", "s", false, "String only characters")
+
+	pwgenPassphrase.register(pwgenCmd)
 }
@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPwgenWordList(t *testing.T) {
+	pwgenPassphrase.wordList = "/nonexistent/words.txt"
+	t.Cleanup(func() { pwgenPassphrase.wordList = ""; pwgenPassphrase.enabled = false })
+
+	stdout := captureOutput(t, &os.Stdout)
+	captureOutput(t, &os.Stderr)
+
+	// The word list is only read for passphrases.
+	require.NoError(t, pwgenCmd.RunE(pwgenCmd, nil))
+	assert.Len(t, strings.Split(strings.TrimSpace(stdout()), "\n"), pwgenVariants)
+
+	pwgenPassphrase.enabled = true
+
+	err := pwgenCmd.RunE(pwgenCmd, nil)
+	assert.ErrorContains(t, err, "failed to open word list")
+}
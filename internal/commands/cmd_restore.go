@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/bundle"
+	"github.com/vitalvas/gopass/internal/encryptor"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+var (
+	restoreAddress       string
+	restoreNoPassphrase  bool
+	restoreIdentityVault string
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <bundle>",
+	Short: "Create a new vault from an exported bundle",
+	Long: `Create a new vault from a bundle written by "gopass export".
+
+The vault named by --vault must not exist yet, it gets new keys and the
+entries and settings of the bundle. Bundles encrypted to a public key are
+opened with the keys of the vault given with --identity-vault. Use --address
+to restore into a different location or backend.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		address, err := newVaultAddress(restoreAddress)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open bundle: %w", err)
+		}
+		defer file.Close()
+
+		b, err := bundle.Read(file)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Bundle of vault %s created %s with %d entries\n",
+			b.Header.Vault, b.Header.CreatedAt.Local().Format("2006-01-02 15:04"), b.Header.Entries)
+
+		contents, err := openBundle(b)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range contents.Entries {
+			if err := vault.ValidateKeyName(entry.Name); err != nil {
+				return fmt.Errorf("invalid key name in bundle: %w", err)
+			}
+
+			if entry.Payload == nil {
+				return fmt.Errorf("entry without payload in bundle: %s", entry.Name)
+			}
+		}
+
+		var passphrase []byte
+
+		if !restoreNoPassphrase {
+			passphrase, err = readNewPassphrase()
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := restoreVault(address, passphrase, contents); err != nil {
+			removeRestoredVault(address, contents)
+			return err
+		}
+
+		fmt.Printf("Restored %d entries into vault %s\n", len(contents.Entries), vaultName)
+
+		return nil
+	},
+}
+
+func restoreVault(address string, passphrase []byte, contents *bundle.Contents) error {
+	if err := createVault(address, passphrase); err != nil {
+		return err
+	}
+
+	if settings := contents.Settings; settings != nil {
+		vaultConfig.History = settings.History
+		vaultConfig.Clipboard = settings.Clipboard
+		vaultConfig.Profiles = settings.Profiles
+
+		if err := configSave(); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range contents.Entries {
+		if err := storePayload(entry.Name, entry.Payload); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// removeRestoredVault removes what a failed restore created, so it can be run
+// again. Local stores are removed as a whole. Remote stores lose the restored
+// entries, but the test key that marks the vault as existing cannot be removed
+// through the store and has to be deleted by hand.
+func removeRestoredVault(address string, contents *bundle.Contents) {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return
+	}
+
+	local := parsed.Scheme == "file" || parsed.Scheme == "sqlite"
+	testKeyLeft := false
+
+	if store != nil {
+		if !local && encrypt != nil {
+			for _, entry := range contents.Entries {
+				if err := store.DeleteKey(encrypt.KeyID(entry.Name)); err != nil && !errors.Is(err, vault.ErrKeyNotFound) {
+					fmt.Fprintf(os.Stderr, "Warning: failed to remove %s: %v\n", entry.Name, err)
+				}
+			}
+
+			_, err := store.GetTestKey()
+			testKeyLeft = err == nil
+		}
+
+		store.Close()
+		store = nil
+	}
+
+	var paths []string
+
+	switch parsed.Scheme {
+	case "file":
+		paths = append(paths, parsed.Path)
+	case "sqlite":
+		paths = append(paths, parsed.Path, parsed.Path+"-wal", parsed.Path+"-shm")
+	}
+
+	paths = append(paths, configPath())
+
+	for _, path := range paths {
+		if err := os.RemoveAll(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove %s, delete it before restoring again: %v\n", path, err)
+		}
+	}
+
+	if testKeyLeft {
+		fmt.Fprintf(os.Stderr, "Warning: delete the test key (.test_key) of %s before restoring again\n", parsed.Redacted())
+	}
+}
+
+func openBundle(b *bundle.Bundle) (*bundle.Contents, error) {
+	switch b.Header.Encryption {
+	case bundle.EncryptionPassphrase:
+		passphrase, err := readPassphrase("Enter bundle passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+
+		contents, err := b.DecryptWithPassphrase(passphrase)
+		if errors.Is(err, encryptor.ErrInvalidPassphrase) {
+			return nil, fmt.Errorf("failed to open bundle: invalid passphrase")
+		}
+
+		return contents, err
+
+	case bundle.EncryptionRecipient:
+		if restoreIdentityVault == "" {
+			return nil, fmt.Errorf("bundle is encrypted to key %s, use --identity-vault", b.Header.Recipient)
+		}
+
+		if err := vault.ValidateName(restoreIdentityVault); err != nil {
+			return nil, err
+		}
+
+		keys, err := identityKeys(restoreIdentityVault)
+		if err != nil {
+			return nil, err
+		}
+
+		return b.DecryptWithKeys(keys)
+	}
+
+	return nil, fmt.Errorf("unsupported bundle encryption: %s", b.Header.Encryption)
+}
+
+// identityKeys reads and unseals the keys of another vault.
+func identityKeys(name string) (*encryptor.Keys, error) {
+	data, err := os.ReadFile(vaultConfigPath(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault config: %w", err)
+	}
+
+	var config vault.Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse vault config: %w", err)
+	}
+
+	if config.Keys == nil {
+		return nil, fmt.Errorf("vault config has no keys")
+	}
+
+	if !config.Keys.IsSealed() {
+		return config.Keys, nil
+	}
+
+	passphrase, err := readPassphrase(fmt.Sprintf("Enter passphrase for vault %s: ", name))
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := config.Keys.Unseal(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock vault: %w", err)
+	}
+
+	return keys, nil
+}
+
+func init() {
+	restoreCmd.Flags().StringVar(&restoreAddress, "address", fmt.Sprintf("file://%s/.gopass/{{vault}}", os.Getenv("HOME")), "Store address of the new vault")
+	restoreCmd.Flags().BoolVar(&restoreNoPassphrase, "no-passphrase", false, "Store the private key of the new vault unencrypted in the vault config")
+	restoreCmd.Flags().StringVar(&restoreIdentityVault, "identity-vault", "", "Vault whose keys open a bundle encrypted to a public key")
+}
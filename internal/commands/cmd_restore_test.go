@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/bundle"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestRemoveRestoredVault(t *testing.T) {
+	setupTestVault(t)
+
+	contents := &bundle.Contents{Entries: []bundle.Entry{{Name: "/mail", Payload: &vault.Payload{Data: "secret"}}}}
+	require.NoError(t, storePayload("/mail", contents.Entries[0].Payload))
+
+	address := fmt.Sprintf("file://%s/.gopass/default", os.Getenv("HOME"))
+
+	removeRestoredVault(address, contents)
+
+	assert.Nil(t, store)
+	assert.NoFileExists(t, configPath())
+	assert.NoDirExists(t, fmt.Sprintf("%s/.gopass/default", os.Getenv("HOME")))
+
+	_, err := newVaultAddress(address)
+	assert.NoError(t, err)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/vitalvas/gopass/internal/encryptor"
+	"github.com/vitalvas/gopass/internal/vault"
 )
 
 var rotateCmd = &cobra.Command{
@@ -20,13 +21,13 @@ var rotateCmd = &cobra.Command{
 This command will:
 1. Generate new ML-KEM-768 encryption keys
 2. Decrypt all stored keys with the current keys
-3. Re-encrypt all keys with the new keys
+3. Re-encrypt all keys and their history with the new keys
 4. Update the vault configuration
 5. Create a backup of the old configuration
 
 WARNING: Keep a backup of your config file!
 If you lose it, you will not be able to access your stored data.`,
-	PreRunE: loader,
+	PreRunE: localLoader,
 	RunE: func(_ *cobra.Command, _ []string) error {
 		reader := bufio.NewReader(os.Stdin)
 
@@ -35,6 +36,9 @@ If you lose it, you will not be able to access your stored data.`,
 			return fmt.Errorf("failed to generate new keys: %w", err)
 		}
 
+		// Key IDs are derived from the id key, keep it so stored keys stay addressable.
+		newKeys.IDKey = vaultKeys.IDKey
+
 		newEncryptor, err := encryptor.NewEncryptor(newKeys)
 		if err != nil {
 			return fmt.Errorf("failed to create new encryptor: %w", err)
@@ -119,7 +123,7 @@ If you lose it, you will not be able to access your stored data.`,
 				continue
 			}
 
-			if err := store.SetKey(keyID, newEncKeyName, newEncValue); err != nil {
+			if err := storeRotated(newEncryptor, keyID, keyName, newEncKeyName, newEncValue); err != nil {
 				fmt.Printf("Error: failed to store new key %s: %v\n", keyName, err)
 				failed++
 
@@ -129,6 +133,12 @@ If you lose it, you will not be able to access your stored data.`,
 			rotated++
 		}
 
+		if vaultPassphrase != nil {
+			if err := newKeys.Seal(vaultPassphrase); err != nil {
+				return fmt.Errorf("failed to seal new keys: %w", err)
+			}
+		}
+
 		vaultConfig.Keys = newKeys
 
 		newConfigData, err := json.MarshalIndent(vaultConfig, "", "  ")
@@ -140,6 +150,10 @@ If you lose it, you will not be able to access your stored data.`,
 			return fmt.Errorf("failed to write config: %w", err)
 		}
 
+		if stopAgent() {
+			fmt.Println("Agent stopped, restart it to use the new keys")
+		}
+
 		testKey := []byte("rotation_test_" + fmt.Sprintf("%d", time.Now().Unix()))
 		if err := store.SetTestKey(testKey); err != nil {
 			return fmt.Errorf("failed to update test key: %w", err)
@@ -159,6 +173,61 @@ If you lose it, you will not be able to access your stored data.`,
 
 var rotateForce bool
 
+// storeRotated writes a re-encrypted key. With history the revisions are
+// re-encrypted first, all before anything is written, and the current value
+// is replaced without adding it to the history.
+func storeRotated(newEncryptor *encryptor.Encryptor, keyID []byte, keyName string, encKeyName, encValue []byte) error {
+	history, ok := store.(vault.HistoryVault)
+	if !ok {
+		return store.SetKey(keyID, encKeyName, encValue)
+	}
+
+	revisions, err := history.ListRevisions(keyID)
+	if err != nil {
+		return err
+	}
+
+	type rotatedRevision struct {
+		number   int
+		encKey   []byte
+		encValue []byte
+	}
+
+	rotated := make([]rotatedRevision, 0, len(revisions))
+
+	for _, rev := range revisions {
+		_, revValue, err := history.GetRevision(keyID, rev.Number)
+		if err != nil {
+			return err
+		}
+
+		value, err := encrypt.DecryptValue(keyName, revValue)
+		if err != nil {
+			return fmt.Errorf("revision %d: %w", rev.Number, err)
+		}
+
+		newEncKeyName, err := newEncryptor.EncryptKey(keyName)
+		if err != nil {
+			return err
+		}
+
+		newEncValue, err := newEncryptor.EncryptValue(keyName, value)
+		if err != nil {
+			return err
+		}
+
+		rotated = append(rotated, rotatedRevision{number: rev.Number, encKey: newEncKeyName, encValue: newEncValue})
+	}
+
+	for _, rev := range rotated {
+		if err := history.SetRevision(keyID, rev.number, rev.encKey, rev.encValue); err != nil {
+			return fmt.Errorf("revision %d: %w", rev.number, err)
+		}
+	}
+
+	return history.ReplaceKey(keyID, encKeyName, encValue)
+}
+
 func init() {
 	rotateCmd.Flags().BoolVarP(&rotateForce, "force", "f", false, "Skip confirmation prompt")
 }
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestRotateHistory(t *testing.T) {
+	setupTestVault(t)
+
+	require.NoError(t, savePayload("/mail", &vault.Payload{Data: "old"}))
+	require.NoError(t, savePayload("/mail", &vault.Payload{Data: "new"}))
+
+	require.NoError(t, store.Close())
+	require.NoError(t, localLoader(rotateCmd, nil))
+
+	rotateForce = true
+	t.Cleanup(func() { rotateForce = false })
+
+	stdout := captureOutput(t, &os.Stdout)
+	require.NoError(t, rotateCmd.RunE(rotateCmd, nil))
+	assert.Contains(t, stdout(), "Keys rotated: 1\n")
+
+	require.NoError(t, store.Close())
+	require.NoError(t, localLoader(rotateCmd, nil))
+
+	payload, err := loadPayload("/mail")
+	require.NoError(t, err)
+	assert.Equal(t, "new", payload.Data)
+
+	history, err := historyStore()
+	require.NoError(t, err)
+
+	keyID := encrypt.KeyID("/mail")
+
+	revisions, err := history.ListRevisions(keyID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+
+	_, encValue, err := history.GetRevision(keyID, revisions[0].Number)
+	require.NoError(t, err)
+
+	value, err := encrypt.DecryptValue("/mail", encValue)
+	require.NoError(t, err)
+
+	old, err := vault.PayloadUnmarshal(value)
+	require.NoError(t, err)
+	assert.Equal(t, "old", old.Data)
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/vault/httpvault"
+)
+
+var (
+	serveStorage   string
+	serveListen    string
+	serveTLSCert   string
+	serveTLSKey    string
+	serveClientCA  string
+	serveTokenFile string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a vault storage over HTTPS",
+	Long: `Serve a vault storage over HTTPS for clients using an https:// address.
+
+The server stores the encrypted keys and values as they are sent by the
+clients. It needs no vault config and never sees the vault keys or any
+plaintext. Clients are authenticated with a bearer token (--token-file or
+GOPASS_SERVER_TOKEN), a client certificate signed by --client-ca, or both.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if serveStorage == "" {
+			return errors.New("--storage is required")
+		}
+
+		if serveTLSCert == "" || serveTLSKey == "" {
+			return errors.New("--tls-cert and --tls-key are required")
+		}
+
+		token := os.Getenv(httpvault.TokenEnv)
+
+		if serveTokenFile != "" {
+			data, err := os.ReadFile(serveTokenFile)
+			if err != nil {
+				return fmt.Errorf("failed to read token file: %w", err)
+			}
+
+			token = strings.TrimSpace(string(data))
+		}
+
+		if token == "" && serveClientCA == "" {
+			return errors.New("a token or --client-ca is required to authenticate clients")
+		}
+
+		tlsConfig, err := httpvault.ServerTLSConfig(serveTLSCert, serveTLSKey, serveClientCA)
+		if err != nil {
+			return err
+		}
+
+		parsed, err := url.Parse(serveStorage)
+		if err != nil {
+			return fmt.Errorf("failed to parse store address: %w", err)
+		}
+
+		if err := createStorageDir(parsed); err != nil {
+			return err
+		}
+
+		backend, err := openStore(parsed)
+		if err != nil {
+			return err
+		}
+		defer backend.Close()
+
+		server := &http.Server{
+			Addr:              serveListen,
+			Handler:           httpvault.NewServer(backend, token),
+			TLSConfig:         tlsConfig,
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+		}
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		go func() {
+			<-sigChan
+			fmt.Println("\nShutting down...")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			server.Shutdown(ctx)
+		}()
+
+		fmt.Printf("Serving %s on %s\n", parsed.Redacted(), serveListen)
+
+		if err := server.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveStorage, "storage", "", "Address of the storage to serve, e.g. file:///srv/gopass/team")
+	serveCmd.Flags().StringVar(&serveListen, "listen", ":8443", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveTLSCert, "tls-cert", "", "Server certificate file")
+	serveCmd.Flags().StringVar(&serveTLSKey, "tls-key", "", "Server private key file")
+	serveCmd.Flags().StringVar(&serveClientCA, "client-ca", "", "CA certificate file to require client certificates")
+	serveCmd.Flags().StringVar(&serveTokenFile, "token-file", "", "File with the bearer token of the clients")
+}
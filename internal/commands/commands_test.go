@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestVault creates a file vault without passphrase in a temporary home
+// and loads it as the current vault.
+func setupTestVault(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("HOME", t.TempDir())
+
+	vaultName = "default"
+
+	require.NoError(t, createVault(fmt.Sprintf("file://%s/.gopass/default", os.Getenv("HOME")), nil))
+
+	t.Cleanup(func() {
+		if store != nil {
+			store.Close()
+		}
+
+		store = nil
+		encrypt = nil
+		vaultConfig = nil
+	})
+}
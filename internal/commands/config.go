@@ -7,15 +7,31 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/agent"
 	"github.com/vitalvas/gopass/internal/encryptor"
 	"github.com/vitalvas/gopass/internal/vault"
 	"github.com/vitalvas/gopass/internal/vault/filevault"
+	"github.com/vitalvas/gopass/internal/vault/httpvault"
+	"github.com/vitalvas/gopass/internal/vault/pluginvault"
+	"github.com/vitalvas/gopass/internal/vault/s3vault"
+	"github.com/vitalvas/gopass/internal/vault/sqlitevault"
 )
 
+type vaultEncryptor interface {
+	KeyID(keyName string) []byte
+	HasIDKey() bool
+	EncryptKey(text string) ([]byte, error)
+	DecryptKey(text []byte) (string, error)
+	EncryptValue(key string, text []byte) ([]byte, error)
+	DecryptValue(key string, text []byte) ([]byte, error)
+}
+
 var (
-	vaultConfig *vault.Config
-	store       vault.Vault
-	encrypt     *encryptor.Encryptor
+	vaultConfig     *vault.Config
+	vaultKeys       *encryptor.Keys
+	vaultPassphrase []byte
+	store           vault.Vault
+	encrypt         vaultEncryptor
 )
 
 func init() {
@@ -28,10 +44,16 @@ func init() {
 	}()
 }
 
-func configLoader(_ *cobra.Command, _ []string) error {
-	configPath := fmt.Sprintf("%s/.gopass/%s.json", os.Getenv("HOME"), vaultName)
+func configPath() string {
+	return vaultConfigPath(vaultName)
+}
 
-	configFile, err := os.Open(configPath)
+func vaultConfigPath(name string) string {
+	return fmt.Sprintf("%s/.gopass/%s.json", os.Getenv("HOME"), name)
+}
+
+func configLoader(_ *cobra.Command, _ []string) error {
+	configFile, err := os.Open(configPath())
 	if err != nil {
 		return err
 	}
@@ -41,37 +63,178 @@ func configLoader(_ *cobra.Command, _ []string) error {
 	return json.NewDecoder(configFile).Decode(&vaultConfig)
 }
 
+func configSave() error {
+	data, err := json.MarshalIndent(vaultConfig, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	tmpPath := configPath() + ".tmp"
+
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, configPath()); err != nil {
+		return fmt.Errorf("failed to replace config: %w", err)
+	}
+
+	return nil
+}
+
 func vaultLoader(_ *cobra.Command, _ []string) error {
 	parsed, err := url.Parse(vaultConfig.Address)
 	if err != nil {
 		return fmt.Errorf("failed to parse vault address: %w", err)
 	}
 
-	switch parsed.Scheme {
+	store, err = openStore(parsed)
+	if err != nil {
+		return err
+	}
+
+	if resp, err := store.GetTestKey(); err != nil {
+		return fmt.Errorf("failed to get test key: %w", err)
+	} else if resp == nil {
+		return fmt.Errorf("failed to get test key: response is nil")
+	}
+
+	if history, ok := store.(vault.HistoryVault); ok {
+		history.SetHistory(vaultConfig.History)
+	}
+
+	return nil
+}
+
+func openStore(address *url.URL) (vault.Vault, error) {
+	switch address.Scheme {
 	case "file":
-		store = filevault.New(parsed.Path)
+		return filevault.New(address.Path), nil
+
+	case "sqlite":
+		v, err := sqlitevault.New(address.Path)
+		if err != nil {
+			return nil, err
+		}
 
-		if resp, err := store.GetTestKey(); err != nil {
-			return fmt.Errorf("failed to get test key: %w", err)
-		} else if resp == nil {
-			return fmt.Errorf("failed to get test key: response is nil")
+		return v, nil
+
+	case "s3":
+		v, err := s3vault.New(address)
+		if err != nil {
+			return nil, err
+		}
+
+		return v, nil
+
+	case "https":
+		v, err := httpvault.New(address)
+		if err != nil {
+			return nil, err
 		}
 
+		return v, nil
+
 	default:
-		return fmt.Errorf("unsupported scheme: %s", parsed.Scheme)
+		if pluginvault.IsAddress(address) {
+			return pluginvault.New(address)
+		}
+
+		return nil, fmt.Errorf("unsupported scheme: %s", address.Scheme)
+	}
+}
+
+func historyStore() (vault.HistoryVault, error) {
+	history, ok := store.(vault.HistoryVault)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support history")
+	}
+
+	return history, nil
+}
+
+func gitStore() (vault.GitVault, error) {
+	git, ok := store.(vault.GitVault)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support git")
+	}
+
+	return git, nil
+}
+
+func unsealKeys() (*encryptor.Keys, error) {
+	if vaultConfig.Keys == nil {
+		return nil, fmt.Errorf("vault config has no keys")
+	}
+
+	if !vaultConfig.Keys.IsSealed() {
+		return vaultConfig.Keys, nil
+	}
+
+	passphrase, err := readPassphrase(fmt.Sprintf("Enter passphrase for vault %s: ", vaultName))
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := vaultConfig.Keys.Unseal(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock vault: %w", err)
+	}
+
+	vaultPassphrase = passphrase
+
+	return keys, nil
+}
+
+func encryptLoader(cmd *cobra.Command, args []string) error {
+	client, err := agent.Dial(agentSocketPath())
+	if err != nil {
+		return localEncryptLoader(cmd, args)
+	}
+
+	locked, err := client.Locked()
+	if err != nil {
+		return fmt.Errorf("failed to get agent status: %w", err)
+	}
+
+	if locked {
+		var passphrase []byte
+
+		if vaultConfig.Keys != nil && vaultConfig.Keys.IsSealed() {
+			passphrase, err = readPassphrase(fmt.Sprintf("Enter passphrase for vault %s: ", vaultName))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := client.Unlock(passphrase); err != nil {
+			return fmt.Errorf("failed to unlock agent: %w", err)
+		}
+	}
+
+	encrypt, err = agent.NewEncryptor(client)
+	if err != nil {
+		return fmt.Errorf("failed to create agent encryptor: %w", err)
 	}
 
 	return nil
 }
 
-func encryptLoader(_ *cobra.Command, _ []string) error {
-	var err error
+// localEncryptLoader unseals the keys in this process, bypassing the agent.
+// Commands that need the private keys themselves must use it.
+func localEncryptLoader(_ *cobra.Command, _ []string) error {
+	keys, err := unsealKeys()
+	if err != nil {
+		return err
+	}
 
-	encrypt, err = encryptor.NewEncryptor(vaultConfig.Keys)
+	encrypt, err = encryptor.NewEncryptor(keys)
 	if err != nil {
 		return fmt.Errorf("failed to create encryptor: %w", err)
 	}
 
+	vaultKeys = keys
+
 	return nil
 }
 
@@ -86,3 +249,25 @@ func loader(cmd *cobra.Command, args []string) error {
 
 	return vaultLoader(cmd, args)
 }
+
+func localLoader(cmd *cobra.Command, args []string) error {
+	if err := configLoader(cmd, args); err != nil {
+		return err
+	}
+
+	if err := localEncryptLoader(cmd, args); err != nil {
+		return err
+	}
+
+	return vaultLoader(cmd, args)
+}
+
+// storeLoader opens the storage without the vault keys, for commands that do
+// not encrypt or decrypt.
+func storeLoader(cmd *cobra.Command, args []string) error {
+	if err := configLoader(cmd, args); err != nil {
+		return err
+	}
+
+	return vaultLoader(cmd, args)
+}
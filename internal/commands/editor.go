@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+const defaultEditor = "vi"
+
+// useEditor reports whether interactive input should go through $EDITOR.
+// Piped input keeps working as before, so scripts are not affected.
+func useEditor() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+func editorCommand() []string {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		return []string{defaultEditor}
+	}
+
+	return editor
+}
+
+// editorTempDir returns a memory-backed directory for the decrypted temp
+// file, so that secrets are not written to a persistent disk.
+func editorTempDir() string {
+	candidates := []string{"/dev/shm", os.Getenv("XDG_RUNTIME_DIR")}
+
+	for _, dir := range candidates {
+		if dir == "" {
+			continue
+		}
+
+		if info, err := os.Stat(dir); err == nil && info.IsDir() && unix.Access(dir, unix.W_OK) == nil {
+			return dir
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Warning: no tmpfs directory found, the temporary file is created on disk")
+
+	return os.TempDir()
+}
+
+// editContent opens content in $EDITOR and returns the edited content and
+// whether it was changed. The temp file is wiped before it is removed.
+func editContent(keyName, content string) (string, bool, error) {
+	tmpDir, err := os.MkdirTemp(editorTempDir(), "gopass-")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	tmpFile := filepath.Join(tmpDir, strings.ReplaceAll(strings.TrimPrefix(keyName, "/"), "/", "-")+".txt")
+
+	defer wipeFile(tmpFile)
+
+	original := []byte(content)
+	if len(original) > 0 && !bytes.HasSuffix(original, []byte("\n")) {
+		original = append(original, '\n')
+	}
+
+	if err := os.WriteFile(tmpFile, original, 0600); err != nil {
+		return "", false, fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	// The editor handles Ctrl+C itself, gopass has to survive it to wipe
+	// the temp file.
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	defer signal.Stop(sigChan)
+
+	editor := editorCommand()
+
+	cmd := exec.Command(editor[0], append(editor[1:], tmpFile)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", false, fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+
+	select {
+	case sig := <-sigChan:
+		return "", false, fmt.Errorf("interrupted by %s", sig)
+	default:
+	}
+
+	edited, err := os.ReadFile(tmpFile)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read temp file: %w", err)
+	}
+
+	if bytes.Equal(edited, original) {
+		return content, false, nil
+	}
+
+	return strings.TrimSuffix(string(edited), "\n"), true, nil
+}
+
+// wipeFile overwrites the file with zeros before removing it.
+func wipeFile(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err == nil {
+		file.Write(make([]byte, info.Size()))
+		file.Sync()
+		file.Close()
+	}
+
+	return os.Remove(path)
+}
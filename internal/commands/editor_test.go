@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setEditor sets $EDITOR to a shell script with the given body, the temp file
+// is passed as $1.
+func setEditor(t *testing.T, body string) {
+	t.Helper()
+
+	script := filepath.Join(t.TempDir(), "editor.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"+body+"\n"), 0700))
+
+	t.Setenv("EDITOR", script)
+}
+
+func TestEditContent(t *testing.T) {
+	t.Run("edited content", func(t *testing.T) {
+		setEditor(t, `printf 'new-secret\nline2\n' > "$1"`)
+
+		content, changed, err := editContent("/web/site", "old-secret")
+		require.NoError(t, err)
+
+		assert.True(t, changed)
+		assert.Equal(t, "new-secret\nline2", content)
+	})
+
+	t.Run("original content is passed to the editor", func(t *testing.T) {
+		record := filepath.Join(t.TempDir(), "content")
+		setEditor(t, `cp "$1" `+record)
+
+		_, _, err := editContent("/web/site", "old-secret\nnotes")
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(record)
+		require.NoError(t, err)
+		assert.Equal(t, "old-secret\nnotes\n", string(data))
+	})
+
+	t.Run("unchanged content", func(t *testing.T) {
+		setEditor(t, "exit 0")
+
+		content, changed, err := editContent("/web/site", "old-secret")
+		require.NoError(t, err)
+
+		assert.False(t, changed)
+		assert.Equal(t, "old-secret", content)
+	})
+
+	t.Run("editor failure", func(t *testing.T) {
+		setEditor(t, "exit 1")
+
+		_, _, err := editContent("/web/site", "old-secret")
+		assert.ErrorContains(t, err, "failed: exit status 1")
+	})
+
+	t.Run("temp file is wiped and removed", func(t *testing.T) {
+		// A hard link next to the temp directory keeps the file content
+		// readable after the temp file is removed.
+		record := filepath.Join(t.TempDir(), "path")
+		setEditor(t, `link="$(dirname "$(dirname "$1")")/gopass-test-$$"
+ln "$1" "$link"
+echo "$1" > `+record+`
+echo "$link" >> `+record)
+
+		_, changed, err := editContent("/web/site", "old-secret")
+		require.NoError(t, err)
+		assert.False(t, changed)
+
+		data, err := os.ReadFile(record)
+		require.NoError(t, err)
+
+		paths := strings.Fields(string(data))
+		require.Len(t, paths, 2)
+
+		tmpFile, link := paths[0], paths[1]
+		t.Cleanup(func() { os.Remove(link) })
+
+		assert.NoFileExists(t, tmpFile)
+		assert.NoDirExists(t, filepath.Dir(tmpFile))
+
+		wiped, err := os.ReadFile(link)
+		require.NoError(t, err)
+		assert.Equal(t, bytes.Repeat([]byte{0}, len("old-secret\n")), wiped)
+	})
+}
+
+func TestEditorTempDir(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "")
+
+	dir := editorTempDir()
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+}
+
+func TestWipeFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "secret.txt")
+	require.NoError(t, os.WriteFile(path, []byte("secret"), 0600))
+
+	link := filepath.Join(dir, "link")
+	require.NoError(t, os.Link(path, link))
+
+	require.NoError(t, wipeFile(path))
+
+	assert.NoFileExists(t, path)
+
+	data, err := os.ReadFile(link)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0}, data)
+
+	assert.NoError(t, wipeFile(path))
+}
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 	"github.com/vitalvas/gopass/internal/vault"
 	"github.com/vitalvas/gopass/internal/version"
@@ -18,7 +20,15 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+
+	if store != nil {
+		if closeErr := store.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close store: %w", closeErr)
+		}
+	}
+
+	return err
 }
 
 func init() {
@@ -40,4 +50,18 @@ func init() {
 	rootCmd.AddCommand(passkeyCmd)
 	rootCmd.AddCommand(gpgCmd)
 	rootCmd.AddCommand(rotateCmd)
+	rootCmd.AddCommand(passwdCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(agentCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(revertCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(breachcheckCmd)
+	rootCmd.AddCommand(expiringCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(gitCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(clipboardRestoreCmd)
 }
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+const expiryDateFormat = "2006-01-02"
+
+// expiryFlags are the --expires and --max-age flags shared by the commands
+// that write entries.
+type expiryFlags struct {
+	expires string
+	maxAge  string
+}
+
+func (f *expiryFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.expires, "expires", "", "Expiry date as YYYY-MM-DD or RFC 3339 (empty to clear)")
+	cmd.Flags().StringVar(&f.maxAge, "max-age", "", "Maximum age since the last change, e.g. 90d or 12w (0 to clear)")
+}
+
+func (f *expiryFlags) changed(cmd *cobra.Command) bool {
+	return cmd.Flags().Changed("expires") || cmd.Flags().Changed("max-age")
+}
+
+// validate checks the flag values before anything is prompted or generated.
+func (f *expiryFlags) validate(cmd *cobra.Command) error {
+	return f.apply(cmd, &vault.Payload{})
+}
+
+func (f *expiryFlags) apply(cmd *cobra.Command, payload *vault.Payload) error {
+	if cmd.Flags().Changed("expires") {
+		expiresAt, err := parseExpiryDate(f.expires)
+		if err != nil {
+			return err
+		}
+
+		payload.ExpiresAt = expiresAt
+	}
+
+	if cmd.Flags().Changed("max-age") {
+		maxAge, err := parseDays(f.maxAge)
+		if err != nil {
+			return fmt.Errorf("invalid max age: %w", err)
+		}
+
+		payload.MaxAgeDays = maxAge
+	}
+
+	return nil
+}
+
+// parseExpiryDate accepts a date, which expires at the start of that day in
+// local time, or an RFC 3339 timestamp. An empty value clears the expiry.
+func parseExpiryDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.ParseInLocation(expiryDateFormat, value, time.Local); err == nil {
+		return date.UTC(), nil
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry date %q, use YYYY-MM-DD or RFC 3339", value)
+	}
+
+	return date.UTC(), nil
+}
+
+// parseDays parses a number of days as "30d", "4w" or a plain number. Go
+// durations are accepted as well and rounded up to whole days.
+func parseDays(value string) (int, error) {
+	original := value
+	value = strings.TrimSpace(value)
+
+	multiplier := 1
+
+	switch {
+	case strings.HasSuffix(value, "d"):
+		value = strings.TrimSuffix(value, "d")
+
+	case strings.HasSuffix(value, "w"):
+		value = strings.TrimSuffix(value, "w")
+		multiplier = 7
+	}
+
+	if days, err := strconv.Atoi(value); err == nil {
+		if days < 0 {
+			return 0, fmt.Errorf("negative number of days: %s", original)
+		}
+
+		return days * multiplier, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || multiplier != 1 {
+		return 0, fmt.Errorf("invalid number of days: %q", original)
+	}
+
+	if duration < 0 {
+		return 0, fmt.Errorf("negative duration: %s", original)
+	}
+
+	day := 24 * time.Hour
+
+	return int((duration + day - 1) / day), nil
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+func readPassphrase(prompt string) ([]byte, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err == nil {
+		defer tty.Close()
+
+		fmt.Fprint(tty, prompt)
+
+		passphrase, err := term.ReadPassword(int(tty.Fd()))
+
+		fmt.Fprintln(tty)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+
+		return passphrase, nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+
+	line, err := readLineUnbuffered(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+// readLineUnbuffered reads a single line byte by byte, so that the rest of
+// the input stays available for the command that prompted for a passphrase.
+func readLineUnbuffered(r io.Reader) ([]byte, error) {
+	var line []byte
+
+	buf := make([]byte, 1)
+
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			line = append(line, buf[0])
+
+			if buf[0] == '\n' {
+				return line, nil
+			}
+		}
+
+		if err == io.EOF {
+			return line, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func readNewPassphrase() ([]byte, error) {
+	return readConfirmedPassphrase("new passphrase")
+}
+
+// readConfirmedPassphrase prompts twice for a non-empty passphrase.
+func readConfirmedPassphrase(name string) ([]byte, error) {
+	passphrase, err := readPassphrase(fmt.Sprintf("Enter %s: ", name))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+
+	confirm, err := readPassphrase(fmt.Sprintf("Repeat %s: ", name))
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(passphrase, confirm) {
+		return nil, fmt.Errorf("passphrases do not match")
+	}
+
+	return passphrase, nil
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/exporter"
+	"github.com/vitalvas/gopass/internal/importer"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func loadPayload(keyName string) (*vault.Payload, error) {
+	_, encValue, err := store.GetKey(encrypt.KeyID(keyName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	value, err := encrypt.DecryptValue(keyName, encValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+
+	payload, err := vault.PayloadUnmarshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	return payload, nil
+}
+
+// savePayload stores the payload and records the modification time.
+func savePayload(keyName string, payload *vault.Payload) error {
+	payload.Touch(time.Now().UTC())
+
+	return storePayload(keyName, payload)
+}
+
+// storePayload stores the payload as it is, keeping its timestamps.
+func storePayload(keyName string, payload *vault.Payload) error {
+	payloadEncoded, err := payload.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	encKeyName, err := encrypt.EncryptKey(keyName)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key name: %w", err)
+	}
+
+	encValue, err := encrypt.EncryptValue(keyName, payloadEncoded)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt value: %w", err)
+	}
+
+	if err := store.SetKey(encrypt.KeyID(keyName), encKeyName, encValue); err != nil {
+		return fmt.Errorf("failed to store key: %w", err)
+	}
+
+	return nil
+}
+
+// applyFields sets "name=value" field definitions on the payload.
+func applyFields(payload *vault.Payload, fields []string) error {
+	for _, field := range fields {
+		name, value, err := vault.ParseField(field)
+		if err != nil {
+			return err
+		}
+
+		if err := payload.SetField(name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// entryContent formats the payload for multi-line editing the way pass
+// stores entries: the password on the first line, "name: value" fields, the
+// otpauth:// URI and the notes.
+func entryContent(keyName string, payload *vault.Payload) string {
+	return string(exporter.PassContent(exporter.Entry{Name: keyName, Payload: payload}))
+}
+
+// applyEntryContent replaces the password, fields, OTP and notes of the
+// payload with multi-line content in the entryContent format. Passkey and
+// GPG sections, timestamps and expiry are kept.
+func applyEntryContent(payload *vault.Payload, content string) error {
+	parsed, err := importer.ParsePass(content)
+	if err != nil {
+		return err
+	}
+
+	payload.Data = parsed.Data
+	payload.Username = parsed.Username
+	payload.URL = parsed.URL
+	payload.Notes = parsed.Notes
+	payload.Fields = parsed.Fields
+	payload.OTP = parsed.OTP
+
+	return nil
+}
@@ -15,16 +15,21 @@ import (
 const (
 	mlkemCiphertextSize = 1088
 	nonceSize           = 12
+	idKeySize           = 32
 )
 
 type Encryptor struct {
 	publicKey  *mlkem768.PublicKey
 	privateKey *mlkem768.PrivateKey
+	idKey      []byte
 }
 
 type Keys struct {
-	PublicKey  string `json:"pub"`
-	PrivateKey string `json:"priv"`
+	PublicKey        string     `json:"pub"`
+	PrivateKey       string     `json:"priv,omitempty"`
+	IDKey            string     `json:"idk,omitempty"`
+	SealedPrivateKey string     `json:"sealed_priv,omitempty"`
+	KDF              *KDFParams `json:"kdf,omitempty"`
 }
 
 func NewEncryptor(keys *Keys) (*Encryptor, error) {
@@ -32,9 +37,13 @@ func NewEncryptor(keys *Keys) (*Encryptor, error) {
 		return nil, errors.New("keys are required")
 	}
 
-	pubBytes, err := base64.StdEncoding.DecodeString(keys.PublicKey)
+	if keys.PrivateKey == "" && keys.IsSealed() {
+		return nil, errors.New("private key is sealed, unseal it first")
+	}
+
+	enc, err := NewPublicEncryptor(keys)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode public key: %w", err)
+		return nil, err
 	}
 
 	privBytes, err := base64.StdEncoding.DecodeString(keys.PrivateKey)
@@ -42,19 +51,49 @@ func NewEncryptor(keys *Keys) (*Encryptor, error) {
 		return nil, fmt.Errorf("failed to decode private key: %w", err)
 	}
 
+	privateKey, err := mlkem768.Scheme().UnmarshalBinaryPrivateKey(privBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private key: %w", err)
+	}
+
+	enc.privateKey = privateKey.(*mlkem768.PrivateKey)
+
+	return enc, nil
+}
+
+// NewPublicEncryptor creates an encryptor that can derive key IDs and
+// encrypt, but not decrypt, without access to the private key.
+func NewPublicEncryptor(keys *Keys) (*Encryptor, error) {
+	if keys == nil {
+		return nil, errors.New("keys are required")
+	}
+
+	pubBytes, err := base64.StdEncoding.DecodeString(keys.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+
 	publicKey, err := mlkem768.Scheme().UnmarshalBinaryPublicKey(pubBytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal public key: %w", err)
 	}
 
-	privateKey, err := mlkem768.Scheme().UnmarshalBinaryPrivateKey(privBytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal private key: %w", err)
+	var idKey []byte
+
+	if keys.IDKey != "" {
+		idKey, err = base64.StdEncoding.DecodeString(keys.IDKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode id key: %w", err)
+		}
+
+		if len(idKey) != idKeySize {
+			return nil, fmt.Errorf("invalid id key size: %d", len(idKey))
+		}
 	}
 
 	return &Encryptor{
-		publicKey:  publicKey.(*mlkem768.PublicKey),
-		privateKey: privateKey.(*mlkem768.PrivateKey),
+		publicKey: publicKey.(*mlkem768.PublicKey),
+		idKey:     idKey,
 	}, nil
 }
 
@@ -74,13 +113,46 @@ func GenerateKeys() (*Keys, error) {
 		return nil, fmt.Errorf("failed to marshal private key: %w", err)
 	}
 
+	idKey, err := GenerateIDKey()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Keys{
 		PublicKey:  base64.StdEncoding.EncodeToString(pubBytes),
 		PrivateKey: base64.StdEncoding.EncodeToString(privBytes),
+		IDKey:      idKey,
 	}, nil
 }
 
+func GenerateIDKey() (string, error) {
+	idKey := make([]byte, idKeySize)
+	if _, err := rand.Read(idKey); err != nil {
+		return "", fmt.Errorf("failed to generate id key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(idKey), nil
+}
+
+// HasIDKey reports whether key IDs are derived with the vault secret
+// rather than the legacy unkeyed hash.
+func (e *Encryptor) HasIDKey() bool {
+	return e.idKey != nil
+}
+
 func (e *Encryptor) KeyID(keyName string) []byte {
+	if e.idKey == nil {
+		return LegacyKeyID(keyName)
+	}
+
+	mac, _ := blake2b.New256(e.idKey)
+	mac.Write([]byte(keyName))
+
+	return mac.Sum(nil)
+}
+
+// LegacyKeyID is the unkeyed key ID used by vaults created without an id key.
+func LegacyKeyID(keyName string) []byte {
 	hash := blake2b.Sum256([]byte(keyName))
 	return hash[:]
 }
@@ -158,6 +230,10 @@ func (e *Encryptor) encrypt(plaintext, aad []byte) ([]byte, error) {
 }
 
 func (e *Encryptor) decrypt(data, aad []byte) ([]byte, error) {
+	if e.privateKey == nil {
+		return nil, errors.New("private key is not available")
+	}
+
 	minSize := mlkemCiphertextSize + nonceSize + 16
 	if len(data) < minSize {
 		return nil, errors.New("ciphertext too short")
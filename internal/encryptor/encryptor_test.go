@@ -152,6 +152,38 @@ func TestEncryptor_CrossEncryptorIsolation(t *testing.T) {
 	})
 }
 
+func TestNewPublicEncryptor(t *testing.T) {
+	keys, err := GenerateKeys()
+	require.NoError(t, err)
+
+	enc, err := NewEncryptor(keys)
+	require.NoError(t, err)
+
+	pubEnc, err := NewPublicEncryptor(&Keys{PublicKey: keys.PublicKey, IDKey: keys.IDKey})
+	require.NoError(t, err)
+
+	t.Run("same key IDs", func(t *testing.T) {
+		assert.Equal(t, enc.KeyID("/test/key"), pubEnc.KeyID("/test/key"))
+	})
+
+	t.Run("encrypts for the private key", func(t *testing.T) {
+		encrypted, err := pubEnc.EncryptValue("/test/key", []byte("secret"))
+		require.NoError(t, err)
+
+		decrypted, err := enc.DecryptValue("/test/key", encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret"), decrypted)
+	})
+
+	t.Run("cannot decrypt", func(t *testing.T) {
+		encrypted, err := enc.EncryptKey("/test/key")
+		require.NoError(t, err)
+
+		_, err = pubEnc.DecryptKey(encrypted)
+		assert.Error(t, err)
+	})
+}
+
 func TestEncryptor_KeyID(t *testing.T) {
 	keys, err := GenerateKeys()
 	require.NoError(t, err)
@@ -178,7 +210,7 @@ func TestEncryptor_KeyID(t *testing.T) {
 		assert.NotEqual(t, id1, id2)
 	})
 
-	t.Run("different encryptors produce same ID for same key name", func(t *testing.T) {
+	t.Run("different encryptors produce different IDs for same key name", func(t *testing.T) {
 		keys2, err := GenerateKeys()
 		require.NoError(t, err)
 		enc2, err := NewEncryptor(keys2)
@@ -187,6 +219,36 @@ func TestEncryptor_KeyID(t *testing.T) {
 		keyName := "/same/key"
 		id1 := enc.KeyID(keyName)
 		id2 := enc2.KeyID(keyName)
-		assert.Equal(t, id1, id2)
+		assert.NotEqual(t, id1, id2)
+	})
+
+	t.Run("not the unkeyed hash", func(t *testing.T) {
+		keyName := "/social/github.com/alice"
+		assert.True(t, enc.HasIDKey())
+		assert.NotEqual(t, LegacyKeyID(keyName), enc.KeyID(keyName))
+	})
+
+	t.Run("legacy keys without id key", func(t *testing.T) {
+		legacyKeys, err := GenerateKeys()
+		require.NoError(t, err)
+
+		legacyKeys.IDKey = ""
+
+		legacyEnc, err := NewEncryptor(legacyKeys)
+		require.NoError(t, err)
+
+		keyName := "/same/key"
+		assert.False(t, legacyEnc.HasIDKey())
+		assert.Equal(t, LegacyKeyID(keyName), legacyEnc.KeyID(keyName))
+	})
+
+	t.Run("invalid id key", func(t *testing.T) {
+		badKeys, err := GenerateKeys()
+		require.NoError(t, err)
+
+		badKeys.IDKey = "c2hvcnQ="
+
+		_, err = NewEncryptor(badKeys)
+		assert.Error(t, err)
 	})
 }
@@ -0,0 +1,183 @@
+package encryptor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	KDFArgon2id = "argon2id"
+
+	DefaultKDFTime    = 3
+	DefaultKDFMemory  = 64 * 1024
+	DefaultKDFThreads = 4
+
+	kdfSaltSize = 16
+	kdfKeySize  = 32
+)
+
+var ErrInvalidPassphrase = errors.New("invalid passphrase")
+
+type sealedSecrets struct {
+	PrivateKey string `json:"priv"`
+	IDKey      string `json:"idk,omitempty"`
+}
+
+type KDFParams struct {
+	Algorithm string `json:"alg"`
+	Salt      string `json:"salt"`
+	Time      uint32 `json:"t"`
+	Memory    uint32 `json:"m"`
+	Threads   uint8  `json:"p"`
+}
+
+func NewKDFParams() (*KDFParams, error) {
+	salt := make([]byte, kdfSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	return &KDFParams{
+		Algorithm: KDFArgon2id,
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+		Time:      DefaultKDFTime,
+		Memory:    DefaultKDFMemory,
+		Threads:   DefaultKDFThreads,
+	}, nil
+}
+
+func (p *KDFParams) DeriveKey(passphrase []byte) ([]byte, error) {
+	if p.Algorithm != KDFArgon2id {
+		return nil, fmt.Errorf("unsupported kdf: %s", p.Algorithm)
+	}
+
+	if p.Time == 0 || p.Memory == 0 || p.Threads == 0 {
+		return nil, errors.New("invalid kdf parameters")
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(p.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode salt: %w", err)
+	}
+
+	if len(salt) < kdfSaltSize {
+		return nil, errors.New("kdf salt too short")
+	}
+
+	return argon2.IDKey(passphrase, salt, p.Time, p.Memory, p.Threads, kdfKeySize), nil
+}
+
+// IsSealed reports whether the private key is wrapped with a passphrase.
+func (k *Keys) IsSealed() bool {
+	return k.SealedPrivateKey != ""
+}
+
+// Seal wraps the private key with a key derived from the passphrase and
+// removes the plaintext private key and id key.
+func (k *Keys) Seal(passphrase []byte) error {
+	if k.IsSealed() {
+		return errors.New("keys are already sealed")
+	}
+
+	if len(passphrase) == 0 {
+		return errors.New("empty passphrase")
+	}
+
+	if k.PrivateKey == "" {
+		return errors.New("private key is required")
+	}
+
+	secrets, err := json.Marshal(sealedSecrets{
+		PrivateKey: k.PrivateKey,
+		IDKey:      k.IDKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	kdf, err := NewKDFParams()
+	if err != nil {
+		return err
+	}
+
+	aead, err := k.sealCipher(kdf, passphrase)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, secrets, []byte(k.PublicKey))
+
+	k.KDF = kdf
+	k.SealedPrivateKey = base64.StdEncoding.EncodeToString(sealed)
+	k.PrivateKey = ""
+	k.IDKey = ""
+
+	return nil
+}
+
+// Unseal returns a copy of the keys with the plaintext secrets restored.
+func (k *Keys) Unseal(passphrase []byte) (*Keys, error) {
+	if !k.IsSealed() {
+		return nil, errors.New("keys are not sealed")
+	}
+
+	if k.KDF == nil {
+		return nil, errors.New("missing kdf parameters")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(k.SealedPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sealed private key: %w", err)
+	}
+
+	if len(sealed) < nonceSize {
+		return nil, errors.New("sealed private key too short")
+	}
+
+	aead, err := k.sealCipher(k.KDF, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(k.PublicKey))
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	var secrets sealedSecrets
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal secrets: %w", err)
+	}
+
+	return &Keys{
+		PublicKey:  k.PublicKey,
+		PrivateKey: secrets.PrivateKey,
+		IDKey:      secrets.IDKey,
+	}, nil
+}
+
+func (k *Keys) sealCipher(kdf *KDFParams, passphrase []byte) (cipher.AEAD, error) {
+	key, err := kdf.DeriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
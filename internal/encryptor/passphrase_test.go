@@ -0,0 +1,166 @@
+package encryptor
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKDFParams_DeriveKey(t *testing.T) {
+	t.Run("deterministic", func(t *testing.T) {
+		kdf, err := NewKDFParams()
+		require.NoError(t, err)
+
+		key1, err := kdf.DeriveKey([]byte("secret"))
+		require.NoError(t, err)
+		assert.Len(t, key1, kdfKeySize)
+
+		key2, err := kdf.DeriveKey([]byte("secret"))
+		require.NoError(t, err)
+		assert.Equal(t, key1, key2)
+
+		key3, err := kdf.DeriveKey([]byte("other"))
+		require.NoError(t, err)
+		assert.NotEqual(t, key1, key3)
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		kdf, err := NewKDFParams()
+		require.NoError(t, err)
+
+		kdf.Algorithm = "scrypt"
+
+		_, err = kdf.DeriveKey([]byte("secret"))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		kdf, err := NewKDFParams()
+		require.NoError(t, err)
+
+		kdf.Memory = 0
+
+		_, err = kdf.DeriveKey([]byte("secret"))
+		assert.Error(t, err)
+	})
+
+	t.Run("short salt", func(t *testing.T) {
+		kdf, err := NewKDFParams()
+		require.NoError(t, err)
+
+		kdf.Salt = "c2FsdA=="
+
+		_, err = kdf.DeriveKey([]byte("secret"))
+		assert.Error(t, err)
+	})
+}
+
+func TestKeys_SealUnseal(t *testing.T) {
+	t.Run("roundtrip", func(t *testing.T) {
+		keys, err := GenerateKeys()
+		require.NoError(t, err)
+
+		original := keys.PrivateKey
+		originalIDKey := keys.IDKey
+
+		require.NoError(t, keys.Seal([]byte("passphrase")))
+		assert.True(t, keys.IsSealed())
+		assert.Empty(t, keys.PrivateKey)
+		assert.Empty(t, keys.IDKey)
+		assert.NotNil(t, keys.KDF)
+
+		unsealed, err := keys.Unseal([]byte("passphrase"))
+		require.NoError(t, err)
+		assert.Equal(t, original, unsealed.PrivateKey)
+		assert.Equal(t, originalIDKey, unsealed.IDKey)
+		assert.False(t, unsealed.IsSealed())
+
+		enc, err := NewEncryptor(unsealed)
+		require.NoError(t, err)
+
+		ct, err := enc.EncryptKey("/test")
+		require.NoError(t, err)
+
+		pt, err := enc.DecryptKey(ct)
+		require.NoError(t, err)
+		assert.Equal(t, "/test", pt)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		keys, err := GenerateKeys()
+		require.NoError(t, err)
+
+		require.NoError(t, keys.Seal([]byte("passphrase")))
+
+		_, err = keys.Unseal([]byte("wrong"))
+		assert.ErrorIs(t, err, ErrInvalidPassphrase)
+	})
+
+	t.Run("tampered public key", func(t *testing.T) {
+		keys, err := GenerateKeys()
+		require.NoError(t, err)
+
+		require.NoError(t, keys.Seal([]byte("passphrase")))
+
+		other, err := GenerateKeys()
+		require.NoError(t, err)
+
+		keys.PublicKey = other.PublicKey
+
+		_, err = keys.Unseal([]byte("passphrase"))
+		assert.ErrorIs(t, err, ErrInvalidPassphrase)
+	})
+
+	t.Run("empty passphrase", func(t *testing.T) {
+		keys, err := GenerateKeys()
+		require.NoError(t, err)
+
+		assert.Error(t, keys.Seal(nil))
+		assert.False(t, keys.IsSealed())
+	})
+
+	t.Run("seal twice", func(t *testing.T) {
+		keys, err := GenerateKeys()
+		require.NoError(t, err)
+
+		require.NoError(t, keys.Seal([]byte("passphrase")))
+		assert.Error(t, keys.Seal([]byte("passphrase")))
+	})
+
+	t.Run("unseal plain keys", func(t *testing.T) {
+		keys, err := GenerateKeys()
+		require.NoError(t, err)
+
+		_, err = keys.Unseal([]byte("passphrase"))
+		assert.Error(t, err)
+	})
+
+	t.Run("sealed keys rejected by encryptor", func(t *testing.T) {
+		keys, err := GenerateKeys()
+		require.NoError(t, err)
+
+		require.NoError(t, keys.Seal([]byte("passphrase")))
+
+		_, err = NewEncryptor(keys)
+		assert.Error(t, err)
+	})
+
+	t.Run("json roundtrip", func(t *testing.T) {
+		keys, err := GenerateKeys()
+		require.NoError(t, err)
+
+		require.NoError(t, keys.Seal([]byte("passphrase")))
+
+		data, err := json.Marshal(keys)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), `"priv"`)
+
+		var decoded Keys
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		_, err = decoded.Unseal([]byte("passphrase"))
+		assert.NoError(t, err)
+	})
+}
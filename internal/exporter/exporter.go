@@ -0,0 +1,244 @@
+// Package exporter writes decrypted entries in plaintext formats for use in
+// other password managers.
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/otp"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+// SchemaVersion is the version of the JSON export schema.
+const SchemaVersion = 1
+
+type Entry struct {
+	Name    string
+	Payload *vault.Payload
+}
+
+// Document is the JSON export schema.
+type Document struct {
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exported_at"`
+	Entries    []JSONEntry `json:"entries"`
+}
+
+type JSONEntry struct {
+	Name       string            `json:"name"`
+	Password   string            `json:"password"`
+	Username   string            `json:"username,omitempty"`
+	URL        string            `json:"url,omitempty"`
+	Notes      string            `json:"notes,omitempty"`
+	OTP        string            `json:"otp,omitempty"`
+	Fields     map[string]string `json:"fields,omitempty"`
+	Passkey    *Passkey          `json:"passkey,omitempty"`
+	GPGKey     *GPGKey           `json:"gpg_key,omitempty"`
+	CreatedAt  time.Time         `json:"created_at,omitzero"`
+	ModifiedAt time.Time         `json:"modified_at,omitzero"`
+	ExpiresAt  time.Time         `json:"expires_at,omitzero"`
+	MaxAgeDays int               `json:"max_age_days,omitempty"`
+}
+
+type Passkey struct {
+	CredentialID string    `json:"credential_id"`
+	RPID         string    `json:"rp_id"`
+	UserID       string    `json:"user_id"`
+	UserName     string    `json:"user_name"`
+	SignCount    uint32    `json:"sign_count"`
+	PrivateKey   string    `json:"private_key"`
+	PublicKey    string    `json:"public_key"`
+	CreatedAt    time.Time `json:"created_at,omitzero"`
+}
+
+type GPGKey struct {
+	KeyID       string `json:"key_id"`
+	Fingerprint string `json:"fingerprint"`
+	UserID      string `json:"user_id"`
+	PublicKey   string `json:"public_key"`
+	PrivateKey  string `json:"private_key,omitempty"`
+}
+
+// OTPURI returns the otpauth:// URI of an entry, or an empty string.
+func OTPURI(entry Entry) string {
+	if entry.Payload.OTP == nil {
+		return ""
+	}
+
+	o := entry.Payload.OTP
+
+	return otp.FormatTOTPURI(entry.Name, o.Secret, o.Digits, o.Period)
+}
+
+// WriteJSON writes the entries as a Document.
+func WriteJSON(w io.Writer, entries []Entry, now time.Time) error {
+	doc := Document{
+		Version:    SchemaVersion,
+		ExportedAt: now,
+		Entries:    make([]JSONEntry, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		payload := entry.Payload
+
+		jsonEntry := JSONEntry{
+			Name:       entry.Name,
+			Password:   payload.Data,
+			Username:   payload.Username,
+			URL:        payload.URL,
+			Notes:      payload.Notes,
+			OTP:        OTPURI(entry),
+			Fields:     payload.Fields,
+			CreatedAt:  payload.CreatedAt,
+			ModifiedAt: payload.ModifiedAt,
+			ExpiresAt:  payload.ExpiresAt,
+			MaxAgeDays: payload.MaxAgeDays,
+		}
+
+		if pk := payload.Passkey; pk != nil {
+			jsonEntry.Passkey = &Passkey{
+				CredentialID: pk.ID,
+				RPID:         pk.RPID,
+				UserID:       pk.UserID,
+				UserName:     pk.UserName,
+				SignCount:    pk.SignCount,
+				PrivateKey:   pk.PrivateKeyPEM,
+				PublicKey:    pk.PublicKeyPEM,
+				CreatedAt:    pk.CreatedAt,
+			}
+		}
+
+		if key := payload.GPGKey; key != nil {
+			jsonEntry.GPGKey = &GPGKey{
+				KeyID:       key.KeyID,
+				Fingerprint: key.Fingerprint,
+				UserID:      key.UserID,
+				PublicKey:   key.PublicKey,
+				PrivateKey:  key.PrivateKey,
+			}
+		}
+
+		doc.Entries = append(doc.Entries, jsonEntry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+
+	return nil
+}
+
+// csvColumns are the fixed CSV columns, custom fields follow as
+// "field:<name>" columns.
+var csvColumns = []string{"name", "password", "username", "url", "notes", "otp", "created_at", "modified_at", "expires_at"}
+
+// WriteCSV writes one row per entry. Passkeys and GPG keys are not included.
+func WriteCSV(w io.Writer, entries []Entry) error {
+	fieldSet := make(map[string]bool)
+
+	for _, entry := range entries {
+		for name := range entry.Payload.Fields {
+			fieldSet[name] = true
+		}
+	}
+
+	fieldNames := slices.Sorted(maps.Keys(fieldSet))
+
+	header := slices.Clone(csvColumns)
+	for _, name := range fieldNames {
+		header = append(header, "field:"+name)
+	}
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	for _, entry := range entries {
+		payload := entry.Payload
+
+		row := []string{
+			entry.Name,
+			payload.Data,
+			payload.Username,
+			payload.URL,
+			payload.Notes,
+			OTPURI(entry),
+			formatTime(payload.CreatedAt),
+			formatTime(payload.ModifiedAt),
+			formatTime(payload.ExpiresAt),
+		}
+
+		for _, name := range fieldNames {
+			row = append(row, payload.Fields[name])
+		}
+
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// PassContent formats an entry the way pass and its extensions read it: the
+// password on the first line, followed by "name: value" lines, the otpauth://
+// URI and the notes.
+func PassContent(entry Entry) []byte {
+	payload := entry.Payload
+
+	var b strings.Builder
+
+	b.WriteString(payload.Data)
+	b.WriteByte('\n')
+
+	if payload.Username != "" {
+		fmt.Fprintf(&b, "login: %s\n", payload.Username)
+	}
+
+	if payload.URL != "" {
+		fmt.Fprintf(&b, "url: %s\n", payload.URL)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(payload.Fields)) {
+		fmt.Fprintf(&b, "%s: %s\n", name, payload.Fields[name])
+	}
+
+	if uri := OTPURI(entry); uri != "" {
+		b.WriteString(uri)
+		b.WriteByte('\n')
+	}
+
+	if payload.Notes != "" {
+		b.WriteString(payload.Notes)
+		b.WriteByte('\n')
+	}
+
+	return []byte(b.String())
+}
@@ -0,0 +1,110 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/importer"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+var created = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testEntries() []Entry {
+	return []Entry{
+		{
+			Name: "/mail",
+			Payload: &vault.Payload{
+				Data:      "s3cret",
+				Username:  "alice",
+				URL:       "https://mail.example.com",
+				Notes:     "line 1\nline 2",
+				Fields:    map[string]string{"pin": "1234"},
+				OTP:       &vault.OTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 8},
+				CreatedAt: created,
+			},
+		},
+		{
+			Name: "/web/passkey",
+			Payload: &vault.Payload{
+				Data:    "Passkey for example.com",
+				Fields:  map[string]string{"team": "ops"},
+				Passkey: &vault.Passkey{ID: "cred", RPID: "example.com", PrivateKeyPEM: "PRIVATE"},
+			},
+		},
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, WriteJSON(&buf, testEntries(), created))
+
+	var doc Document
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	assert.Equal(t, SchemaVersion, doc.Version)
+	assert.Equal(t, created, doc.ExportedAt)
+	require.Len(t, doc.Entries, 2)
+
+	mail := doc.Entries[0]
+	assert.Equal(t, "/mail", mail.Name)
+	assert.Equal(t, "s3cret", mail.Password)
+	assert.Equal(t, "alice", mail.Username)
+	assert.Equal(t, "otpauth://totp//mail?secret=JBSWY3DPEHPK3PXP&digits=8&period=30", mail.OTP)
+	assert.Equal(t, map[string]string{"pin": "1234"}, mail.Fields)
+	assert.Equal(t, created, mail.CreatedAt)
+	assert.Nil(t, mail.Passkey)
+
+	require.NotNil(t, doc.Entries[1].Passkey)
+	assert.Equal(t, "cred", doc.Entries[1].Passkey.CredentialID)
+	assert.Equal(t, "PRIVATE", doc.Entries[1].Passkey.PrivateKey)
+	assert.NotContains(t, buf.String(), `"modified_at"`)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, WriteCSV(&buf, testEntries()))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, []string{"name", "password", "username", "url", "notes", "otp", "created_at", "modified_at", "expires_at", "field:pin", "field:team"}, rows[0])
+	assert.Equal(t, []string{
+		"/mail", "s3cret", "alice", "https://mail.example.com", "line 1\nline 2",
+		"otpauth://totp//mail?secret=JBSWY3DPEHPK3PXP&digits=8&period=30",
+		"2024-01-02T03:04:05Z", "", "", "1234", "",
+	}, rows[1])
+	assert.Equal(t, "ops", rows[2][10])
+}
+
+func TestPassContent(t *testing.T) {
+	entry := testEntries()[0]
+
+	content := PassContent(entry)
+
+	assert.Equal(t, "s3cret\n"+
+		"login: alice\n"+
+		"url: https://mail.example.com\n"+
+		"pin: 1234\n"+
+		"otpauth://totp//mail?secret=JBSWY3DPEHPK3PXP&digits=8&period=30\n"+
+		"line 1\nline 2\n", string(content))
+
+	// The pass importer reads the entry back.
+	payload, err := importer.ParsePass(string(content))
+	require.NoError(t, err)
+
+	assert.Equal(t, "s3cret", payload.Data)
+	assert.Equal(t, "alice", payload.Username)
+	assert.Equal(t, "https://mail.example.com", payload.URL)
+	assert.Equal(t, map[string]string{"pin": "1234"}, payload.Fields)
+	assert.Equal(t, &vault.OTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 8, Period: 30}, payload.OTP)
+	assert.Equal(t, "line 1\nline 2", payload.Notes)
+}
@@ -0,0 +1,90 @@
+package exporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Encrypter encrypts the content of a pass entry.
+type Encrypter func(plaintext []byte) ([]byte, error)
+
+// WritePass writes the entries as a pass password store into dir, which must
+// not exist or be empty. gpgID is written to .gpg-id as the recipient of the
+// store.
+func WritePass(dir string, entries []Entry, encrypt Encrypter, gpgID string) error {
+	// Vault key names may contain "." and ".." segments, which cannot be
+	// used as file paths.
+	for _, entry := range entries {
+		for segment := range strings.SplitSeq(strings.TrimPrefix(entry.Name, "/"), "/") {
+			if segment == "." || segment == ".." {
+				return fmt.Errorf("key name cannot be used as a file path: %s", entry.Name)
+			}
+		}
+	}
+
+	existing, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	if len(existing) > 0 {
+		return fmt.Errorf("directory is not empty: %s", dir)
+	}
+
+	// Encrypt everything first, a failing encryption leaves nothing behind.
+	ciphertexts := make([][]byte, len(entries))
+
+	for i, entry := range entries {
+		ciphertext, err := encrypt(PassContent(entry))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", entry.Name, err)
+		}
+
+		ciphertexts[i] = ciphertext
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := writePassFiles(dir, entries, ciphertexts, gpgID); err != nil {
+		// dir was empty or missing, so a partial store can be removed.
+		removeContents(dir)
+		return err
+	}
+
+	return nil
+}
+
+func writePassFiles(dir string, entries []Entry, ciphertexts [][]byte, gpgID string) error {
+	if err := os.WriteFile(filepath.Join(dir, ".gpg-id"), []byte(gpgID+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write .gpg-id: %w", err)
+	}
+
+	for i, entry := range entries {
+		path := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(entry.Name, "/"))+".gpg")
+
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+
+		if err := os.WriteFile(path, ciphertexts[i], 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func removeContents(dir string) {
+	children, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, child := range children {
+		os.RemoveAll(filepath.Join(dir, child.Name()))
+	}
+}
@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePass(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+
+	identity := func(plaintext []byte) ([]byte, error) {
+		return plaintext, nil
+	}
+
+	require.NoError(t, WritePass(dir, testEntries(), identity, "ABCDEF0123456789"))
+
+	gpgID, err := os.ReadFile(filepath.Join(dir, ".gpg-id"))
+	require.NoError(t, err)
+	assert.Equal(t, "ABCDEF0123456789\n", string(gpgID))
+
+	content, err := os.ReadFile(filepath.Join(dir, "mail.gpg"))
+	require.NoError(t, err)
+	assert.Equal(t, PassContent(testEntries()[0]), content)
+
+	info, err := os.Stat(filepath.Join(dir, "web", "passkey.gpg"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	err = WritePass(dir, testEntries(), identity, "ABCDEF0123456789")
+	assert.ErrorContains(t, err, "not empty")
+
+	escaping := []Entry{{Name: "/web/../../etc", Payload: testEntries()[0].Payload}}
+
+	err = WritePass(filepath.Join(t.TempDir(), "other"), escaping, identity, "ABCDEF0123456789")
+	assert.ErrorContains(t, err, "cannot be used as a file path")
+
+	t.Run("encryption failure", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "other")
+
+		calls := 0
+		failing := func(plaintext []byte) ([]byte, error) {
+			calls++
+			if calls > 1 {
+				return nil, errors.New("no key")
+			}
+
+			return plaintext, nil
+		}
+
+		err := WritePass(dir, testEntries(), failing, "ABCDEF0123456789")
+		assert.ErrorContains(t, err, "no key")
+		assert.NoDirExists(t, dir)
+
+		require.NoError(t, WritePass(dir, testEntries(), identity, "ABCDEF0123456789"))
+	})
+
+	t.Run("write failure", func(t *testing.T) {
+		dir := t.TempDir()
+
+		// The file of the first entry is in the way of the directory of the
+		// second one.
+		conflicting := []Entry{
+			{Name: "/mail", Payload: testEntries()[0].Payload},
+			{Name: "/mail.gpg/work", Payload: testEntries()[0].Payload},
+		}
+
+		err := WritePass(dir, conflicting, identity, "ABCDEF0123456789")
+		assert.ErrorContains(t, err, "failed to create directory")
+
+		children, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, children)
+
+		require.NoError(t, WritePass(dir, testEntries(), identity, "ABCDEF0123456789"))
+	})
+}
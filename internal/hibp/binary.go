@@ -0,0 +1,169 @@
+package hibp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// The binary index starts with a magic line and a fanout table with the
+// cumulative number of records for every 16-bit hash prefix, followed by the
+// sorted records of a hash and a big-endian uint32 count.
+const (
+	indexMagic      = "GPHIBP1\n"
+	fanoutSize      = 1 << 16
+	headerSize      = len(indexMagic) + fanoutSize*8
+	countSize       = 4
+	recordSize      = HashSize + countSize
+	writeBufferSize = 1 << 20
+)
+
+var ErrNotSorted = errors.New("hash file is not sorted by hash")
+
+type binaryIndex struct {
+	file   *os.File
+	fanout [fanoutSize]uint64
+}
+
+func openBinary(file *os.File) (*binaryIndex, error) {
+	index := &binaryIndex{file: file}
+
+	fanout := make([]byte, fanoutSize*8)
+	if _, err := file.ReadAt(fanout, int64(len(indexMagic))); err != nil {
+		return nil, fmt.Errorf("failed to read index header: %w", err)
+	}
+
+	for i := range index.fanout {
+		index.fanout[i] = binary.BigEndian.Uint64(fanout[i*8:])
+	}
+
+	return index, nil
+}
+
+func (b *binaryIndex) Close() error {
+	return b.file.Close()
+}
+
+func (b *binaryIndex) Lookup(hash Hash) (int, error) {
+	prefix := int(binary.BigEndian.Uint16(hash[:2]))
+
+	var lo uint64
+	if prefix > 0 {
+		lo = b.fanout[prefix-1]
+	}
+
+	hi := b.fanout[prefix]
+	record := make([]byte, recordSize)
+
+	var readErr error
+
+	i := sort.Search(int(hi-lo), func(i int) bool {
+		if readErr != nil {
+			return true
+		}
+
+		if _, err := b.file.ReadAt(record, int64(headerSize)+int64(lo+uint64(i))*recordSize); err != nil {
+			readErr = err
+			return true
+		}
+
+		return bytes.Compare(record[:HashSize], hash[:]) >= 0
+	})
+
+	if readErr != nil {
+		return 0, fmt.Errorf("failed to read index: %w", readErr)
+	}
+
+	if uint64(i) == hi-lo {
+		return 0, nil
+	}
+
+	if _, err := b.file.ReadAt(record, int64(headerSize)+int64(lo+uint64(i))*recordSize); err != nil {
+		return 0, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	if !bytes.Equal(record[:HashSize], hash[:]) {
+		return 0, nil
+	}
+
+	return int(binary.BigEndian.Uint32(record[HashSize:])), nil
+}
+
+// BuildIndex converts a text file sorted by hash into the binary index,
+// which is about half the size and needs fewer reads per lookup.
+func BuildIndex(src io.Reader, dst io.WriteSeeker) (int, error) {
+	if _, err := dst.Write(make([]byte, headerSize)); err != nil {
+		return 0, fmt.Errorf("failed to write index header: %w", err)
+	}
+
+	var (
+		fanout   [fanoutSize]uint64
+		previous *Hash
+		records  int
+	)
+
+	writer := bufio.NewWriterSize(dst, writeBufferSize)
+	scanner := bufio.NewScanner(src)
+	record := make([]byte, recordSize)
+
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		hash, count, err := parseLine(scanner.Bytes())
+		if err != nil {
+			return 0, err
+		}
+
+		if previous != nil && bytes.Compare(previous[:], hash[:]) >= 0 {
+			return 0, fmt.Errorf("%w: %X", ErrNotSorted, hash)
+		}
+
+		previous = &hash
+
+		copy(record, hash[:])
+		binary.BigEndian.PutUint32(record[HashSize:], uint32(min(uint64(count), math.MaxUint32)))
+
+		if _, err := writer.Write(record); err != nil {
+			return 0, fmt.Errorf("failed to write index: %w", err)
+		}
+
+		fanout[binary.BigEndian.Uint16(hash[:2])]++
+		records++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read hash file: %w", err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write index: %w", err)
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, indexMagic)
+
+	var total uint64
+
+	for i, count := range fanout {
+		total += count
+		binary.BigEndian.PutUint64(header[len(indexMagic)+i*8:], total)
+	}
+
+	if _, err := dst.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to write index header: %w", err)
+	}
+
+	if _, err := dst.Write(header); err != nil {
+		return 0, fmt.Errorf("failed to write index header: %w", err)
+	}
+
+	return records, nil
+}
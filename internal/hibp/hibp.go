@@ -0,0 +1,88 @@
+package hibp
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+const HashSize = sha1.Size
+
+var ErrInvalidLine = errors.New("invalid hash line")
+
+type Hash [HashSize]byte
+
+// Index looks up SHA-1 hashes in a local copy of the Have I Been Pwned
+// password list.
+type Index interface {
+	// Lookup returns how often the password was seen in breaches, or zero.
+	Lookup(hash Hash) (int, error)
+	Close() error
+}
+
+func HashPassword(password string) Hash {
+	return sha1.Sum([]byte(password)) //nolint:gosec
+}
+
+// Open detects the file format: a binary index built by BuildIndex or the
+// sorted text file in the "HASH:COUNT" format.
+func Open(path string) (Index, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hash file: %w", err)
+	}
+
+	header := make([]byte, len(indexMagic))
+
+	if _, err := io.ReadFull(file, header); err == nil && bytes.Equal(header, []byte(indexMagic)) {
+		index, err := openBinary(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		return index, nil
+	}
+
+	index, err := openText(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// parseLine parses a "HASH:COUNT" line, the count is optional.
+func parseLine(line []byte) (Hash, int, error) {
+	var hash Hash
+
+	line = bytes.TrimRight(line, "\r\n")
+
+	hexHash, countText, hasCount := bytes.Cut(line, []byte(":"))
+	if len(hexHash) != hex.EncodedLen(HashSize) {
+		return hash, 0, fmt.Errorf("%w: %q", ErrInvalidLine, line)
+	}
+
+	if _, err := hex.Decode(hash[:], hexHash); err != nil {
+		return hash, 0, fmt.Errorf("%w: %q", ErrInvalidLine, line)
+	}
+
+	count := 1
+
+	if hasCount {
+		var err error
+
+		count, err = strconv.Atoi(string(bytes.TrimSpace(countText)))
+		if err != nil || count < 0 {
+			return hash, 0, fmt.Errorf("%w: %q", ErrInvalidLine, line)
+		}
+	}
+
+	return hash, count, nil
+}
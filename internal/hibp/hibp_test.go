@@ -0,0 +1,140 @@
+package hibp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHashFile(t *testing.T, count int) (string, map[Hash]int) {
+	t.Helper()
+
+	hashes := make(map[Hash]int, count)
+	lines := make([]string, 0, count)
+
+	for i := 0; i < count; i++ {
+		hash := HashPassword(fmt.Sprintf("password%d", i))
+		hashes[hash] = i + 1
+		lines = append(lines, fmt.Sprintf("%X:%d", hash, i+1))
+	}
+
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0600))
+
+	return path, hashes
+}
+
+func testLookups(t *testing.T, index Index, hashes map[Hash]int) {
+	t.Helper()
+
+	for hash, expected := range hashes {
+		count, err := index.Lookup(hash)
+		require.NoError(t, err)
+		require.Equal(t, expected, count, "%X", hash)
+	}
+
+	for i := 0; i < 200; i++ {
+		count, err := index.Lookup(HashPassword(fmt.Sprintf("not-breached-%d", i)))
+		require.NoError(t, err)
+		require.Zero(t, count)
+	}
+
+	// Hashes before the first and after the last line.
+	for _, hash := range []Hash{{}, {0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}} {
+		count, err := index.Lookup(hash)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	}
+}
+
+func TestTextIndex(t *testing.T) {
+	path, hashes := writeHashFile(t, 5000)
+
+	index, err := Open(path)
+	require.NoError(t, err)
+
+	defer index.Close()
+
+	assert.IsType(t, &textIndex{}, index)
+
+	testLookups(t, index, hashes)
+}
+
+func TestTextIndexWithoutCounts(t *testing.T) {
+	hash := HashPassword("password")
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.ToLower(fmt.Sprintf("%X\n", hash))), 0600))
+
+	index, err := Open(path)
+	require.NoError(t, err)
+
+	defer index.Close()
+
+	count, err := index.Lookup(hash)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestBinaryIndex(t *testing.T) {
+	path, hashes := writeHashFile(t, 5000)
+
+	src, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer src.Close()
+
+	indexPath := filepath.Join(t.TempDir(), "pwned.idx")
+
+	dst, err := os.Create(indexPath)
+	require.NoError(t, err)
+
+	records, err := BuildIndex(src, dst)
+	require.NoError(t, err)
+	require.NoError(t, dst.Close())
+
+	assert.Equal(t, 5000, records)
+
+	index, err := Open(indexPath)
+	require.NoError(t, err)
+
+	defer index.Close()
+
+	assert.IsType(t, &binaryIndex{}, index)
+
+	testLookups(t, index, hashes)
+}
+
+func TestBuildIndexNotSorted(t *testing.T) {
+	src := fmt.Sprintf("%X:1\n%X:1\n", Hash{2}, Hash{1})
+
+	dst, err := os.Create(filepath.Join(t.TempDir(), "pwned.idx"))
+	require.NoError(t, err)
+
+	defer dst.Close()
+
+	_, err = BuildIndex(bytes.NewBufferString(src), dst)
+	assert.ErrorIs(t, err, ErrNotSorted)
+}
+
+func TestParseLine(t *testing.T) {
+	hash, count, err := parseLine([]byte("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:52256179\r\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, HashPassword("password"), hash)
+	assert.Equal(t, 52256179, count)
+
+	for _, line := range []string{"", "5BAA61E4", "ZZAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:-1"} {
+		_, _, err := parseLine([]byte(line))
+		assert.ErrorIs(t, err, ErrInvalidLine, line)
+	}
+}
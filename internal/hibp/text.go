@@ -0,0 +1,135 @@
+package hibp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// scanThreshold is the range size below which the text index stops the
+	// binary search and reads lines one by one.
+	scanThreshold = 16 * 1024
+
+	maxLineLength = 128
+)
+
+// textIndex does a binary search over the byte offsets of a text file sorted
+// by hash, as downloaded from Have I Been Pwned.
+type textIndex struct {
+	file *os.File
+	size int64
+}
+
+func openText(file *os.File) (*textIndex, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat hash file: %w", err)
+	}
+
+	return &textIndex{
+		file: file,
+		size: info.Size(),
+	}, nil
+}
+
+func (t *textIndex) Close() error {
+	return t.file.Close()
+}
+
+func (t *textIndex) Lookup(hash Hash) (int, error) {
+	lo, hi := int64(0), t.size
+
+	// The matching line, if any, starts in [lo, hi].
+	for hi-lo > scanThreshold {
+		mid := lo + (hi-lo)/2
+
+		start, line, err := t.lineAfter(mid)
+		if err != nil {
+			return 0, err
+		}
+
+		// Only possible with lines longer than expected, the rest of the
+		// range is small enough to scan.
+		if line == nil || start >= hi {
+			break
+		}
+
+		lineHash, _, err := parseLine(line)
+		if err != nil {
+			return 0, err
+		}
+
+		if bytes.Compare(lineHash[:], hash[:]) < 0 {
+			lo = start
+		} else {
+			hi = start
+		}
+	}
+
+	return t.scan(lo, hi, hash)
+}
+
+// lineAfter returns the first line that starts at or after offset.
+func (t *textIndex) lineAfter(offset int64) (int64, []byte, error) {
+	if offset > 0 {
+		offset--
+	}
+
+	reader := bufio.NewReaderSize(io.NewSectionReader(t.file, offset, t.size-offset), 2*maxLineLength)
+
+	if offset > 0 {
+		skipped, err := reader.ReadSlice('\n')
+		if errors.Is(err, io.EOF) {
+			return t.size, nil, nil
+		} else if err != nil {
+			return 0, nil, fmt.Errorf("failed to read hash file: %w", err)
+		}
+
+		offset += int64(len(skipped))
+	}
+
+	line, err := reader.ReadSlice('\n')
+	if errors.Is(err, io.EOF) && len(line) == 0 {
+		return t.size, nil, nil
+	} else if err != nil && !errors.Is(err, io.EOF) {
+		return 0, nil, fmt.Errorf("failed to read hash file: %w", err)
+	}
+
+	return offset, line, nil
+}
+
+func (t *textIndex) scan(lo, hi int64, hash Hash) (int, error) {
+	reader := bufio.NewReader(io.NewSectionReader(t.file, lo, t.size-lo))
+	offset := lo
+
+	for offset <= hi {
+		line, err := reader.ReadSlice('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			lineHash, count, parseErr := parseLine(line)
+			if parseErr != nil {
+				return 0, parseErr
+			}
+
+			switch bytes.Compare(lineHash[:], hash[:]) {
+			case 0:
+				return count, nil
+			case 1:
+				return 0, nil
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return 0, nil
+		} else if err != nil {
+			return 0, fmt.Errorf("failed to read hash file: %w", err)
+		}
+
+		offset += int64(len(line))
+	}
+
+	return 0, nil
+}
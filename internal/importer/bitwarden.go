@@ -0,0 +1,277 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/passkey"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+// Bitwarden item types.
+const (
+	bitwardenLogin      = 1
+	bitwardenSecureNote = 2
+	bitwardenCard       = 3
+	bitwardenIdentity   = 4
+	bitwardenSSHKey     = 5
+)
+
+// Bitwarden custom field types.
+const (
+	bitwardenFieldBoolean = 2
+	bitwardenFieldLinked  = 3
+)
+
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Collections []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"collections"`
+	Items []bitwardenItem `json:"items"`
+}
+
+type bitwardenItem struct {
+	Type          int              `json:"type"`
+	Name          string           `json:"name"`
+	Notes         string           `json:"notes"`
+	FolderID      string           `json:"folderId"`
+	CollectionIDs []string         `json:"collectionIds"`
+	Fields        []bitwardenField `json:"fields"`
+	Login         *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+		Fido2Credentials []bitwardenPasskey `json:"fido2Credentials"`
+	} `json:"login"`
+	CreationDate time.Time  `json:"creationDate"`
+	DeletedDate  *time.Time `json:"deletedDate"`
+}
+
+type bitwardenField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  int    `json:"type"`
+}
+
+type bitwardenPasskey struct {
+	CredentialID string `json:"credentialId"`
+	KeyAlgorithm string `json:"keyAlgorithm"`
+	KeyCurve     string `json:"keyCurve"`
+	KeyValue     string `json:"keyValue"`
+	RPID         string `json:"rpId"`
+	UserHandle   string `json:"userHandle"`
+	UserName     string `json:"userName"`
+	Counter      string `json:"counter"`
+	CreationDate string `json:"creationDate"`
+}
+
+// ReadBitwarden reads an unencrypted Bitwarden JSON export. Folders, or
+// collections for organization exports, become key paths. Logins and secure
+// notes are imported, the first passkey of a login is stored with it and any
+// further passkeys as separate keys below it.
+func ReadBitwarden(r io.Reader) (*Result, error) {
+	var export bitwardenExport
+
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitwarden export: %w", err)
+	}
+
+	if export.Encrypted {
+		return nil, fmt.Errorf("encrypted Bitwarden exports are not supported, export as unencrypted JSON")
+	}
+
+	paths := make(map[string]string)
+
+	for _, folder := range export.Folders {
+		paths[folder.ID] = folder.Name
+	}
+
+	for _, collection := range export.Collections {
+		paths[collection.ID] = collection.Name
+	}
+
+	result := &Result{}
+
+	var items []item
+
+	for _, bwItem := range export.Items {
+		folderID := bwItem.FolderID
+		if folderID == "" && len(bwItem.CollectionIDs) > 0 {
+			folderID = bwItem.CollectionIDs[0]
+		}
+
+		title := strings.TrimSpace(bwItem.Name)
+		if title == "" {
+			title = "untitled"
+		}
+
+		source := "/" + strings.ReplaceAll(title, "/", "_")
+		if path := strings.Trim(paths[folderID], "/"); path != "" {
+			source = "/" + path + source
+		}
+
+		if bwItem.DeletedDate != nil {
+			result.skip(source, "item is in the trash")
+			continue
+		}
+
+		switch bwItem.Type {
+		case bitwardenLogin, bitwardenSecureNote:
+		case bitwardenCard:
+			result.skip(source, "cards are not supported")
+			continue
+		case bitwardenIdentity:
+			result.skip(source, "identities are not supported")
+			continue
+		case bitwardenSSHKey:
+			result.skip(source, "SSH keys are not supported")
+			continue
+		default:
+			result.skip(source, "unknown item type %d", bwItem.Type)
+			continue
+		}
+
+		payload := &vault.Payload{
+			Notes:     bwItem.Notes,
+			CreatedAt: bwItem.CreationDate,
+		}
+
+		var extra []field
+
+		var passkeys []*vault.Passkey
+
+		if login := bwItem.Login; login != nil {
+			payload.Data = login.Password
+			payload.Username = login.Username
+
+			for i, uri := range login.URIs {
+				if i == 0 {
+					payload.URL = uri.URI
+				} else {
+					extra = append(extra, field{name: fmt.Sprintf("url_%d", i+1), value: uri.URI})
+				}
+			}
+
+			if login.TOTP != "" {
+				otpData, err := parseTOTP(login.TOTP)
+				if err != nil {
+					extra = append(extra, field{name: "totp", value: login.TOTP})
+				} else {
+					payload.OTP = otpData
+				}
+			}
+
+			for i, credential := range login.Fido2Credentials {
+				pk, err := bitwardenPasskeyPayload(credential)
+				if err != nil {
+					result.skip(fmt.Sprintf("%s (passkey %d)", source, i+1), "%v", err)
+					continue
+				}
+
+				passkeys = append(passkeys, pk)
+			}
+		}
+
+		for _, f := range bwItem.Fields {
+			switch f.Type {
+			case bitwardenFieldLinked:
+				continue
+			case bitwardenFieldBoolean:
+				f.Value = strconv.FormatBool(f.Value == "true")
+			}
+
+			extra = append(extra, field{name: f.Name, value: f.Value})
+		}
+
+		addFields(payload, extra)
+
+		if len(passkeys) > 0 {
+			payload.Passkey = passkeys[0]
+			if payload.Data == "" {
+				payload.Data = fmt.Sprintf("Passkey for %s", passkeys[0].RPID)
+			}
+		}
+
+		items = append(items, item{source: source, payload: payload})
+
+		for i, pk := range passkeys[min(1, len(passkeys)):] {
+			items = append(items, item{
+				source: fmt.Sprintf("%s/passkey-%d", source, i+2),
+				payload: &vault.Payload{
+					Data:      fmt.Sprintf("Passkey for %s", pk.RPID),
+					Passkey:   pk,
+					CreatedAt: bwItem.CreationDate,
+				},
+			})
+		}
+	}
+
+	result.addAll(items)
+
+	return result, nil
+}
+
+func bitwardenPasskeyPayload(credential bitwardenPasskey) (*vault.Passkey, error) {
+	if credential.KeyAlgorithm != "ECDSA" || credential.KeyCurve != "P-256" {
+		return nil, fmt.Errorf("unsupported passkey algorithm %s", strings.TrimSpace(credential.KeyAlgorithm+" "+credential.KeyCurve))
+	}
+
+	der, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(credential.KeyValue, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid passkey private key: %w", err)
+	}
+
+	cred, err := passkey.ImportPKCS8(der, bitwardenCredentialID(credential.CredentialID), credential.RPID, credential.UserHandle, credential.UserName)
+	if err != nil {
+		return nil, err
+	}
+
+	pk := &vault.Passkey{
+		ID:            cred.ID,
+		PrivateKeyPEM: cred.PrivateKeyPEM,
+		PublicKeyPEM:  cred.PublicKeyPEM,
+		RPID:          cred.RPID,
+		UserID:        cred.UserID,
+		UserName:      cred.UserName,
+		CreatedAt:     cred.CreatedAt,
+	}
+
+	if counter, err := strconv.ParseUint(credential.Counter, 10, 32); err == nil {
+		pk.SignCount = uint32(counter)
+	}
+
+	if created, err := time.Parse(time.RFC3339, credential.CreationDate); err == nil {
+		pk.CreatedAt = created.UTC()
+	}
+
+	return pk, nil
+}
+
+// bitwardenCredentialID converts the credential ID of a Bitwarden passkey,
+// a GUID or "b64." followed by base64url, to raw base64url.
+func bitwardenCredentialID(id string) string {
+	if encoded, ok := strings.CutPrefix(id, "b64."); ok {
+		return encoded
+	}
+
+	if raw, err := hex.DecodeString(strings.ReplaceAll(id, "-", "")); err == nil && len(raw) == 16 && len(id) == 36 {
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	return id
+}
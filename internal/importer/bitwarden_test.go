@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestReadBitwarden(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	keyValue := base64.RawURLEncoding.EncodeToString(der)
+
+	export := fmt.Sprintf(`{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "Work/Mail"}],
+  "items": [
+    {
+      "type": 1, "name": "Company Mail", "folderId": "f1", "notes": "main account",
+      "creationDate": "2024-01-02T03:04:05Z",
+      "fields": [
+        {"name": "PIN", "value": "1234", "type": 1},
+        {"name": "Remember", "value": "true", "type": 2},
+        {"name": "Linked", "value": null, "type": 3}
+      ],
+      "login": {
+        "username": "alice", "password": "s3cret", "totp": "JBSW Y3DP EHPK 3PXP",
+        "uris": [{"uri": "https://mail.example.com"}, {"uri": "https://webmail.example.com"}]
+      }
+    },
+    {
+      "type": 1, "name": "Passkey only",
+      "login": {
+        "fido2Credentials": [
+          {
+            "credentialId": "6a8f4c3e-1b2d-4e5f-8a9b-0c1d2e3f4a5b", "keyType": "public-key",
+            "keyAlgorithm": "ECDSA", "keyCurve": "P-256", "keyValue": %[1]q,
+            "rpId": "example.com", "userHandle": "dXNlcjEyMw", "userName": "alice",
+            "counter": "7", "creationDate": "2024-05-06T07:08:09Z"
+          },
+          {
+            "credentialId": "b64.AQID", "keyType": "public-key",
+            "keyAlgorithm": "ECDSA", "keyCurve": "P-256", "keyValue": %[1]q,
+            "rpId": "example.org", "userHandle": "dXNlcjEyMw", "userName": "alice", "counter": "0"
+          },
+          {"credentialId": "b64.BAUG", "keyAlgorithm": "RSA", "keyCurve": "", "keyValue": "", "rpId": "example.net"}
+        ]
+      }
+    },
+    {"type": 1, "name": "Steam", "login": {"password": "pw", "totp": "steam://ABCDEF"}},
+    {"type": 2, "name": "Wifi", "notes": "password is on the router", "secureNote": {"type": 0}},
+    {"type": 3, "name": "Visa", "card": {"number": "4111111111111111"}},
+    {"type": 1, "name": "Deleted", "deletedDate": "2024-01-01T00:00:00Z", "login": {"password": "old"}}
+  ]
+}`, keyValue)
+
+	result, err := ReadBitwarden(strings.NewReader(export))
+	require.NoError(t, err)
+
+	entries := make(map[string]*vault.Payload)
+	for _, entry := range result.Entries {
+		entries[entry.Name] = entry.Payload
+	}
+
+	require.Len(t, entries, 5)
+
+	mail := entries["/Work/Mail/Company_Mail"]
+	require.NotNil(t, mail)
+	assert.Equal(t, "s3cret", mail.Data)
+	assert.Equal(t, "alice", mail.Username)
+	assert.Equal(t, "https://mail.example.com", mail.URL)
+	assert.Equal(t, "main account", mail.Notes)
+	assert.Equal(t, map[string]string{"pin": "1234", "remember": "true", "url_2": "https://webmail.example.com"}, mail.Fields)
+	assert.Equal(t, &vault.OTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 6, Period: 30}, mail.OTP)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), mail.CreatedAt)
+
+	passkeyEntry := entries["/Passkey_only"]
+	require.NotNil(t, passkeyEntry)
+	require.NotNil(t, passkeyEntry.Passkey)
+	assert.Equal(t, "Passkey for example.com", passkeyEntry.Data)
+	assert.Equal(t, "ao9MPhstTl-KmwwdLj9KWw", passkeyEntry.Passkey.ID)
+	assert.Equal(t, "example.com", passkeyEntry.Passkey.RPID)
+	assert.Equal(t, "dXNlcjEyMw", passkeyEntry.Passkey.UserID)
+	assert.Equal(t, "alice", passkeyEntry.Passkey.UserName)
+	assert.Equal(t, uint32(7), passkeyEntry.Passkey.SignCount)
+	assert.Equal(t, time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC), passkeyEntry.Passkey.CreatedAt)
+	assert.Contains(t, passkeyEntry.Passkey.PrivateKeyPEM, "EC PRIVATE KEY")
+
+	second := entries["/Passkey_only/passkey-2"]
+	require.NotNil(t, second)
+	assert.Equal(t, "AQID", second.Passkey.ID)
+	assert.Equal(t, "example.org", second.Passkey.RPID)
+
+	steam := entries["/Steam"]
+	assert.Nil(t, steam.OTP)
+	assert.Equal(t, "steam://ABCDEF", steam.Fields["totp"])
+
+	assert.Equal(t, "password is on the router", entries["/Wifi"].Notes)
+
+	skipped := make(map[string]string)
+	for _, s := range result.Skipped {
+		skipped[s.Source] = s.Reason
+	}
+
+	assert.Equal(t, map[string]string{
+		"/Passkey only (passkey 3)": "unsupported passkey algorithm RSA",
+		"/Visa":                     "cards are not supported",
+		"/Deleted":                  "item is in the trash",
+	}, skipped)
+}
+
+func TestReadBitwardenErrors(t *testing.T) {
+	_, err := ReadBitwarden(strings.NewReader(`{"encrypted": true, "items": []}`))
+	assert.ErrorContains(t, err, "encrypted")
+
+	_, err = ReadBitwarden(strings.NewReader(`not json`))
+	assert.Error(t, err)
+}
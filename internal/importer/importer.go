@@ -0,0 +1,228 @@
+// Package importer reads entries from other password managers and maps them
+// to vault payloads.
+package importer
+
+import (
+	"encoding/base32"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/vitalvas/gopass/internal/otp"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+// Entry is an imported entry. Source is the name in the original store,
+// Name the key name it is stored under.
+type Entry struct {
+	Source  string
+	Name    string
+	Payload *vault.Payload
+}
+
+func (e *Entry) Renamed() bool {
+	return e.Source != e.Name
+}
+
+// Skipped is a source entry that could not be imported.
+type Skipped struct {
+	Source string
+	Reason string
+}
+
+type Result struct {
+	Entries []*Entry
+	Skipped []Skipped
+}
+
+func (r *Result) skip(source, format string, args ...any) {
+	r.Skipped = append(r.Skipped, Skipped{
+		Source: source,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+type item struct {
+	source  string
+	payload *vault.Payload
+}
+
+// addAll stores the items under valid key names derived from their source
+// paths. Sources that are valid key names keep them, the others are renamed
+// and get a numeric suffix when the name is already taken.
+func (r *Result) addAll(items []item) {
+	names := make(map[string]bool)
+	entries := make([]*Entry, len(items))
+
+	for i, it := range items {
+		if vault.ValidateKeyName(it.source) == nil && !names[it.source] {
+			names[it.source] = true
+			entries[i] = &Entry{Source: it.source, Name: it.source, Payload: it.payload}
+		}
+	}
+
+	for i, it := range items {
+		if entries[i] != nil {
+			continue
+		}
+
+		name, err := KeyName(it.source)
+		if err == nil {
+			name, err = uniqueName(name, names)
+		}
+
+		if err != nil {
+			r.skip(it.source, "%v", err)
+			continue
+		}
+
+		names[name] = true
+		entries[i] = &Entry{Source: it.source, Name: name, Payload: it.payload}
+	}
+
+	for _, entry := range entries {
+		if entry != nil {
+			r.Entries = append(r.Entries, entry)
+		}
+	}
+}
+
+func uniqueName(name string, names map[string]bool) (string, error) {
+	candidate := name
+
+	for i := 2; names[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+
+		if err := vault.ValidateKeyName(candidate); err != nil {
+			return "", err
+		}
+	}
+
+	return candidate, nil
+}
+
+type field struct {
+	name  string
+	value string
+}
+
+// addFields stores custom fields with their names lowercased and sanitized.
+// Empty values are dropped, fields without a usable name or with a name that
+// is already taken are appended to the notes as "name: value" lines.
+func addFields(payload *vault.Payload, fields []field) {
+	var extraNotes []string
+
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+
+		name := strings.ToLower(sanitizeSegment(f.name))
+
+		if _, exists := payload.GetField(name); !exists {
+			if err := payload.SetField(name, f.value); err == nil {
+				continue
+			}
+		}
+
+		extraNotes = append(extraNotes, f.name+": "+f.value)
+	}
+
+	if len(extraNotes) > 0 {
+		payload.Notes = strings.TrimSpace(payload.Notes + "\n\n" + strings.Join(extraNotes, "\n"))
+	}
+}
+
+// KeyName maps a slash separated path to a valid key name. Characters that
+// are not allowed in key names are replaced with underscores and empty
+// segments are dropped.
+func KeyName(path string) (string, error) {
+	var segments []string
+
+	for _, segment := range strings.Split(path, "/") {
+		segment = sanitizeSegment(segment)
+
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+
+		segments = append(segments, segment)
+	}
+
+	name := "/" + strings.Join(segments, "/")
+
+	if err := vault.ValidateKeyName(name); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+func sanitizeSegment(segment string) string {
+	var b strings.Builder
+
+	replaced := false
+
+	for _, r := range strings.TrimSpace(segment) {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+			replaced = false
+
+		case !replaced:
+			b.WriteByte('_')
+			replaced = true
+		}
+	}
+
+	return strings.Trim(b.String(), "_")
+}
+
+// ParseOTP converts an otpauth:// URI to the OTP section of a payload, only
+// TOTP is supported.
+func ParseOTP(uri string) (*vault.OTP, error) {
+	parsed, err := otp.ParseURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OTP URI: %w", err)
+	}
+
+	if parsed.Type != "totp" {
+		return nil, fmt.Errorf("only TOTP is supported, got: %s", parsed.Type)
+	}
+
+	return &vault.OTP{
+		Secret: strings.ToUpper(parsed.Secret),
+		Digits: parsed.Digits,
+		Period: parsed.Period,
+	}, nil
+}
+
+// parseTOTP accepts an otpauth:// URI or a bare base32 secret, which uses the
+// default digits and period.
+func parseTOTP(value string) (*vault.OTP, error) {
+	value = strings.TrimSpace(value)
+
+	if strings.HasPrefix(strings.ToLower(value), "otpauth://") {
+		return ParseOTP(value)
+	}
+
+	secret := normalizeSecret(value)
+
+	if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "=")); err != nil || secret == "" {
+		return nil, fmt.Errorf("invalid TOTP secret")
+	}
+
+	return &vault.OTP{
+		Secret: secret,
+		Digits: otp.DefaultDigits,
+		Period: otp.DefaultPeriod,
+	}, nil
+}
+
+func normalizeSecret(secret string) string {
+	if unescaped, err := url.QueryUnescape(secret); err == nil {
+		secret = unescaped
+	}
+
+	return strings.ToUpper(strings.Join(strings.Fields(secret), ""))
+}
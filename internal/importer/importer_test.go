@@ -0,0 +1,46 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyName(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		expected string
+	}{
+		{"/email/work", "/email/work"},
+		{"email/work", "/email/work"},
+		{"/web/my site (old)", "/web/my_site_old"},
+		{"/web//example.com", "/web/example.com"},
+		{"/bank/Über Bank", "/bank/ber_Bank"},
+		{"/../etc/passwd", "/etc/passwd"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			name, err := KeyName(tc.path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, name)
+		})
+	}
+
+	for _, path := range []string{"", "/", "/a", "/äöü", "/" + strings.Repeat("a", 128)} {
+		_, err := KeyName(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestParseOTP(t *testing.T) {
+	otpData, err := ParseOTP("otpauth://totp/Example:alice?secret=jbswy3dpehpk3pxp&issuer=Example&digits=8")
+	require.NoError(t, err)
+
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", otpData.Secret)
+	assert.Equal(t, 8, otpData.Digits)
+	assert.Equal(t, 30, otpData.Period)
+
+	_, err = ParseOTP("otpauth://hotp/Example:alice?secret=JBSWY3DPEHPK3PXP&counter=1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"encoding/base64"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vitalvas/gopass/internal/kdbx"
+	"github.com/vitalvas/gopass/internal/otp"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+// Standard KeePass fields.
+const (
+	keepassTitle    = "Title"
+	keepassUserName = "UserName"
+	keepassPassword = "Password"
+	keepassURL      = "URL"
+	keepassNotes    = "Notes"
+)
+
+// ReadKeePass maps a KeePass database to entries. Groups below the root
+// group become key paths and the entry title the last segment, entries in
+// the recycle bin are ignored. Attachments are stored as separate keys below
+// the key of their entry.
+func ReadKeePass(db *kdbx.Database) *Result {
+	result := &Result{}
+
+	var items []item
+
+	var walk func(group *kdbx.Group, path string)
+
+	walk = func(group *kdbx.Group, path string) {
+		for _, entry := range group.Entries {
+			title := strings.TrimSpace(entry.Fields[keepassTitle])
+			if title == "" {
+				title = "untitled"
+			}
+
+			source := path + "/" + strings.ReplaceAll(title, "/", "_")
+
+			items = append(items, item{source: source, payload: keepassPayload(entry)})
+
+			for _, attachment := range entry.Attachments {
+				items = append(items, item{
+					source:  source + "/" + strings.ReplaceAll(attachment.Name, "/", "_"),
+					payload: attachmentPayload(attachment),
+				})
+			}
+		}
+
+		for _, child := range group.Groups {
+			if db.RecycleBinUUID != "" && child.UUID == db.RecycleBinUUID {
+				continue
+			}
+
+			walk(child, path+"/"+strings.ReplaceAll(child.Name, "/", "_"))
+		}
+	}
+
+	walk(db.Root, "")
+
+	result.addAll(items)
+
+	return result
+}
+
+func keepassPayload(entry *kdbx.Entry) *vault.Payload {
+	fields := entry.Fields
+
+	payload := &vault.Payload{
+		Data:      fields[keepassPassword],
+		Username:  fields[keepassUserName],
+		URL:       fields[keepassURL],
+		Notes:     fields[keepassNotes],
+		CreatedAt: entry.CreatedAt,
+		ExpiresAt: entry.ExpiresAt,
+	}
+
+	otpData, otpFields := keepassOTP(fields)
+	payload.OTP = otpData
+
+	var extra []field
+
+	for name, value := range fields {
+		switch name {
+		case keepassTitle, keepassUserName, keepassPassword, keepassURL, keepassNotes:
+			continue
+		}
+
+		if !otpFields[name] {
+			extra = append(extra, field{name: name, value: value})
+		}
+	}
+
+	slices.SortFunc(extra, func(a, b field) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	addFields(payload, extra)
+
+	return payload
+}
+
+// keepassOTP reads the TOTP settings of KeePassXC ("otp" with an otpauth://
+// URI or the older "TOTP Seed" and "TOTP Settings") and KeePass 2.47+
+// ("TimeOtp-*"). It returns the fields that were used, settings that cannot
+// be used are kept as fields.
+func keepassOTP(fields map[string]string) (*vault.OTP, map[string]bool) {
+	if uri := strings.TrimSpace(fields["otp"]); uri != "" {
+		otpData, err := ParseOTP(uri)
+		if err != nil {
+			return nil, nil
+		}
+
+		return otpData, map[string]bool{"otp": true}
+	}
+
+	if seed := fields["TOTP Seed"]; seed != "" {
+		otpData := &vault.OTP{
+			Secret: normalizeSecret(seed),
+			Digits: otp.DefaultDigits,
+			Period: otp.DefaultPeriod,
+		}
+
+		// "period;digits", digits can also be "S" for Steam codes.
+		if settings := fields["TOTP Settings"]; settings != "" {
+			period, digits, _ := strings.Cut(settings, ";")
+
+			if value, err := strconv.Atoi(period); err == nil && value > 0 {
+				otpData.Period = value
+			}
+
+			if value, err := strconv.Atoi(digits); err == nil && value > 0 {
+				otpData.Digits = value
+			} else if digits != "" {
+				return nil, nil
+			}
+		}
+
+		return otpData, map[string]bool{"TOTP Seed": true, "TOTP Settings": true}
+	}
+
+	secret := fields["TimeOtp-Secret-Base32"]
+	if secret == "" {
+		return nil, nil
+	}
+
+	if algorithm := fields["TimeOtp-Algorithm"]; algorithm != "" && algorithm != "HMAC-SHA-1" {
+		return nil, nil
+	}
+
+	otpData := &vault.OTP{
+		Secret: normalizeSecret(secret),
+		Digits: otp.DefaultDigits,
+		Period: otp.DefaultPeriod,
+	}
+
+	if value, err := strconv.Atoi(fields["TimeOtp-Length"]); err == nil && value > 0 {
+		otpData.Digits = value
+	}
+
+	if value, err := strconv.Atoi(fields["TimeOtp-Period"]); err == nil && value > 0 {
+		otpData.Period = value
+	}
+
+	return otpData, map[string]bool{
+		"TimeOtp-Secret-Base32": true,
+		"TimeOtp-Length":        true,
+		"TimeOtp-Period":        true,
+		"TimeOtp-Algorithm":     true,
+	}
+}
+
+// attachmentPayload stores text attachments as they are and binary ones
+// base64 encoded.
+func attachmentPayload(attachment kdbx.Attachment) *vault.Payload {
+	payload := &vault.Payload{
+		Fields: map[string]string{"filename": attachment.Name},
+	}
+
+	if utf8.Valid(attachment.Data) {
+		payload.Data = string(attachment.Data)
+	} else {
+		payload.Data = base64.StdEncoding.EncodeToString(attachment.Data)
+		payload.Fields["encoding"] = "base64"
+	}
+
+	return payload
+}
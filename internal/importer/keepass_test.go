@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/kdbx"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestReadKeePass(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	db := &kdbx.Database{
+		RecycleBinUUID: "bin",
+		Root: &kdbx.Group{
+			Name: "Passwords",
+			Entries: []*kdbx.Entry{
+				{Fields: map[string]string{"Title": "Router", "Password": "admin"}},
+			},
+			Groups: []*kdbx.Group{
+				{
+					Name: "Internet",
+					Entries: []*kdbx.Entry{
+						{
+							Fields: map[string]string{
+								"Title":         "Mail",
+								"UserName":      "alice",
+								"Password":      "s3cret",
+								"URL":           "https://mail.example.com",
+								"Notes":         "main account",
+								"otp":           "otpauth://totp/Mail:alice?secret=JBSWY3DPEHPK3PXP&period=60",
+								"Recovery Code": "1234-5678",
+								"Ключ":          "значение",
+							},
+							Attachments: []kdbx.Attachment{
+								{Name: "codes.txt", Data: []byte("one\ntwo")},
+								{Name: "key.bin", Data: []byte{0xff, 0x00, 0xfe}},
+							},
+							CreatedAt: created,
+						},
+						{Fields: map[string]string{"Title": "Mail", "Password": "second"}},
+						{Fields: map[string]string{"Title": "Steam", "TOTP Seed": "jbsw y3dp", "TOTP Settings": "30;S"}},
+					},
+				},
+				{
+					Name: "Legacy TOTP",
+					Entries: []*kdbx.Entry{
+						{Fields: map[string]string{"Title": "VPN", "TOTP Seed": "JBSWY3DPEHPK3PXP", "TOTP Settings": "30;8"}},
+						{Fields: map[string]string{"Title": "Bank", "TimeOtp-Secret-Base32": "JBSWY3DPEHPK3PXP", "TimeOtp-Length": "7"}},
+					},
+				},
+				{
+					UUID:    "bin",
+					Name:    "Recycle Bin",
+					Entries: []*kdbx.Entry{{Fields: map[string]string{"Title": "Deleted"}}},
+				},
+			},
+		},
+	}
+
+	result := ReadKeePass(db)
+	require.Empty(t, result.Skipped)
+
+	entries := make(map[string]*vault.Payload)
+	for _, entry := range result.Entries {
+		entries[entry.Name] = entry.Payload
+	}
+
+	require.Len(t, entries, 8)
+
+	mail := entries["/Internet/Mail"]
+	require.NotNil(t, mail)
+	assert.Equal(t, "s3cret", mail.Data)
+	assert.Equal(t, "alice", mail.Username)
+	assert.Equal(t, "https://mail.example.com", mail.URL)
+	assert.Equal(t, "main account\n\nКлюч: значение", mail.Notes)
+	assert.Equal(t, map[string]string{"recovery_code": "1234-5678"}, mail.Fields)
+	assert.Equal(t, &vault.OTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 6, Period: 60}, mail.OTP)
+	assert.Equal(t, created, mail.CreatedAt)
+
+	assert.Equal(t, "second", entries["/Internet/Mail-2"].Data)
+	assert.Equal(t, "admin", entries["/Router"].Data)
+
+	assert.Equal(t, "one\ntwo", entries["/Internet/Mail/codes.txt"].Data)
+	assert.Equal(t, map[string]string{"filename": "key.bin", "encoding": "base64"}, entries["/Internet/Mail/key.bin"].Fields)
+	assert.Equal(t, "/wD+", entries["/Internet/Mail/key.bin"].Data)
+
+	// Steam codes are not supported, the settings are kept as fields.
+	steam := entries["/Internet/Steam"]
+	assert.Nil(t, steam.OTP)
+	assert.Equal(t, "jbsw y3dp", steam.Fields["totp_seed"])
+
+	assert.Equal(t, &vault.OTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 8, Period: 30}, entries["/Legacy_TOTP/VPN"].OTP)
+	assert.Equal(t, &vault.OTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 7, Period: 30}, entries["/Legacy_TOTP/Bank"].OTP)
+	assert.Empty(t, entries["/Legacy_TOTP/Bank"].Fields)
+
+	assert.NotContains(t, entries, "/Recycle_Bin/Deleted")
+}
@@ -0,0 +1,347 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+// onePUXData is the name of the item data in a 1PUX archive.
+const onePUXData = "export.data"
+
+// Kinds of the 1Password CSV columns.
+const (
+	csvTitle = iota + 1
+	csvURL
+	csvUsername
+	csvPassword
+	csvNotes
+	csvOTP
+	csvIgnored
+)
+
+// onePasswordCSVColumns maps the column names of the 1Password CSV exports,
+// lowercased, to the payload. Other columns become fields.
+var onePasswordCSVColumns = map[string]int{
+	"title":             csvTitle,
+	"name":              csvTitle,
+	"url":               csvURL,
+	"website":           csvURL,
+	"login_url":         csvURL,
+	"username":          csvUsername,
+	"login_username":    csvUsername,
+	"password":          csvPassword,
+	"login_password":    csvPassword,
+	"notes":             csvNotes,
+	"notesplain":        csvNotes,
+	"otpauth":           csvOTP,
+	"one-time password": csvOTP,
+	"favorite":          csvIgnored,
+	"archived":          csvIgnored,
+}
+
+// ReadOnePasswordCSV reads a 1Password CSV export. The first row names the
+// columns, entries are stored under their title.
+func ReadOnePasswordCSV(r io.Reader) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("CSV export is empty")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV export: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	hasTitle := false
+
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		columns[i] = name
+
+		if onePasswordCSVColumns[strings.ToLower(name)] == csvTitle {
+			hasTitle = true
+		}
+	}
+
+	if !hasTitle {
+		return nil, fmt.Errorf("CSV export has no title column")
+	}
+
+	result := &Result{}
+
+	var items []item
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV export: %w", err)
+		}
+
+		payload := &vault.Payload{}
+
+		var title string
+
+		var extra []field
+
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+
+			switch onePasswordCSVColumns[strings.ToLower(columns[i])] {
+			case csvTitle:
+				title = strings.TrimSpace(value)
+			case csvURL:
+				payload.URL = value
+			case csvUsername:
+				payload.Username = value
+			case csvPassword:
+				payload.Data = value
+			case csvNotes:
+				payload.Notes = value
+			case csvOTP:
+				if value == "" {
+					continue
+				}
+
+				otpData, err := parseTOTP(value)
+				if err != nil {
+					extra = append(extra, field{name: columns[i], value: value})
+				} else {
+					payload.OTP = otpData
+				}
+			case csvIgnored:
+			default:
+				extra = append(extra, field{name: columns[i], value: value})
+			}
+		}
+
+		if title == "" {
+			title = "untitled"
+		}
+
+		source := "/" + strings.ReplaceAll(title, "/", "_")
+
+		if payload.Data == "" && payload.Username == "" && payload.Notes == "" && payload.OTP == nil {
+			result.skip(source, "line %d is empty", line)
+			continue
+		}
+
+		addFields(payload, extra)
+
+		items = append(items, item{source: source, payload: payload})
+	}
+
+	result.addAll(items)
+
+	return result, nil
+}
+
+type onePUXExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []onePUXItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type onePUXItem struct {
+	CreatedAt int64  `json:"createdAt"`
+	State     string `json:"state"`
+	Details   struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Name        string `json:"name"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"`
+		Sections   []struct {
+			Title  string `json:"title"`
+			Fields []struct {
+				Title string                     `json:"title"`
+				ID    string                     `json:"id"`
+				Value map[string]json.RawMessage `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+		Passkey json.RawMessage `json:"passkey"`
+	} `json:"details"`
+	Overview struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+		URLs  []struct {
+			URL string `json:"url"`
+		} `json:"urls"`
+	} `json:"overview"`
+}
+
+// ReadOnePUX reads a 1Password 1PUX export. Vaults become the first segment
+// of the key path. Section fields become fields and TOTP fields the OTP
+// secret. Attachments and passkeys are not part of the exported data.
+func ReadOnePUX(r io.ReaderAt, size int64) (*Result, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open 1PUX export: %w", err)
+	}
+
+	file, err := archive.Open(onePUXData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open 1PUX export: %w", err)
+	}
+	defer file.Close()
+
+	var export onePUXExport
+
+	if err := json.NewDecoder(file).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to parse 1PUX export: %w", err)
+	}
+
+	result := &Result{}
+
+	var items []item
+
+	for _, account := range export.Accounts {
+		for _, opVault := range account.Vaults {
+			for _, opItem := range opVault.Items {
+				title := strings.TrimSpace(opItem.Overview.Title)
+				if title == "" {
+					title = "untitled"
+				}
+
+				source := "/" + strings.ReplaceAll(opVault.Attrs.Name, "/", "_") + "/" + strings.ReplaceAll(title, "/", "_")
+
+				if opItem.State == "deleted" {
+					result.skip(source, "item is in the trash")
+					continue
+				}
+
+				if len(opItem.Details.Passkey) > 0 && string(opItem.Details.Passkey) != "null" {
+					result.skip(source+" (passkey)", "1Password does not export passkey private keys")
+				}
+
+				items = append(items, item{source: source, payload: onePUXPayload(opItem)})
+			}
+		}
+	}
+
+	result.addAll(items)
+
+	return result, nil
+}
+
+func onePUXPayload(opItem onePUXItem) *vault.Payload {
+	details := opItem.Details
+
+	payload := &vault.Payload{
+		Data:  details.Password,
+		URL:   opItem.Overview.URL,
+		Notes: details.NotesPlain,
+	}
+
+	if opItem.CreatedAt > 0 {
+		payload.CreatedAt = time.Unix(opItem.CreatedAt, 0).UTC()
+	}
+
+	var extra []field
+
+	for _, loginField := range details.LoginFields {
+		switch {
+		case loginField.Designation == "username" && payload.Username == "":
+			payload.Username = loginField.Value
+		case loginField.Designation == "password" && payload.Data == "":
+			payload.Data = loginField.Value
+		case loginField.Designation == "":
+			extra = append(extra, field{name: loginField.Name, value: loginField.Value})
+		}
+	}
+
+	for i, u := range opItem.Overview.URLs {
+		if u.URL == "" || u.URL == payload.URL {
+			continue
+		}
+
+		extra = append(extra, field{name: fmt.Sprintf("url_%d", i+1), value: u.URL})
+	}
+
+	for _, section := range details.Sections {
+		for _, sectionField := range section.Fields {
+			name := sectionField.Title
+			if name == "" {
+				name = sectionField.ID
+			}
+
+			if raw, ok := sectionField.Value["totp"]; ok && payload.OTP == nil {
+				var uri string
+				if json.Unmarshal(raw, &uri) == nil && uri != "" {
+					if otpData, err := parseTOTP(uri); err == nil {
+						payload.OTP = otpData
+						continue
+					}
+				}
+			}
+
+			extra = append(extra, field{name: name, value: onePUXValue(sectionField.Value)})
+		}
+	}
+
+	addFields(payload, extra)
+
+	return payload
+}
+
+// onePUXValue returns the value of a section field as text. Values are
+// objects with the value type as the only key, structured values other than
+// email addresses are dropped.
+func onePUXValue(value map[string]json.RawMessage) string {
+	for kind, raw := range value {
+		var text string
+		if json.Unmarshal(raw, &text) == nil {
+			return text
+		}
+
+		var number json.Number
+		if json.Unmarshal(raw, &number) == nil {
+			if kind == "date" {
+				if seconds, err := number.Int64(); err == nil {
+					return time.Unix(seconds, 0).UTC().Format(time.DateOnly)
+				}
+			}
+
+			return number.String()
+		}
+
+		if kind == "email" {
+			var email struct {
+				Address string `json:"email_address"`
+			}
+
+			if json.Unmarshal(raw, &email) == nil {
+				return email.Address
+			}
+		}
+
+		if kind == "boolean" {
+			var flag bool
+			if json.Unmarshal(raw, &flag) == nil {
+				return strconv.FormatBool(flag)
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestReadOnePasswordCSV(t *testing.T) {
+	export := "\ufeffTitle,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\n" +
+		"Mail,https://mail.example.com,alice,s3cret,otpauth://totp/Mail?secret=JBSWY3DPEHPK3PXP&digits=8,false,false,work,\"line 1\nline 2\"\n" +
+		"Mail,,bob,other,,false,false,,\n" +
+		"Empty,,,,,false,false,,\n"
+
+	result, err := ReadOnePasswordCSV(strings.NewReader(export))
+	require.NoError(t, err)
+
+	entries := make(map[string]*vault.Payload)
+	for _, entry := range result.Entries {
+		entries[entry.Name] = entry.Payload
+	}
+
+	require.Len(t, entries, 2)
+
+	mail := entries["/Mail"]
+	require.NotNil(t, mail)
+	assert.Equal(t, "s3cret", mail.Data)
+	assert.Equal(t, "alice", mail.Username)
+	assert.Equal(t, "https://mail.example.com", mail.URL)
+	assert.Equal(t, "line 1\nline 2", mail.Notes)
+	assert.Equal(t, map[string]string{"tags": "work"}, mail.Fields)
+	assert.Equal(t, &vault.OTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 8, Period: 30}, mail.OTP)
+
+	assert.Equal(t, "bob", entries["/Mail-2"].Username)
+
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, "/Empty", result.Skipped[0].Source)
+
+	_, err = ReadOnePasswordCSV(strings.NewReader("Username,Password\nalice,pw\n"))
+	assert.ErrorContains(t, err, "no title column")
+
+	_, err = ReadOnePasswordCSV(strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+func TestReadOnePUX(t *testing.T) {
+	data := `{
+  "accounts": [{
+    "attrs": {"accountName": "Alice"},
+    "vaults": [{
+      "attrs": {"name": "Personal"},
+      "items": [
+        {
+          "createdAt": 1704164645, "state": "active", "categoryUuid": "001",
+          "details": {
+            "loginFields": [
+              {"value": "alice", "name": "username", "fieldType": "T", "designation": "username"},
+              {"value": "s3cret", "name": "password", "fieldType": "P", "designation": "password"},
+              {"value": "on", "name": "remember", "fieldType": "C", "designation": ""}
+            ],
+            "notesPlain": "main account",
+            "sections": [{
+              "title": "",
+              "fields": [
+                {"title": "one-time password", "id": "TOTP_1", "value": {"totp": "otpauth://totp/Mail?secret=JBSWY3DPEHPK3PXP"}},
+                {"title": "Recovery Email", "id": "r1", "value": {"email": {"email_address": "alice@example.org", "provider": null}}},
+                {"title": "Member Since", "id": "m1", "value": {"date": 1704164645}},
+                {"title": "PIN", "id": "p1", "value": {"concealed": "1234"}}
+              ]
+            }],
+            "passkey": {"credentialId": "AQID", "rpId": "example.com"}
+          },
+          "overview": {
+            "title": "Mail", "url": "https://mail.example.com",
+            "urls": [{"label": "", "url": "https://mail.example.com"}, {"label": "", "url": "https://webmail.example.com"}]
+          }
+        },
+        {
+          "createdAt": 1704164645, "state": "archived", "categoryUuid": "005",
+          "details": {"password": "wifi-password", "notesPlain": ""},
+          "overview": {"title": "Home/Wifi"}
+        }
+      ]
+    }]
+  }]
+}`
+
+	var buf bytes.Buffer
+
+	archive := zip.NewWriter(&buf)
+
+	w, err := archive.Create(onePUXData)
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+
+	result, err := ReadOnePUX(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	entries := make(map[string]*vault.Payload)
+	for _, entry := range result.Entries {
+		entries[entry.Name] = entry.Payload
+	}
+
+	require.Len(t, entries, 2)
+
+	mail := entries["/Personal/Mail"]
+	require.NotNil(t, mail)
+	assert.Equal(t, "s3cret", mail.Data)
+	assert.Equal(t, "alice", mail.Username)
+	assert.Equal(t, "https://mail.example.com", mail.URL)
+	assert.Equal(t, "main account", mail.Notes)
+	assert.Equal(t, &vault.OTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 6, Period: 30}, mail.OTP)
+	assert.Equal(t, time.Unix(1704164645, 0).UTC(), mail.CreatedAt)
+	assert.Equal(t, map[string]string{
+		"remember":       "on",
+		"url_2":          "https://webmail.example.com",
+		"recovery_email": "alice@example.org",
+		"member_since":   "2024-01-02",
+		"pin":            "1234",
+	}, mail.Fields)
+
+	assert.Equal(t, "wifi-password", entries["/Personal/Home_Wifi"].Data)
+
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, "/Personal/Mail (passkey)", result.Skipped[0].Source)
+
+	_, err = ReadOnePUX(bytes.NewReader([]byte("not a zip")), 9)
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+const passExtension = ".gpg"
+
+// Decrypter decrypts an OpenPGP message.
+type Decrypter func(ciphertext []byte) ([]byte, error)
+
+// ReadPass walks a pass password store and decrypts all entries. Hidden
+// files and directories such as .git are ignored, files that cannot be
+// decrypted or parsed are reported as skipped.
+func ReadPass(dir string, decrypt Decrypter) (*Result, error) {
+	result := &Result{}
+
+	var items []item
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() || !strings.HasSuffix(d.Name(), passExtension) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		source := "/" + strings.TrimSuffix(filepath.ToSlash(rel), passExtension)
+
+		ciphertext, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		plaintext, err := decrypt(ciphertext)
+		if err != nil {
+			result.skip(source, "failed to decrypt: %v", err)
+			return nil
+		}
+
+		payload, err := ParsePass(string(plaintext))
+		if err != nil {
+			result.skip(source, "%v", err)
+			return nil
+		}
+
+		items = append(items, item{source: source, payload: payload})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password store: %w", err)
+	}
+
+	result.addAll(items)
+
+	return result, nil
+}
+
+// ParsePass converts the content of a pass entry. The first line is the
+// password, "otpauth://" lines become the OTP section, "name: value" lines
+// with a valid field name become fields and everything else is kept as
+// notes.
+func ParsePass(content string) (*vault.Payload, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	payload := &vault.Payload{
+		Data: lines[0],
+	}
+
+	var notes []string
+
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimSpace(line)
+
+		// URIs that cannot be used, such as HOTP, are kept in the notes.
+		if strings.HasPrefix(trimmed, "otpauth://") && payload.OTP == nil {
+			if otpData, err := ParseOTP(trimmed); err == nil {
+				payload.OTP = otpData
+				continue
+			}
+		}
+
+		if name, value, ok := passField(line); ok {
+			if _, exists := payload.GetField(name); !exists {
+				if err := payload.SetField(name, value); err == nil {
+					continue
+				}
+			}
+		}
+
+		notes = append(notes, line)
+	}
+
+	payload.Notes = strings.TrimSpace(strings.Join(notes, "\n"))
+
+	if payload.Data == "" && payload.OTP == nil && payload.Notes == "" && len(payload.Fields) == 0 {
+		return nil, fmt.Errorf("entry is empty")
+	}
+
+	return payload, nil
+}
+
+// passField parses the "name: value" lines used by pass extensions and
+// browser plugins, with the common names mapped to the payload fields.
+func passField(line string) (string, string, bool) {
+	name, value, ok := strings.Cut(line, ": ")
+	if !ok {
+		return "", "", false
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	value = strings.TrimSpace(value)
+
+	if value == "" || vault.ValidateFieldName(name) != nil {
+		return "", "", false
+	}
+
+	switch name {
+	case "login", "user", "email":
+		name = vault.FieldUsername
+
+	case "website", "site":
+		name = vault.FieldURL
+
+	case vault.FieldPassword, vault.FieldNotes:
+		return "", "", false
+	}
+
+	return name, value, true
+}
@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePass(t *testing.T) {
+	payload, err := ParsePass("s3cret\nlogin: alice\nurl: https://example.com\notpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP\npin: 1234\n\nhttps://example.com/recovery\nsome notes\n")
+	require.NoError(t, err)
+
+	assert.Equal(t, "s3cret", payload.Data)
+	assert.Equal(t, "alice", payload.Username)
+	assert.Equal(t, "https://example.com", payload.URL)
+	assert.Equal(t, map[string]string{"pin": "1234"}, payload.Fields)
+	assert.Equal(t, "https://example.com/recovery\nsome notes", payload.Notes)
+
+	require.NotNil(t, payload.OTP)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", payload.OTP.Secret)
+}
+
+func TestParsePassKeepsUnknownLines(t *testing.T) {
+	payload, err := ParsePass("s3cret\notpauth://hotp/Example?secret=JBSWY3DPEHPK3PXP\nsecurity question: first pet?\nlogin: alice\nlogin: bob")
+	require.NoError(t, err)
+
+	assert.Nil(t, payload.OTP)
+	assert.Equal(t, "alice", payload.Username)
+	assert.Equal(t, "otpauth://hotp/Example?secret=JBSWY3DPEHPK3PXP\nsecurity question: first pet?\nlogin: bob", payload.Notes)
+
+	_, err = ParsePass("\n\n")
+	assert.Error(t, err)
+}
+
+func TestReadPass(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"email/work.gpg":        "work\nlogin: alice",
+		"web/my site.gpg":       "site",
+		"web/my_site.gpg":       "duplicate",
+		"broken.gpg":            "fail",
+		"a.gpg":                 "too short",
+		".git/objects/aa.gpg":   "ignored",
+		".gpg-id":               "ABCDEF",
+		"web/readme.txt":        "ignored",
+		"deep/nested/entry.gpg": "nested",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	decrypt := func(ciphertext []byte) ([]byte, error) {
+		if string(ciphertext) == "fail" {
+			return nil, errors.New("no secret key")
+		}
+
+		return ciphertext, nil
+	}
+
+	result, err := ReadPass(dir, decrypt)
+	require.NoError(t, err)
+
+	imported := make(map[string]string)
+
+	for _, entry := range result.Entries {
+		imported[entry.Name] = entry.Payload.Data
+		assert.Equal(t, entry.Source == "/web/my site", entry.Renamed(), entry.Source)
+	}
+
+	assert.Equal(t, map[string]string{
+		"/deep/nested/entry": "nested",
+		"/email/work":        "work",
+		"/web/my_site":       "duplicate",
+		"/web/my_site-2":     "site",
+	}, imported)
+
+	skipped := make(map[string]string)
+	for _, s := range result.Skipped {
+		skipped[s.Source] = s.Reason
+	}
+
+	require.Len(t, skipped, 2)
+	assert.Contains(t, skipped["/broken"], "failed to decrypt")
+	assert.Contains(t, skipped["/a"], "invalid key name")
+}
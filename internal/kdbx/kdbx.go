@@ -0,0 +1,419 @@
+// Package kdbx reads KeePass KDBX 4 databases.
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+const (
+	signature1 = 0x9AA2D903
+	signature2 = 0xB54BFB67
+
+	majorVersion = 4
+
+	headerEnd         = 0
+	headerCipherID    = 2
+	headerCompression = 3
+	headerMasterSeed  = 4
+	headerIV          = 7
+	headerKDF         = 11
+
+	innerHeaderEnd       = 0
+	innerHeaderStreamID  = 1
+	innerHeaderStreamKey = 2
+	innerHeaderBinary    = 3
+
+	streamSalsa20  = 2
+	streamChaCha20 = 3
+)
+
+var (
+	cipherAES256   = []byte{0x31, 0xc1, 0xf2, 0xe6, 0xbf, 0x71, 0x43, 0x50, 0xbe, 0x58, 0x05, 0x21, 0x6a, 0xfc, 0x5a, 0xff}
+	cipherChaCha20 = []byte{0xd6, 0x03, 0x8a, 0x2b, 0x8b, 0x6f, 0x4c, 0xb5, 0xa5, 0x24, 0x33, 0x9a, 0x31, 0xdb, 0xb5, 0x9a}
+)
+
+var (
+	ErrInvalidFile        = errors.New("not a KeePass database")
+	ErrUnsupported        = errors.New("unsupported KeePass database")
+	ErrInvalidCredentials = errors.New("invalid password or key file")
+	ErrCorrupted          = errors.New("KeePass database is corrupted")
+)
+
+// Credentials unlock a database. Password is ignored when it is nil, KeyFile
+// holds the content of the key file.
+type Credentials struct {
+	Password []byte
+	KeyFile  []byte
+}
+
+type Database struct {
+	Root *Group
+	// RecycleBinUUID identifies the group with deleted entries, if any.
+	RecycleBinUUID string
+}
+
+type Group struct {
+	UUID    string
+	Name    string
+	Groups  []*Group
+	Entries []*Entry
+}
+
+type Entry struct {
+	// Fields holds the string fields, such as Title, UserName, Password,
+	// URL, Notes and custom attributes.
+	Fields      map[string]string
+	Attachments []Attachment
+
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+	// ExpiresAt is only set for entries with expiry enabled.
+	ExpiresAt time.Time
+}
+
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+type header struct {
+	cipherID    []byte
+	compressed  bool
+	masterSeed  []byte
+	iv          []byte
+	kdf         map[string]any
+	raw         []byte
+	rawChecksum []byte
+	rawHMAC     []byte
+}
+
+// Read decrypts a KDBX 4 database.
+func Read(r io.Reader, creds Credentials) (*Database, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database: %w", err)
+	}
+
+	hdr, body, err := readHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(hdr.raw)
+	if !bytes.Equal(checksum[:], hdr.rawChecksum) {
+		return nil, fmt.Errorf("%w: header checksum mismatch", ErrCorrupted)
+	}
+
+	compositeKey, err := creds.compositeKey()
+	if err != nil {
+		return nil, err
+	}
+
+	transformedKey, err := transformKey(hdr.kdf, compositeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	hmacKey := sha512.Sum512(concat(hdr.masterSeed, transformedKey, []byte{1}))
+
+	headerMAC := hmac.New(sha256.New, blockHMACKey(hmacKey[:], math.MaxUint64))
+	headerMAC.Write(hdr.raw)
+
+	if !hmac.Equal(headerMAC.Sum(nil), hdr.rawHMAC) {
+		return nil, ErrInvalidCredentials
+	}
+
+	ciphertext, err := readBlocks(body, hmacKey[:])
+	if err != nil {
+		return nil, err
+	}
+
+	encryptionKey := sha256.Sum256(concat(hdr.masterSeed, transformedKey))
+
+	plaintext, err := decrypt(hdr, encryptionKey[:], ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	if hdr.compressed {
+		reader, err := gzip.NewReader(bytes.NewReader(plaintext))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+
+		plaintext, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+	}
+
+	stream, binaries, content, err := readInnerHeader(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseXML(content, stream, binaries)
+}
+
+func readHeader(data []byte) (*header, []byte, error) {
+	if len(data) < 12 || binary.LittleEndian.Uint32(data[0:4]) != signature1 || binary.LittleEndian.Uint32(data[4:8]) != signature2 {
+		return nil, nil, ErrInvalidFile
+	}
+
+	if major := binary.LittleEndian.Uint16(data[10:12]); major != majorVersion {
+		return nil, nil, fmt.Errorf("%w: KDBX version %d, only version 4 is supported", ErrUnsupported, major)
+	}
+
+	hdr := &header{}
+	pos := 12
+
+	for {
+		if len(data) < pos+5 {
+			return nil, nil, fmt.Errorf("%w: truncated header", ErrCorrupted)
+		}
+
+		id := data[pos]
+		size := int(binary.LittleEndian.Uint32(data[pos+1 : pos+5]))
+		pos += 5
+
+		if size < 0 || len(data) < pos+size {
+			return nil, nil, fmt.Errorf("%w: truncated header", ErrCorrupted)
+		}
+
+		value := data[pos : pos+size]
+		pos += size
+
+		switch id {
+		case headerEnd:
+			if len(data) < pos+64 {
+				return nil, nil, fmt.Errorf("%w: truncated header", ErrCorrupted)
+			}
+
+			hdr.raw = data[:pos]
+			hdr.rawChecksum = data[pos : pos+32]
+			hdr.rawHMAC = data[pos+32 : pos+64]
+
+			if hdr.cipherID == nil || hdr.masterSeed == nil || hdr.iv == nil || hdr.kdf == nil {
+				return nil, nil, fmt.Errorf("%w: missing header fields", ErrCorrupted)
+			}
+
+			return hdr, data[pos+64:], nil
+
+		case headerCipherID:
+			hdr.cipherID = value
+
+		case headerCompression:
+			if len(value) != 4 {
+				return nil, nil, fmt.Errorf("%w: invalid compression flags", ErrCorrupted)
+			}
+
+			switch binary.LittleEndian.Uint32(value) {
+			case 0:
+			case 1:
+				hdr.compressed = true
+			default:
+				return nil, nil, fmt.Errorf("%w: unknown compression", ErrUnsupported)
+			}
+
+		case headerMasterSeed:
+			if len(value) != 32 {
+				return nil, nil, fmt.Errorf("%w: invalid master seed", ErrCorrupted)
+			}
+
+			hdr.masterSeed = value
+
+		case headerIV:
+			hdr.iv = value
+
+		case headerKDF:
+			params, err := readVariantDictionary(value)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			hdr.kdf = params
+		}
+	}
+}
+
+// readBlocks verifies and joins the HMAC protected blocks of the payload.
+func readBlocks(data, hmacKey []byte) ([]byte, error) {
+	var out []byte
+
+	for index := uint64(0); ; index++ {
+		if len(data) < 36 {
+			return nil, fmt.Errorf("%w: truncated block", ErrCorrupted)
+		}
+
+		mac := data[:32]
+		size := int(int32(binary.LittleEndian.Uint32(data[32:36])))
+
+		if size < 0 || len(data) < 36+size {
+			return nil, fmt.Errorf("%w: truncated block", ErrCorrupted)
+		}
+
+		block := data[36 : 36+size]
+
+		if !hmac.Equal(blockHMAC(hmacKey, index, data[32:36], block), mac) {
+			return nil, fmt.Errorf("%w: block %d HMAC mismatch", ErrCorrupted, index)
+		}
+
+		if size == 0 {
+			return out, nil
+		}
+
+		out = append(out, block...)
+		data = data[36+size:]
+	}
+}
+
+func blockHMACKey(hmacKey []byte, index uint64) []byte {
+	var indexBytes [8]byte
+	binary.LittleEndian.PutUint64(indexBytes[:], index)
+
+	key := sha512.Sum512(concat(indexBytes[:], hmacKey))
+
+	return key[:]
+}
+
+func blockHMAC(hmacKey []byte, index uint64, size, block []byte) []byte {
+	var indexBytes [8]byte
+	binary.LittleEndian.PutUint64(indexBytes[:], index)
+
+	mac := hmac.New(sha256.New, blockHMACKey(hmacKey, index))
+	mac.Write(indexBytes[:])
+	mac.Write(size)
+	mac.Write(block)
+
+	return mac.Sum(nil)
+}
+
+func decrypt(hdr *header, key, ciphertext []byte) ([]byte, error) {
+	switch {
+	case bytes.Equal(hdr.cipherID, cipherAES256):
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(hdr.iv) != aes.BlockSize || len(ciphertext)%aes.BlockSize != 0 || len(ciphertext) == 0 {
+			return nil, fmt.Errorf("%w: invalid AES payload", ErrCorrupted)
+		}
+
+		plaintext := make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(block, hdr.iv).CryptBlocks(plaintext, ciphertext)
+
+		padding := int(plaintext[len(plaintext)-1])
+		if padding == 0 || padding > aes.BlockSize {
+			return nil, fmt.Errorf("%w: invalid padding", ErrCorrupted)
+		}
+
+		return plaintext[:len(plaintext)-padding], nil
+
+	case bytes.Equal(hdr.cipherID, cipherChaCha20):
+		stream, err := chacha20.NewUnauthenticatedCipher(key, hdr.iv)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+
+		plaintext := make([]byte, len(ciphertext))
+		stream.XORKeyStream(plaintext, ciphertext)
+
+		return plaintext, nil
+	}
+
+	return nil, fmt.Errorf("%w: cipher %x, only AES-256 and ChaCha20 are supported", ErrUnsupported, hdr.cipherID)
+}
+
+// readInnerHeader returns the stream for protected values, the binary pool
+// and the XML document.
+func readInnerHeader(data []byte) (cipher.Stream, [][]byte, []byte, error) {
+	var (
+		streamID  uint32
+		streamKey []byte
+		binaries  [][]byte
+	)
+
+	for {
+		if len(data) < 5 {
+			return nil, nil, nil, fmt.Errorf("%w: truncated inner header", ErrCorrupted)
+		}
+
+		id := data[0]
+		size := int(binary.LittleEndian.Uint32(data[1:5]))
+
+		if size < 0 || len(data) < 5+size {
+			return nil, nil, nil, fmt.Errorf("%w: truncated inner header", ErrCorrupted)
+		}
+
+		value := data[5 : 5+size]
+		data = data[5+size:]
+
+		switch id {
+		case innerHeaderEnd:
+			stream, err := newInnerStream(streamID, streamKey)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			return stream, binaries, data, nil
+
+		case innerHeaderStreamID:
+			if len(value) != 4 {
+				return nil, nil, nil, fmt.Errorf("%w: invalid inner stream", ErrCorrupted)
+			}
+
+			streamID = binary.LittleEndian.Uint32(value)
+
+		case innerHeaderStreamKey:
+			streamKey = value
+
+		case innerHeaderBinary:
+			// The first byte holds the flags, the protection flag only
+			// matters for keeping the data in protected memory.
+			if len(value) == 0 {
+				return nil, nil, nil, fmt.Errorf("%w: invalid binary", ErrCorrupted)
+			}
+
+			binaries = append(binaries, value[1:])
+		}
+	}
+}
+
+func newInnerStream(id uint32, key []byte) (cipher.Stream, error) {
+	switch id {
+	case streamChaCha20:
+		hash := sha512.Sum512(key)
+
+		return chacha20.NewUnauthenticatedCipher(hash[:32], hash[32:44])
+
+	case streamSalsa20:
+		return newSalsa20Stream(sha256.Sum256(key)), nil
+	}
+
+	return nil, fmt.Errorf("%w: inner stream %d", ErrUnsupported, id)
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+
+	return out
+}
@@ -0,0 +1,437 @@
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/salsa20"
+)
+
+type testDatabase struct {
+	cipherID    []byte
+	kdf         map[string]any
+	innerStream uint32
+	compressed  bool
+	creds       Credentials
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+
+	return b
+}
+
+func writeVariantDictionary(params map[string]any) []byte {
+	out := []byte{0x00, 0x01}
+
+	for key, value := range params {
+		var (
+			valueType byte
+			data      []byte
+		)
+
+		switch v := value.(type) {
+		case uint32:
+			valueType, data = variantUint32, binary.LittleEndian.AppendUint32(nil, v)
+		case uint64:
+			valueType, data = variantUint64, binary.LittleEndian.AppendUint64(nil, v)
+		case []byte:
+			valueType, data = variantByteArray, v
+		}
+
+		out = append(out, valueType)
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(key)))
+		out = append(out, key...)
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(data)))
+		out = append(out, data...)
+	}
+
+	return append(out, variantEnd)
+}
+
+func headerField(id byte, data []byte) []byte {
+	out := []byte{id}
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(data)))
+
+	return append(out, data...)
+}
+
+func encodeTime(tm time.Time) string {
+	seconds := tm.Unix() - kdbxEpoch.Unix()
+
+	return base64.StdEncoding.EncodeToString(binary.LittleEndian.AppendUint64(nil, uint64(seconds)))
+}
+
+// testXML writes the document, protected values are encrypted in document
+// order like KeePass does.
+func testXML(stream cipher.Stream) string {
+	protect := func(value string) string {
+		out := []byte(value)
+		stream.XORKeyStream(out, out)
+
+		return base64.StdEncoding.EncodeToString(out)
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expires := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="utf-8" standalone="yes"?>`)
+	b.WriteString(`<KeePassFile><Meta><Generator>test</Generator><DatabaseName>Test</DatabaseName><RecycleBinUUID>AQEBAQEBAQEBAQEBAQEBAQ==</RecycleBinUUID></Meta><Root>`)
+	b.WriteString(`<Group><UUID>AAAAAAAAAAAAAAAAAAAAAA==</UUID><Name>Database</Name>`)
+	b.WriteString(`<Times><CreationTime>` + encodeTime(created) + `</CreationTime></Times>`)
+	b.WriteString(`<Entry><String><Key>Title</Key><Value>Mail</Value></String>`)
+	b.WriteString(`<String><Key>UserName</Key><Value>alice</Value></String>`)
+	b.WriteString(`<String><Key>Password</Key><Value Protected="True">` + protect("s3cret") + `</Value></String>`)
+	b.WriteString(`<String><Key>PIN</Key><Value Protected="True">` + protect("1234") + `</Value></String>`)
+	b.WriteString(`<Binary><Key>recovery.txt</Key><Value Ref="0"/></Binary>`)
+	b.WriteString(`<Times><CreationTime>` + encodeTime(created) + `</CreationTime>`)
+	b.WriteString(`<LastModificationTime>` + encodeTime(created.Add(time.Hour)) + `</LastModificationTime>`)
+	b.WriteString(`<ExpiryTime>` + encodeTime(expires) + `</ExpiryTime><Expires>True</Expires></Times>`)
+	b.WriteString(`<History><Entry><String><Key>Password</Key><Value Protected="True">` + protect("old password") + `</Value></String></Entry></History>`)
+	b.WriteString(`</Entry>`)
+	b.WriteString(`<Group><Name>Sub &amp; Group</Name><Entry><String><Key>Title</Key><Value>Bank</Value></String>`)
+	b.WriteString(`<String><Key>Password</Key><Value Protected="True">` + protect("второй") + `</Value></String>`)
+	b.WriteString(`<Times><ExpiryTime>` + encodeTime(expires) + `</ExpiryTime><Expires>False</Expires></Times>`)
+	b.WriteString(`</Entry></Group>`)
+	b.WriteString(`</Group><DeletedObjects/></Root></KeePassFile>`)
+
+	return b.String()
+}
+
+func writeDatabase(t *testing.T, db testDatabase) []byte {
+	t.Helper()
+
+	masterSeed := randomBytes(t, 32)
+
+	iv := randomBytes(t, 16)
+	if bytes.Equal(db.cipherID, cipherChaCha20) {
+		iv = randomBytes(t, 12)
+	}
+
+	compression := uint32(0)
+	if db.compressed {
+		compression = 1
+	}
+
+	header := binary.LittleEndian.AppendUint32(nil, signature1)
+	header = binary.LittleEndian.AppendUint32(header, signature2)
+	header = binary.LittleEndian.AppendUint32(header, 4<<16|1)
+	header = append(header, headerField(headerCipherID, db.cipherID)...)
+	header = append(header, headerField(headerCompression, binary.LittleEndian.AppendUint32(nil, compression))...)
+	header = append(header, headerField(headerMasterSeed, masterSeed)...)
+	header = append(header, headerField(headerIV, iv)...)
+	header = append(header, headerField(headerKDF, writeVariantDictionary(db.kdf))...)
+	header = append(header, headerField(headerEnd, []byte("\r\n\r\n"))...)
+
+	compositeKey, err := db.creds.compositeKey()
+	require.NoError(t, err)
+
+	transformedKey, err := transformKey(db.kdf, compositeKey)
+	require.NoError(t, err)
+
+	hmacKey := sha512.Sum512(concat(masterSeed, transformedKey, []byte{1}))
+	encryptionKey := sha256.Sum256(concat(masterSeed, transformedKey))
+
+	checksum := sha256.Sum256(header)
+	headerMAC := hmac.New(sha256.New, blockHMACKey(hmacKey[:], math.MaxUint64))
+	headerMAC.Write(header)
+
+	out := append(bytes.Clone(header), checksum[:]...)
+	out = append(out, headerMAC.Sum(nil)...)
+
+	streamKey := randomBytes(t, 64)
+
+	inner := headerField(innerHeaderStreamID, binary.LittleEndian.AppendUint32(nil, db.innerStream))
+	inner = append(inner, headerField(innerHeaderStreamKey, streamKey)...)
+	inner = append(inner, headerField(innerHeaderBinary, append([]byte{1}, "recovery codes"...))...)
+	inner = append(inner, headerField(innerHeaderEnd, nil)...)
+
+	stream, err := newInnerStream(db.innerStream, streamKey)
+	require.NoError(t, err)
+
+	plaintext := append(inner, testXML(stream)...)
+
+	if db.compressed {
+		var buf bytes.Buffer
+
+		writer := gzip.NewWriter(&buf)
+		_, err := writer.Write(plaintext)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		plaintext = buf.Bytes()
+	}
+
+	var ciphertext []byte
+
+	if bytes.Equal(db.cipherID, cipherChaCha20) {
+		stream, err := chacha20.NewUnauthenticatedCipher(encryptionKey[:], iv)
+		require.NoError(t, err)
+
+		ciphertext = make([]byte, len(plaintext))
+		stream.XORKeyStream(ciphertext, plaintext)
+	} else {
+		block, err := aes.NewCipher(encryptionKey[:])
+		require.NoError(t, err)
+
+		padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+		plaintext = append(plaintext, bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+		ciphertext = make([]byte, len(plaintext))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+	}
+
+	// Small blocks to cover several HMAC blocks.
+	for index := uint64(0); ; index++ {
+		size := min(len(ciphertext), 256)
+		sizeBytes := binary.LittleEndian.AppendUint32(nil, uint32(size))
+
+		out = append(out, blockHMAC(hmacKey[:], index, sizeBytes, ciphertext[:size])...)
+		out = append(out, sizeBytes...)
+		out = append(out, ciphertext[:size]...)
+
+		if size == 0 {
+			return out
+		}
+
+		ciphertext = ciphertext[size:]
+	}
+}
+
+func argon2Params(t *testing.T, uuid []byte) map[string]any {
+	return map[string]any{
+		"$UUID": uuid,
+		"S":     randomBytes(t, 32),
+		"P":     uint32(2),
+		"M":     uint64(64 * 1024),
+		"I":     uint64(2),
+		"V":     uint32(argon2Version),
+	}
+}
+
+func TestRead(t *testing.T) {
+	password := Credentials{Password: []byte("password")}
+
+	tests := []struct {
+		name string
+		db   testDatabase
+	}{
+		{"AES Argon2d", testDatabase{cipherAES256, argon2Params(t, kdfArgon2d), streamChaCha20, true, password}},
+		{"ChaCha20 Argon2id", testDatabase{cipherChaCha20, argon2Params(t, kdfArgon2id), streamChaCha20, false, password}},
+		{"AES-KDF Salsa20", testDatabase{cipherAES256, map[string]any{"$UUID": kdfAES, "S": randomBytes(t, 32), "R": uint64(1000)}, streamSalsa20, true, password}},
+		{"key file", testDatabase{cipherChaCha20, argon2Params(t, kdfArgon2d), streamChaCha20, true, Credentials{Password: []byte("password"), KeyFile: []byte("any file content")}}},
+		{"key file only", testDatabase{cipherAES256, argon2Params(t, kdfArgon2d), streamChaCha20, true, Credentials{KeyFile: randomBytes(t, 32)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := writeDatabase(t, tt.db)
+
+			db, err := Read(bytes.NewReader(data), tt.db.creds)
+			require.NoError(t, err)
+
+			root := db.Root
+			assert.Equal(t, "Database", root.Name)
+			assert.Equal(t, "AAAAAAAAAAAAAAAAAAAAAA==", root.UUID)
+			assert.Equal(t, "AQEBAQEBAQEBAQEBAQEBAQ==", db.RecycleBinUUID)
+			require.Len(t, root.Entries, 1)
+
+			mail := root.Entries[0]
+			assert.Equal(t, map[string]string{
+				"Title":    "Mail",
+				"UserName": "alice",
+				"Password": "s3cret",
+				"PIN":      "1234",
+			}, mail.Fields)
+			assert.Equal(t, []Attachment{{Name: "recovery.txt", Data: []byte("recovery codes")}}, mail.Attachments)
+			assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), mail.CreatedAt)
+			assert.Equal(t, time.Date(2024, 1, 2, 4, 4, 5, 0, time.UTC), mail.ModifiedAt)
+			assert.Equal(t, time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC), mail.ExpiresAt)
+
+			require.Len(t, root.Groups, 1)
+
+			sub := root.Groups[0]
+			assert.Equal(t, "Sub & Group", sub.Name)
+			require.Len(t, sub.Entries, 1)
+			assert.Equal(t, "второй", sub.Entries[0].Fields["Password"])
+			assert.True(t, sub.Entries[0].ExpiresAt.IsZero())
+
+			_, err = Read(bytes.NewReader(data), Credentials{Password: []byte("wrong")})
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestReadKeePassFiles(t *testing.T) {
+	password := []byte("abcdefg12345678")
+
+	keyFile, err := os.ReadFile("testdata/keepass-keyfile.key")
+	require.NoError(t, err)
+
+	tests := []struct {
+		file  string
+		creds Credentials
+	}{
+		{"keepass-aes-argon2d.kdbx", Credentials{Password: password}},
+		{"keepass-chacha20-argon2d.kdbx", Credentials{Password: password}},
+		{"keepass-keyfile.kdbx", Credentials{Password: password, KeyFile: keyFile}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			require.NoError(t, err)
+
+			db, err := Read(bytes.NewReader(data), tt.creds)
+			require.NoError(t, err)
+
+			root := db.Root
+			assert.Equal(t, "example", root.Name)
+			assert.Equal(t, "V0QTx/36QUGzntXSNvQFNw==", root.UUID)
+			assert.Equal(t, "I9x+dYTouUW3OsEL4W4e2Q==", db.RecycleBinUUID)
+			require.Len(t, root.Groups, 7)
+
+			general := root.Groups[0]
+			assert.Equal(t, "General", general.Name)
+			require.Len(t, general.Entries, 2)
+
+			sample := general.Entries[0]
+			assert.Equal(t, map[string]string{
+				"Title":    "Sample Entry",
+				"UserName": "User Name",
+				"Password": "Password",
+				"URL":      "http://keepass.info/",
+				"Notes":    "Notes",
+			}, sample.Fields)
+			assert.Equal(t, time.Date(2015, 6, 19, 15, 38, 42, 0, time.UTC), sample.CreatedAt)
+			assert.Equal(t, time.Date(2015, 6, 19, 15, 38, 42, 0, time.UTC), sample.ModifiedAt)
+			assert.Equal(t, "AnotherPassword", general.Entries[1].Fields["Password"])
+
+			windows := root.Groups[1]
+			assert.Equal(t, "Windows", windows.Name)
+			require.NotEmpty(t, windows.Entries)
+			assert.Equal(t, "File test", windows.Entries[0].Fields["Title"])
+			assert.Equal(t, []Attachment{{Name: "example.txt", Data: []byte("Hello world")}}, windows.Entries[0].Attachments)
+
+			_, err = Read(bytes.NewReader(data), Credentials{Password: []byte("wrong")})
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestReadCorrupted(t *testing.T) {
+	creds := Credentials{Password: []byte("password")}
+	data := writeDatabase(t, testDatabase{cipherAES256, argon2Params(t, kdfArgon2d), streamChaCha20, true, creds})
+
+	corrupted := bytes.Clone(data)
+	corrupted[len(corrupted)-100] ^= 1
+
+	_, err := Read(bytes.NewReader(corrupted), creds)
+	assert.ErrorIs(t, err, ErrCorrupted)
+
+	_, err = Read(bytes.NewReader(data[:len(data)-40]), creds)
+	assert.ErrorIs(t, err, ErrCorrupted)
+
+	_, err = Read(strings.NewReader("not a database"), creds)
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	kdbx3 := bytes.Clone(data)
+	binary.LittleEndian.PutUint16(kdbx3[10:12], 3)
+
+	_, err = Read(bytes.NewReader(kdbx3), creds)
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestArgon2d(t *testing.T) {
+	// Test vector from RFC 9106, section 5.1.
+	key := deriveKey(
+		bytes.Repeat([]byte{0x01}, 32),
+		bytes.Repeat([]byte{0x02}, 16),
+		bytes.Repeat([]byte{0x03}, 8),
+		bytes.Repeat([]byte{0x04}, 12),
+		3, 32, 4, 32,
+	)
+	assert.Equal(t, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb", hex.EncodeToString(key))
+}
+
+func TestKeyFileKey(t *testing.T) {
+	raw := randomBytes(t, 32)
+
+	key, err := keyFileKey(raw)
+	require.NoError(t, err)
+	assert.Equal(t, raw, key)
+
+	key, err = keyFileKey([]byte(hex.EncodeToString(raw)))
+	require.NoError(t, err)
+	assert.Equal(t, raw, key)
+
+	v1 := `<?xml version="1.0" encoding="utf-8"?><KeyFile><Meta><Version>1.00</Version></Meta><Key><Data>` + base64.StdEncoding.EncodeToString(raw) + `</Data></Key></KeyFile>`
+
+	key, err = keyFileKey([]byte(v1))
+	require.NoError(t, err)
+	assert.Equal(t, raw, key)
+
+	checksum := sha256.Sum256(raw)
+	hexKey := strings.ToUpper(hex.EncodeToString(raw))
+	v2 := `<?xml version="1.0" encoding="utf-8"?><KeyFile><Meta><Version>2.0</Version></Meta><Key><Data Hash="` + hex.EncodeToString(checksum[:4]) + `">` + hexKey[:32] + "\n\t" + hexKey[32:] + `</Data></Key></KeyFile>`
+
+	key, err = keyFileKey([]byte(v2))
+	require.NoError(t, err)
+	assert.Equal(t, raw, key)
+
+	_, err = keyFileKey([]byte(strings.Replace(v2, hex.EncodeToString(checksum[:4]), "00000000", 1)))
+	assert.Error(t, err)
+
+	other := []byte("some other file")
+	hash := sha256.Sum256(other)
+
+	key, err = keyFileKey(other)
+	require.NoError(t, err)
+	assert.Equal(t, hash[:], key)
+}
+
+func TestSalsa20Stream(t *testing.T) {
+	var key [32]byte
+	copy(key[:], randomBytes(t, 32))
+
+	message := randomBytes(t, 300)
+
+	expected := make([]byte, len(message))
+	salsa20.XORKeyStream(expected, message, salsa20IV[:], &key)
+
+	stream := newSalsa20Stream(key)
+	actual := make([]byte, 0, len(message))
+
+	// Uneven chunks like the protected values of a database.
+	for _, size := range []int{5, 64, 1, 100, 130} {
+		chunk := make([]byte, size)
+		stream.XORKeyStream(chunk, message[len(actual):len(actual)+size])
+		actual = append(actual, chunk...)
+	}
+
+	assert.Equal(t, expected, actual)
+}
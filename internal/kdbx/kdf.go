@@ -0,0 +1,263 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var (
+	kdfAES      = []byte{0xc9, 0xd9, 0xf3, 0x9a, 0x62, 0x8a, 0x44, 0x60, 0xbf, 0x74, 0x0d, 0x08, 0xc1, 0x8a, 0x4f, 0xea}
+	kdfArgon2d  = []byte{0xef, 0x63, 0x6d, 0xdf, 0x8c, 0x29, 0x44, 0x4b, 0x91, 0xf7, 0xa9, 0xa4, 0x03, 0xe3, 0x0a, 0x0c}
+	kdfArgon2id = []byte{0x9e, 0x29, 0x8b, 0x19, 0x56, 0xdb, 0x47, 0x73, 0xb2, 0x3d, 0xfc, 0x3e, 0xc6, 0xf0, 0xa1, 0xe6}
+)
+
+// Value types of a variant dictionary.
+const (
+	variantEnd       = 0x00
+	variantUint32    = 0x04
+	variantUint64    = 0x05
+	variantBool      = 0x08
+	variantInt32     = 0x0c
+	variantInt64     = 0x0d
+	variantString    = 0x18
+	variantByteArray = 0x42
+)
+
+// readVariantDictionary parses the typed key-value list used for the KDF
+// parameters.
+func readVariantDictionary(data []byte) (map[string]any, error) {
+	if len(data) < 2 || data[1] != 1 {
+		return nil, fmt.Errorf("%w: unknown KDF parameters version", ErrUnsupported)
+	}
+
+	params := make(map[string]any)
+	data = data[2:]
+
+	for {
+		if len(data) < 1 {
+			return nil, fmt.Errorf("%w: truncated KDF parameters", ErrCorrupted)
+		}
+
+		valueType := data[0]
+		if valueType == variantEnd {
+			return params, nil
+		}
+
+		if len(data) < 5 {
+			return nil, fmt.Errorf("%w: truncated KDF parameters", ErrCorrupted)
+		}
+
+		keyLen := int(binary.LittleEndian.Uint32(data[1:5]))
+		if keyLen < 0 || len(data) < 5+keyLen+4 {
+			return nil, fmt.Errorf("%w: truncated KDF parameters", ErrCorrupted)
+		}
+
+		key := string(data[5 : 5+keyLen])
+		data = data[5+keyLen:]
+
+		valueLen := int(binary.LittleEndian.Uint32(data[0:4]))
+		if valueLen < 0 || len(data) < 4+valueLen {
+			return nil, fmt.Errorf("%w: truncated KDF parameters", ErrCorrupted)
+		}
+
+		value := data[4 : 4+valueLen]
+		data = data[4+valueLen:]
+
+		var err error
+
+		switch valueType {
+		case variantUint32, variantInt32:
+			if len(value) != 4 {
+				err = fmt.Errorf("%w: invalid KDF parameter %s", ErrCorrupted, key)
+				break
+			}
+
+			params[key] = binary.LittleEndian.Uint32(value)
+
+		case variantUint64, variantInt64:
+			if len(value) != 8 {
+				err = fmt.Errorf("%w: invalid KDF parameter %s", ErrCorrupted, key)
+				break
+			}
+
+			params[key] = binary.LittleEndian.Uint64(value)
+
+		case variantBool:
+			params[key] = len(value) == 1 && value[0] != 0
+
+		case variantString:
+			params[key] = string(value)
+
+		case variantByteArray:
+			params[key] = value
+
+		default:
+			err = fmt.Errorf("%w: unknown KDF parameter type 0x%02x", ErrUnsupported, valueType)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func transformKey(params map[string]any, compositeKey []byte) ([]byte, error) {
+	uuid, _ := params["$UUID"].([]byte)
+
+	switch {
+	case bytes.Equal(uuid, kdfAES):
+		seed, _ := params["S"].([]byte)
+		rounds, ok := params["R"].(uint64)
+
+		if len(seed) != 32 || !ok {
+			return nil, fmt.Errorf("%w: invalid AES-KDF parameters", ErrCorrupted)
+		}
+
+		block, err := aes.NewCipher(seed)
+		if err != nil {
+			return nil, err
+		}
+
+		key := bytes.Clone(compositeKey)
+
+		for i := uint64(0); i < rounds; i++ {
+			block.Encrypt(key[:16], key[:16])
+			block.Encrypt(key[16:], key[16:])
+		}
+
+		transformed := sha256.Sum256(key)
+
+		return transformed[:], nil
+
+	case bytes.Equal(uuid, kdfArgon2d), bytes.Equal(uuid, kdfArgon2id):
+		salt, _ := params["S"].([]byte)
+		parallelism, okP := params["P"].(uint32)
+		memory, okM := params["M"].(uint64)
+		iterations, okI := params["I"].(uint64)
+		version, okV := params["V"].(uint32)
+
+		if !okP || !okM || !okI || !okV || len(salt) == 0 {
+			return nil, fmt.Errorf("%w: invalid Argon2 parameters", ErrCorrupted)
+		}
+
+		if version != argon2Version {
+			return nil, fmt.Errorf("%w: Argon2 version 0x%x", ErrUnsupported, version)
+		}
+
+		if secret, _ := params["K"].([]byte); len(secret) > 0 {
+			return nil, fmt.Errorf("%w: Argon2 secret key", ErrUnsupported)
+		}
+
+		if data, _ := params["A"].([]byte); len(data) > 0 {
+			return nil, fmt.Errorf("%w: Argon2 associated data", ErrUnsupported)
+		}
+
+		if parallelism < 1 || parallelism > math.MaxUint8 || iterations < 1 || iterations > math.MaxUint32 || memory/1024 > math.MaxUint32 {
+			return nil, fmt.Errorf("%w: Argon2 parameters out of range", ErrUnsupported)
+		}
+
+		if bytes.Equal(uuid, kdfArgon2id) {
+			return argon2.IDKey(compositeKey, salt, uint32(iterations), uint32(memory/1024), uint8(parallelism), 32), nil
+		}
+
+		return argon2dKey(compositeKey, salt, uint32(iterations), uint32(memory/1024), uint8(parallelism), 32), nil
+	}
+
+	return nil, fmt.Errorf("%w: KDF %x", ErrUnsupported, uuid)
+}
+
+func (c Credentials) compositeKey() ([]byte, error) {
+	if c.Password == nil && c.KeyFile == nil {
+		return nil, fmt.Errorf("a password or key file is required")
+	}
+
+	hash := sha256.New()
+
+	if c.Password != nil {
+		password := sha256.Sum256(c.Password)
+		hash.Write(password[:])
+	}
+
+	if c.KeyFile != nil {
+		key, err := keyFileKey(c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		hash.Write(key)
+	}
+
+	return hash.Sum(nil), nil
+}
+
+type keyFile struct {
+	Meta struct {
+		Version string `xml:"Version"`
+	} `xml:"Meta"`
+	Key struct {
+		Data struct {
+			Hash  string `xml:"Hash,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Data"`
+	} `xml:"Key"`
+}
+
+// keyFileKey supports the XML key files in version 1 and 2, 32 byte binary
+// files and 64 character hex files. Any other file is hashed.
+func keyFileKey(data []byte) ([]byte, error) {
+	var kf keyFile
+
+	if bytes.Contains(data, []byte("<KeyFile>")) && xml.Unmarshal(data, &kf) == nil {
+		value := strings.Join(strings.Fields(kf.Key.Data.Value), "")
+
+		switch {
+		case strings.HasPrefix(kf.Meta.Version, "1."):
+			key, err := decodeBase64(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key file: %w", err)
+			}
+
+			return key, nil
+
+		case strings.HasPrefix(kf.Meta.Version, "2."):
+			key, err := hex.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key file: %w", err)
+			}
+
+			if kf.Key.Data.Hash != "" {
+				checksum := sha256.Sum256(key)
+				if !strings.EqualFold(hex.EncodeToString(checksum[:4]), kf.Key.Data.Hash) {
+					return nil, fmt.Errorf("invalid key file: checksum mismatch")
+				}
+			}
+
+			return key, nil
+		}
+
+		return nil, fmt.Errorf("invalid key file: unknown version %q", kf.Meta.Version)
+	}
+
+	if len(data) == 32 {
+		return data, nil
+	}
+
+	if len(data) == 64 {
+		if key, err := hex.DecodeString(string(data)); err == nil {
+			return key, nil
+		}
+	}
+
+	hash := sha256.Sum256(data)
+
+	return hash[:], nil
+}
@@ -0,0 +1,46 @@
+package kdbx
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+// salsa20IV is the fixed nonce KeePass uses for the Salsa20 inner stream.
+var salsa20IV = [8]byte{0xe8, 0x30, 0x09, 0x4b, 0x97, 0x20, 0x5d, 0x2a}
+
+// salsa20Stream is a Salsa20 cipher.Stream, x/crypto only provides one shot
+// encryption.
+type salsa20Stream struct {
+	key     [32]byte
+	counter uint64
+	block   [64]byte
+	used    int
+}
+
+func newSalsa20Stream(key [32]byte) *salsa20Stream {
+	return &salsa20Stream{
+		key:  key,
+		used: len(salsa20Stream{}.block),
+	}
+}
+
+func (s *salsa20Stream) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if s.used == len(s.block) {
+			var input [16]byte
+
+			copy(input[:8], salsa20IV[:])
+			binary.LittleEndian.PutUint64(input[8:], s.counter)
+
+			s.block = [64]byte{}
+			salsa.XORKeyStream(s.block[:], s.block[:], &input, &s.key)
+
+			s.counter++
+			s.used = 0
+		}
+
+		dst[i] = src[i] ^ s.block[s.used]
+		s.used++
+	}
+}
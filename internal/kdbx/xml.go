@@ -0,0 +1,287 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// kdbxEpoch is the start of the time values in KDBX 4, which are stored as
+// seconds since year 1.
+var kdbxEpoch = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// xmlParser walks the XML document token by token. Protected values are
+// encrypted with one stream in document order, including the values of
+// history entries, so they have to be decrypted in the same order.
+type xmlParser struct {
+	stream   cipher.Stream
+	binaries [][]byte
+
+	path      []string
+	protected []bool
+	text      strings.Builder
+
+	db      *Database
+	groups  []*Group
+	entries []*entryState
+
+	key, value string
+	binaryRef  int
+	expires    bool
+	expiryTime time.Time
+}
+
+type entryState struct {
+	entry   *Entry
+	history bool
+}
+
+func parseXML(content []byte, stream cipher.Stream, binaries [][]byte) (*Database, error) {
+	p := &xmlParser{
+		stream:   stream,
+		binaries: binaries,
+		db:       &Database{},
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			p.start(t)
+
+		case xml.CharData:
+			p.text.Write(t)
+
+		case xml.EndElement:
+			if err := p.end(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if p.db.Root == nil {
+		return nil, fmt.Errorf("%w: no root group", ErrCorrupted)
+	}
+
+	return p.db, nil
+}
+
+// ancestor returns the name of the element n levels above the current one.
+func (p *xmlParser) ancestor(n int) string {
+	if len(p.path) <= n {
+		return ""
+	}
+
+	return p.path[len(p.path)-1-n]
+}
+
+func (p *xmlParser) start(t xml.StartElement) {
+	name := t.Name.Local
+	parent := ""
+
+	if len(p.path) > 0 {
+		parent = p.path[len(p.path)-1]
+	}
+
+	protected := false
+
+	for _, attr := range t.Attr {
+		switch {
+		case attr.Name.Local == "Protected" && strings.EqualFold(attr.Value, "true"):
+			protected = true
+
+		case attr.Name.Local == "Ref" && name == "Value" && parent == "Binary":
+			p.binaryRef, _ = strconv.Atoi(attr.Value)
+		}
+	}
+
+	p.path = append(p.path, name)
+	p.protected = append(p.protected, protected)
+	p.text.Reset()
+
+	switch {
+	case name == "Group" && (parent == "Root" || parent == "Group"):
+		group := &Group{}
+
+		if len(p.groups) > 0 {
+			current := p.groups[len(p.groups)-1]
+			current.Groups = append(current.Groups, group)
+		} else if p.db.Root == nil {
+			p.db.Root = group
+		}
+
+		p.groups = append(p.groups, group)
+
+	case name == "Entry" && (parent == "Group" || parent == "History"):
+		p.entries = append(p.entries, &entryState{
+			entry:   &Entry{Fields: make(map[string]string)},
+			history: parent == "History",
+		})
+
+	case name == "String" || name == "Binary":
+		p.key, p.value, p.binaryRef = "", "", -1
+
+	case name == "Times":
+		p.expires, p.expiryTime = false, time.Time{}
+	}
+}
+
+func (p *xmlParser) end() error {
+	name, parent, grandparent := p.ancestor(0), p.ancestor(1), p.ancestor(2)
+	protected := p.protected[len(p.protected)-1]
+
+	value := p.text.String()
+	p.text.Reset()
+
+	if protected {
+		decrypted, err := p.unprotect(value)
+		if err != nil {
+			return err
+		}
+
+		value = decrypted
+	}
+
+	p.path = p.path[:len(p.path)-1]
+	p.protected = p.protected[:len(p.protected)-1]
+
+	var entry *Entry
+	if len(p.entries) > 0 {
+		entry = p.entries[len(p.entries)-1].entry
+	}
+
+	switch {
+	case name == "Name" && parent == "Group" && len(p.groups) > 0:
+		p.groups[len(p.groups)-1].Name = value
+
+	case name == "UUID" && parent == "Group" && len(p.groups) > 0:
+		p.groups[len(p.groups)-1].UUID = value
+
+	case name == "RecycleBinUUID" && parent == "Meta":
+		p.db.RecycleBinUUID = value
+
+	case (name == "Key" || name == "Value") && (parent == "String" || parent == "Binary"):
+		if name == "Key" {
+			p.key = value
+		} else {
+			p.value = value
+		}
+
+	case name == "String" && parent == "Entry" && entry != nil:
+		entry.Fields[p.key] = p.value
+
+	case name == "Binary" && parent == "Entry" && entry != nil:
+		if p.binaryRef < 0 || p.binaryRef >= len(p.binaries) {
+			return fmt.Errorf("%w: invalid attachment reference", ErrCorrupted)
+		}
+
+		entry.Attachments = append(entry.Attachments, Attachment{
+			Name: p.key,
+			Data: p.binaries[p.binaryRef],
+		})
+
+	case parent == "Times" && grandparent == "Entry" && entry != nil:
+		if err := p.setTime(entry, name, value); err != nil {
+			return err
+		}
+
+	case name == "Times" && parent == "Entry" && entry != nil && p.expires:
+		entry.ExpiresAt = p.expiryTime
+
+	case name == "Entry" && len(p.entries) > 0:
+		state := p.entries[len(p.entries)-1]
+		p.entries = p.entries[:len(p.entries)-1]
+
+		if !state.history && len(p.groups) > 0 {
+			group := p.groups[len(p.groups)-1]
+			group.Entries = append(group.Entries, state.entry)
+		}
+
+	case name == "Group" && len(p.groups) > 0 && (parent == "Root" || parent == "Group"):
+		p.groups = p.groups[:len(p.groups)-1]
+	}
+
+	return nil
+}
+
+func (p *xmlParser) setTime(entry *Entry, name, value string) error {
+	if name == "Expires" {
+		p.expires = strings.EqualFold(value, "true")
+		return nil
+	}
+
+	var target *time.Time
+
+	switch name {
+	case "CreationTime":
+		target = &entry.CreatedAt
+	case "LastModificationTime":
+		target = &entry.ModifiedAt
+	case "ExpiryTime":
+		target = &p.expiryTime
+	default:
+		return nil
+	}
+
+	parsed, err := parseTime(value)
+	if err != nil {
+		return err
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+// parseTime reads the base64 encoded seconds of KDBX 4 and, for databases
+// converted from older versions, ISO 8601 dates.
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.UTC(), nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(raw) != 8 {
+		return time.Time{}, fmt.Errorf("%w: invalid time %q", ErrCorrupted, value)
+	}
+
+	seconds := int64(binary.LittleEndian.Uint64(raw))
+
+	return time.Unix(kdbxEpoch.Unix()+seconds, 0).UTC(), nil
+}
+
+func (p *xmlParser) unprotect(value string) (string, error) {
+	raw, err := decodeBase64(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid protected value", ErrCorrupted)
+	}
+
+	p.stream.XORKeyStream(raw, raw)
+
+	return string(raw), nil
+}
+
+func decodeBase64(value string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+}
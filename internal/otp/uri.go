@@ -76,3 +76,17 @@ func (u *URI) ToTOTP() *TOTP {
 		Period: u.Period,
 	}
 }
+
+// FormatTOTPURI builds an otpauth:// URI for a TOTP secret. Zero digits or
+// period use the defaults.
+func FormatTOTPURI(label, secret string, digits, period int) string {
+	if digits == 0 {
+		digits = DefaultDigits
+	}
+
+	if period == 0 {
+		period = DefaultPeriod
+	}
+
+	return fmt.Sprintf("otpauth://totp/%s?secret=%s&digits=%d&period=%d", label, secret, digits, period)
+}
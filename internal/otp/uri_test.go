@@ -94,3 +94,14 @@ func TestURI_ToTOTP(t *testing.T) {
 	assert.Equal(t, 8, totp.Digits)
 	assert.Equal(t, 60, totp.Period)
 }
+
+func TestFormatTOTPURI(t *testing.T) {
+	uri := FormatTOTPURI("/mail", "JBSWY3DPEHPK3PXP", 0, 0)
+	assert.Equal(t, "otpauth://totp//mail?secret=JBSWY3DPEHPK3PXP&digits=6&period=30", uri)
+
+	parsed, err := ParseURI(FormatTOTPURI("mail", "JBSWY3DPEHPK3PXP", 8, 60))
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", parsed.Secret)
+	assert.Equal(t, 8, parsed.Digits)
+	assert.Equal(t, 60, parsed.Period)
+}
@@ -30,9 +30,61 @@ func GenerateCredential(rpID, userID, userName string) (*Credential, error) {
 		return nil, fmt.Errorf("failed to generate key: %w", err)
 	}
 
+	privateKeyPEM, publicKeyPEM, err := encodeKeyPair(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialID := make([]byte, 32)
+	if _, err := rand.Read(credentialID); err != nil {
+		return nil, fmt.Errorf("failed to generate credential ID: %w", err)
+	}
+
+	return &Credential{
+		ID:            base64.RawURLEncoding.EncodeToString(credentialID),
+		PrivateKeyPEM: privateKeyPEM,
+		PublicKeyPEM:  publicKeyPEM,
+		RPID:          rpID,
+		UserID:        userID,
+		UserName:      userName,
+		SignCount:     0,
+		CreatedAt:     time.Now().UTC(),
+	}, nil
+}
+
+// ImportPKCS8 creates a credential from a P-256 private key in PKCS #8 DER
+// form, as used by the exports of other password managers.
+func ImportPKCS8(der []byte, id, rpID, userID, userName string) (*Credential, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	privateKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok || privateKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("only P-256 ECDSA keys are supported")
+	}
+
+	privateKeyPEM, publicKeyPEM, err := encodeKeyPair(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:            id,
+		PrivateKeyPEM: privateKeyPEM,
+		PublicKeyPEM:  publicKeyPEM,
+		RPID:          rpID,
+		UserID:        userID,
+		UserName:      userName,
+		CreatedAt:     time.Now().UTC(),
+	}, nil
+}
+
+func encodeKeyPair(privateKey *ecdsa.PrivateKey) (string, string, error) {
 	privateKeyBytes, err := x509.MarshalECPrivateKey(privateKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+		return "", "", fmt.Errorf("failed to marshal private key: %w", err)
 	}
 
 	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
@@ -42,7 +94,7 @@ func GenerateCredential(rpID, userID, userName string) (*Credential, error) {
 
 	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+		return "", "", fmt.Errorf("failed to marshal public key: %w", err)
 	}
 
 	publicKeyPEM := pem.EncodeToMemory(&pem.Block{
@@ -50,21 +102,7 @@ func GenerateCredential(rpID, userID, userName string) (*Credential, error) {
 		Bytes: publicKeyBytes,
 	})
 
-	credentialID := make([]byte, 32)
-	if _, err := rand.Read(credentialID); err != nil {
-		return nil, fmt.Errorf("failed to generate credential ID: %w", err)
-	}
-
-	return &Credential{
-		ID:            base64.RawURLEncoding.EncodeToString(credentialID),
-		PrivateKeyPEM: string(privateKeyPEM),
-		PublicKeyPEM:  string(publicKeyPEM),
-		RPID:          rpID,
-		UserID:        userID,
-		UserName:      userName,
-		SignCount:     0,
-		CreatedAt:     time.Now().UTC(),
-	}, nil
+	return string(privateKeyPEM), string(publicKeyPEM), nil
 }
 
 func (c *Credential) GetPrivateKey() (*ecdsa.PrivateKey, error) {
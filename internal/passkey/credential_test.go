@@ -1,6 +1,10 @@
 package passkey
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -87,3 +91,40 @@ func TestCredential_InvalidSignatureLength(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid signature length")
 }
+
+func TestImportPKCS8(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	cred, err := ImportPKCS8(der, "cred-id", "example.com", "user123", "alice")
+	require.NoError(t, err)
+
+	assert.Equal(t, "cred-id", cred.ID)
+	assert.Equal(t, "example.com", cred.RPID)
+
+	imported, err := cred.GetPrivateKey()
+	require.NoError(t, err)
+	assert.True(t, privateKey.Equal(imported))
+
+	signature, err := cred.Sign([]byte("challenge"))
+	require.NoError(t, err)
+
+	valid, err := cred.Verify([]byte("challenge"), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err = x509.MarshalPKCS8PrivateKey(otherKey)
+	require.NoError(t, err)
+
+	_, err = ImportPKCS8(der, "cred-id", "example.com", "user123", "alice")
+	assert.Error(t, err)
+
+	_, err = ImportPKCS8([]byte("invalid"), "cred-id", "example.com", "user123", "alice")
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	DefaultWords     = 6
+	DefaultSeparator = "-"
+
+	digitSet = "0123456789"
+
+	minWordListSize = 2
+)
+
+// effLargeWordList is the EFF large word list for five dice:
+// https://www.eff.org/files/2016/07/18/eff_large_wordlist.txt
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordList string
+
+var effWords = sync.OnceValue(func() []string {
+	words, _ := ParseWordList(strings.NewReader(effLargeWordList))
+
+	return words
+})
+
+var ErrWordListTooShort = errors.New("word list is too short")
+
+type PassphraseOptions struct {
+	Words      int
+	Separator  string
+	Capitalize bool
+	// Digits is the number of random digits appended to random words.
+	Digits int
+	// WordList defaults to the EFF large word list.
+	WordList []string
+}
+
+// EFFWordList returns the embedded EFF large word list.
+func EFFWordList() []string {
+	return effWords()
+}
+
+// ParseWordList reads one word per line. Lines in the diceware format with
+// the dice rolls before the word are accepted too, duplicates are dropped.
+func ParseWordList(r io.Reader) ([]string, error) {
+	var words []string
+
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		word := fields[len(fields)-1]
+		if seen[word] {
+			continue
+		}
+
+		seen[word] = true
+		words = append(words, word)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read word list: %w", err)
+	}
+
+	if len(words) < minWordListSize {
+		return nil, fmt.Errorf("%w: %d words", ErrWordListTooShort, len(words))
+	}
+
+	return words, nil
+}
+
+func (o PassphraseOptions) normalize() (PassphraseOptions, error) {
+	if o.Words <= 0 {
+		o.Words = DefaultWords
+	}
+
+	if o.Digits < 0 {
+		o.Digits = 0
+	}
+
+	if o.WordList == nil {
+		o.WordList = EFFWordList()
+	}
+
+	if len(o.WordList) < minWordListSize {
+		return o, fmt.Errorf("%w: %d words", ErrWordListTooShort, len(o.WordList))
+	}
+
+	return o, nil
+}
+
+// GeneratePassphrase picks words uniformly from the word list with crypto/rand.
+func GeneratePassphrase(opts PassphraseOptions) (string, error) {
+	opts, err := opts.normalize()
+	if err != nil {
+		return "", err
+	}
+
+	words := make([]string, opts.Words)
+
+	for i := range words {
+		n, err := randomInt(len(opts.WordList))
+		if err != nil {
+			return "", err
+		}
+
+		words[i] = opts.WordList[n]
+
+		if opts.Capitalize {
+			words[i] = capitalize(words[i])
+		}
+	}
+
+	for i := 0; i < opts.Digits; i++ {
+		n, err := randomInt(len(words))
+		if err != nil {
+			return "", err
+		}
+
+		digit, err := randomChar(digitSet)
+		if err != nil {
+			return "", err
+		}
+
+		words[n] += string(digit)
+	}
+
+	return strings.Join(words, opts.Separator), nil
+}
+
+// PassphraseEntropy returns the entropy in bits of a passphrase generated
+// with the same options. The positions of injected digits are not counted,
+// so the result is a lower bound.
+func PassphraseEntropy(opts PassphraseOptions) (float64, error) {
+	opts, err := opts.normalize()
+	if err != nil {
+		return 0, err
+	}
+
+	bits := float64(opts.Words) * math.Log2(float64(len(opts.WordList)))
+	bits += float64(opts.Digits) * math.Log2(float64(len(digitSet)))
+
+	return bits, nil
+}
+
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	if r == utf8.RuneError {
+		return word
+	}
+
+	return string(unicode.ToUpper(r)) + word[size:]
+}
@@ -0,0 +1,146 @@
+package password
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEFFWordList(t *testing.T) {
+	words := EFFWordList()
+
+	if len(words) != 7776 {
+		t.Fatalf("expected 7776 words, got %d", len(words))
+	}
+
+	if words[0] != "abacus" || words[len(words)-1] != "zoom" {
+		t.Errorf("unexpected word list bounds: %s, %s", words[0], words[len(words)-1])
+	}
+}
+
+func TestParseWordList(t *testing.T) {
+	words, err := ParseWordList(strings.NewReader("# comment\n11111\tapple\nbanana\n\napple\ncherry\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"apple", "banana", "cherry"}
+	if strings.Join(words, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, words)
+	}
+
+	if _, err := ParseWordList(strings.NewReader("one\none\n")); !errors.Is(err, ErrWordListTooShort) {
+		t.Errorf("expected ErrWordListTooShort, got %v", err)
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		passphrase, err := GeneratePassphrase(PassphraseOptions{Separator: DefaultSeparator})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		words := strings.Split(passphrase, DefaultSeparator)
+		if len(words) != DefaultWords {
+			t.Fatalf("expected %d words in %q", DefaultWords, passphrase)
+		}
+
+		list := strings.Join(EFFWordList(), "\n") + "\n"
+		for _, word := range words {
+			if !strings.Contains(list, word+"\n") {
+				t.Errorf("word %q is not in the word list", word)
+			}
+		}
+	})
+
+	t.Run("capitalize and digits", func(t *testing.T) {
+		opts := PassphraseOptions{
+			Words:      4,
+			Separator:  " ",
+			Capitalize: true,
+			Digits:     2,
+			WordList:   []string{"alpha", "beta", "gamma"},
+		}
+
+		for i := 0; i < 100; i++ {
+			passphrase, err := GeneratePassphrase(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			words := strings.Split(passphrase, " ")
+			if len(words) != 4 {
+				t.Fatalf("expected 4 words in %q", passphrase)
+			}
+
+			digits := 0
+
+			for _, word := range words {
+				if word[0] < 'A' || word[0] > 'Z' {
+					t.Fatalf("word %q is not capitalized", word)
+				}
+
+				for _, r := range word {
+					if r >= '0' && r <= '9' {
+						digits++
+					}
+				}
+			}
+
+			if digits != 2 {
+				t.Fatalf("expected 2 digits in %q", passphrase)
+			}
+		}
+	})
+
+	t.Run("short word list", func(t *testing.T) {
+		if _, err := GeneratePassphrase(PassphraseOptions{WordList: []string{"one"}}); !errors.Is(err, ErrWordListTooShort) {
+			t.Errorf("expected ErrWordListTooShort, got %v", err)
+		}
+	})
+}
+
+func TestGeneratePassphraseDistribution(t *testing.T) {
+	const samples = 5000
+
+	wordList := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	counts := map[byte]int{}
+
+	for i := 0; i < samples; i++ {
+		passphrase, err := GeneratePassphrase(PassphraseOptions{Words: 2, Separator: "-", WordList: wordList})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, word := range strings.Split(passphrase, "-") {
+			counts[word[0]]++
+		}
+	}
+
+	if stat := chiSquare(counts, len(wordList)); stat > chiSquareLimit(len(wordList)-1) {
+		t.Errorf("chi-square %.1f, word distribution is biased", stat)
+	}
+}
+
+func TestPassphraseEntropy(t *testing.T) {
+	bits, err := PassphraseEntropy(PassphraseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := DefaultWords * math.Log2(7776)
+	if math.Abs(bits-expected) > 1e-9 {
+		t.Errorf("expected %.4f bits, got %.4f", expected, bits)
+	}
+
+	bits, err = PassphraseEntropy(PassphraseOptions{Words: 3, Digits: 1, WordList: []string{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := 3 + math.Log2(10); math.Abs(bits-expected) > 1e-9 {
+		t.Errorf("expected %.4f bits, got %.4f", expected, bits)
+	}
+}
@@ -1,9 +1,11 @@
 package password
 
 import (
-	"math/rand"
-	"slices"
-	"strings"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
 )
 
 const (
@@ -19,11 +21,33 @@ const (
 	DefaultPasswordLength     = 15
 	DefaultSpecialCharsLength = 3
 	DefaultNumbersLength      = 3
+
+	// minLetters covers the letters at both ends of a password, which also
+	// leaves room for at least one lower and one upper case letter.
+	minLetters = 2
 )
 
-func Generate(passwordLength, specialCharsLength, numbersLength int) string {
-	var password strings.Builder
+var ErrTooShort = errors.New("password is too short for the requested special characters and numbers")
+
+type class int
+
+const (
+	classLetter class = iota
+	classNumber
+	classSpecial
+)
 
+// policy is a normalized set of generation options. The password has exactly
+// special special characters and numbers numbers, the remaining characters
+// are letters with at least one lower and one upper case letter. The first
+// and the last characters are always letters.
+type policy struct {
+	length  int
+	special int
+	numbers int
+}
+
+func newPolicy(passwordLength, specialCharsLength, numbersLength int) (policy, error) {
 	if passwordLength <= DefaultLength {
 		passwordLength = DefaultPasswordLength
 	}
@@ -36,38 +60,190 @@ func Generate(passwordLength, specialCharsLength, numbersLength int) string {
 		numbersLength = DefaultNumbersLength
 	}
 
-	// Set special character
-	for i := 0; i < specialCharsLength; i++ {
-		random := rand.Intn(len(specialCharSet)) //nolint:gosec
-		password.WriteString(string(specialCharSet[random]))
+	p := policy{
+		length:  passwordLength,
+		special: specialCharsLength,
+		numbers: numbersLength,
+	}
+
+	if p.letters() < minLetters {
+		return policy{}, fmt.Errorf("%w: length %d, special %d, numbers %d", ErrTooShort, p.length, p.special, p.numbers)
+	}
+
+	return p, nil
+}
+
+func (p policy) letters() int {
+	return p.length - p.special - p.numbers
+}
+
+// Generate creates a password using crypto/rand. Every password allowed by
+// the policy has the same probability.
+func Generate(passwordLength, specialCharsLength, numbersLength int) (string, error) {
+	p, err := newPolicy(passwordLength, specialCharsLength, numbersLength)
+	if err != nil {
+		return "", err
+	}
+
+	// The inner positions get a uniformly shuffled list of classes, the
+	// outer ones are always letters.
+	classes := make([]class, 0, p.length-minLetters)
+
+	for i := 0; i < p.special; i++ {
+		classes = append(classes, classSpecial)
+	}
+
+	for i := 0; i < p.numbers; i++ {
+		classes = append(classes, classNumber)
+	}
+
+	for len(classes) < p.length-minLetters {
+		classes = append(classes, classLetter)
+	}
+
+	if err := shuffle(classes); err != nil {
+		return "", err
+	}
+
+	classes = append([]class{classLetter}, append(classes, classLetter)...)
+
+	letters, err := randomLetters(p.letters())
+	if err != nil {
+		return "", err
+	}
+
+	password := make([]byte, 0, p.length)
+
+	for _, c := range classes {
+		var char byte
+
+		switch c {
+		case classSpecial:
+			char, err = randomChar(specialCharSet)
+
+		case classNumber:
+			char, err = randomChar(numberSet)
+
+		default:
+			char, letters = letters[0], letters[1:]
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		password = append(password, char)
+	}
+
+	return string(password), nil
+}
+
+// Entropy returns the entropy in bits of a password generated with the same
+// options, that is log2 of the number of passwords Generate can return.
+func Entropy(passwordLength, specialCharsLength, numbersLength int) (float64, error) {
+	p, err := newPolicy(passwordLength, specialCharsLength, numbersLength)
+	if err != nil {
+		return 0, err
 	}
 
-	// Set numeric
-	for i := 0; i < numbersLength; i++ {
-		random := rand.Intn(len(numberSet)) //nolint:gosec
-		password.WriteString(string(numberSet[random]))
+	inner := p.length - minLetters
+	innerLetters := p.letters() - minLetters
+
+	// Arrangements of the classes over the inner positions.
+	bits := log2Factorial(inner) - log2Factorial(p.special) - log2Factorial(p.numbers) - log2Factorial(innerLetters)
+
+	bits += float64(p.special) * math.Log2(float64(len(specialCharSet)))
+	bits += float64(p.numbers) * math.Log2(float64(len(numberSet)))
+
+	// Letter strings without a lower or without an upper case letter are
+	// rejected.
+	letters := float64(p.letters())
+	lowerOnly := math.Pow(float64(len(lowerCharSet))/float64(len(allCharSet)), letters)
+	upperOnly := math.Pow(float64(len(upperCharSet))/float64(len(allCharSet)), letters)
+
+	bits += letters*math.Log2(float64(len(allCharSet))) + math.Log2(1-lowerOnly-upperOnly)
+
+	return bits, nil
+}
+
+func log2Factorial(n int) float64 {
+	value, _ := math.Lgamma(float64(n) + 1)
+
+	return value / math.Ln2
+}
+
+// randomLetters draws letters until the result has both a lower and an upper
+// case letter, which keeps the distribution uniform over valid results.
+func randomLetters(count int) ([]byte, error) {
+	for {
+		letters := make([]byte, count)
+
+		var hasLower, hasUpper bool
+
+		for i := range letters {
+			char, err := randomChar(allCharSet)
+			if err != nil {
+				return nil, err
+			}
+
+			letters[i] = char
+
+			if char >= 'a' && char <= 'z' {
+				hasLower = true
+			} else {
+				hasUpper = true
+			}
+		}
+
+		if hasLower && hasUpper {
+			return letters, nil
+		}
 	}
+}
 
-	remainingLength := passwordLength - specialCharsLength - numbersLength
-	for i := 0; i < remainingLength; i++ {
-		random := rand.Intn(len(allCharSet)) //nolint:gosec
-		password.WriteString(string(allCharSet[random]))
+func randomChar(charSet string) (byte, error) {
+	i, err := randomInt(len(charSet))
+	if err != nil {
+		return 0, err
 	}
 
-	charsList := strings.Split(allCharSet, "")
+	return charSet[i], nil
+}
 
-	inRune := []rune(password.String())
-	for z := 0; z < 10_000; z++ {
-		if z > 100 &&
-			slices.Contains(charsList, string(inRune[0])) &&
-			slices.Contains(charsList, string(inRune[len(inRune)-1])) {
-			break
+// shuffle is a Fisher-Yates shuffle with crypto/rand.
+func shuffle(classes []class) error {
+	for i := len(classes) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return err
 		}
 
-		rand.Shuffle(len(inRune), func(i, j int) {
-			inRune[i], inRune[j] = inRune[j], inRune[i]
-		})
+		classes[i], classes[j] = classes[j], classes[i]
 	}
 
-	return string(inRune)
+	return nil
+}
+
+// randomInt returns a uniform random number in [0, n). Values from the
+// incomplete last range of uint32 are rejected to avoid modulo bias.
+func randomInt(n int) (int, error) {
+	if n <= 0 || uint64(n) > math.MaxUint32 {
+		return 0, fmt.Errorf("invalid random range: %d", n)
+	}
+
+	bound := uint32(n)
+	limit := math.MaxUint32 - math.MaxUint32%bound
+
+	var buf [4]byte
+
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			return 0, fmt.Errorf("failed to read random data: %w", err)
+		}
+
+		value := binary.BigEndian.Uint32(buf[:])
+		if value < limit {
+			return int(value % bound), nil
+		}
+	}
 }
@@ -1,12 +1,238 @@
 package password
 
 import (
+	"errors"
+	"math"
+	"strings"
 	"testing"
 )
 
+func countClasses(password string) (lower, upper, numbers, special int) {
+	for _, char := range password {
+		switch {
+		case strings.ContainsRune(lowerCharSet, char):
+			lower++
+		case strings.ContainsRune(upperCharSet, char):
+			upper++
+		case strings.ContainsRune(numberSet, char):
+			numbers++
+		case strings.ContainsRune(specialCharSet, char):
+			special++
+		}
+	}
+
+	return lower, upper, numbers, special
+}
+
+// chiSquare returns the chi-square statistic of the observed counts against
+// a uniform distribution.
+func chiSquare(counts map[byte]int, categories int) float64 {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	expected := float64(total) / float64(categories)
+
+	var stat float64
+
+	for _, count := range counts {
+		diff := float64(count) - expected
+		stat += diff * diff / expected
+	}
+
+	// Categories that never appeared.
+	stat += float64(categories-len(counts)) * expected
+
+	return stat
+}
+
+// chiSquareLimit is a loose upper bound for the chi-square statistic with
+// the given degrees of freedom, far above the 99.9% quantile, so that the
+// tests only fail on a real bias.
+func chiSquareLimit(df int) float64 {
+	return float64(df) + 6*math.Sqrt(2*float64(df)) + 10
+}
+
 func TestGenerate(t *testing.T) {
-	password := Generate(DefaultLength, DefaultLength, DefaultLength)
+	password, err := Generate(DefaultLength, DefaultLength, DefaultLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	if len(password) != DefaultPasswordLength {
 		t.Errorf("Password length is not equal to %d", DefaultPasswordLength)
 	}
 }
+
+func TestGenerateClasses(t *testing.T) {
+	tests := []struct {
+		length  int
+		special int
+		numbers int
+	}{
+		{15, 3, 3},
+		{4, 1, 1},
+		{2, 0, 0},
+		{64, 0, 0},
+		{32, 10, 10},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 200; i++ {
+			password, err := Generate(tt.length, tt.special, tt.numbers)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(password) != tt.length {
+				t.Fatalf("expected length %d, got %d", tt.length, len(password))
+			}
+
+			lower, upper, numbers, special := countClasses(password)
+
+			if special != tt.special || numbers != tt.numbers {
+				t.Fatalf("expected %d special and %d numbers in %q", tt.special, tt.numbers, password)
+			}
+
+			if lower < 1 || upper < 1 {
+				t.Fatalf("expected lower and upper case letters in %q", password)
+			}
+
+			if !strings.Contains(allCharSet, password[:1]) || !strings.Contains(allCharSet, password[len(password)-1:]) {
+				t.Fatalf("expected letters at both ends of %q", password)
+			}
+		}
+	}
+}
+
+func TestGenerateTooShort(t *testing.T) {
+	for _, tt := range [][3]int{{1, 0, 0}, {5, 2, 2}, {3, 3, 0}} {
+		if _, err := Generate(tt[0], tt[1], tt[2]); !errors.Is(err, ErrTooShort) {
+			t.Errorf("expected ErrTooShort for %v, got %v", tt, err)
+		}
+	}
+}
+
+func TestGenerateDistribution(t *testing.T) {
+	const samples = 4000
+
+	letters := map[byte]int{}
+	numbers := map[byte]int{}
+	special := map[byte]int{}
+	specialPositions := map[byte]int{}
+
+	for i := 0; i < samples; i++ {
+		password, err := Generate(12, 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for pos := 0; pos < len(password); pos++ {
+			char := password[pos]
+
+			switch {
+			case strings.IndexByte(allCharSet, char) >= 0:
+				letters[char]++
+			case strings.IndexByte(numberSet, char) >= 0:
+				numbers[char]++
+			default:
+				special[char]++
+				specialPositions[byte(pos)]++
+			}
+		}
+	}
+
+	checks := []struct {
+		name       string
+		counts     map[byte]int
+		categories int
+	}{
+		{"letters", letters, len(allCharSet)},
+		{"numbers", numbers, len(numberSet)},
+		{"special", special, len(specialCharSet)},
+		{"special positions", specialPositions, 12 - minLetters},
+	}
+
+	for _, check := range checks {
+		stat := chiSquare(check.counts, check.categories)
+		if limit := chiSquareLimit(check.categories - 1); stat > limit {
+			t.Errorf("%s: chi-square %.1f exceeds %.1f, distribution is biased", check.name, stat, limit)
+		}
+	}
+
+	if _, ok := specialPositions[0]; ok {
+		t.Error("special character at the first position")
+	}
+}
+
+func TestRandomInt(t *testing.T) {
+	const (
+		bound   = 7
+		samples = 70000
+	)
+
+	counts := map[byte]int{}
+
+	for i := 0; i < samples; i++ {
+		value, err := randomInt(bound)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value < 0 || value >= bound {
+			t.Fatalf("value %d out of range", value)
+		}
+
+		counts[byte(value)]++
+	}
+
+	if stat := chiSquare(counts, bound); stat > chiSquareLimit(bound-1) {
+		t.Errorf("chi-square %.1f, distribution is biased", stat)
+	}
+
+	if _, err := randomInt(0); err == nil {
+		t.Error("expected error for empty range")
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	// Two letters without special characters or numbers: one lower and one
+	// upper case letter in any order.
+	bits, err := Entropy(2, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := math.Log2(float64(2 * len(lowerCharSet) * len(upperCharSet)))
+	if math.Abs(bits-expected) > 1e-9 {
+		t.Errorf("expected %.4f bits, got %.4f", expected, bits)
+	}
+
+	// Four characters with one number: the number can only be at one of the
+	// two inner positions.
+	bits, err = Entropy(4, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validLetters := math.Pow(45, 3) - math.Pow(22, 3) - math.Pow(23, 3)
+	expected = math.Log2(2 * 8 * validLetters)
+
+	if math.Abs(bits-expected) > 1e-9 {
+		t.Errorf("expected %.4f bits, got %.4f", expected, bits)
+	}
+
+	defaultBits, err := Entropy(DefaultLength, DefaultLength, DefaultLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if defaultBits < 70 {
+		t.Errorf("default password entropy is too low: %.1f bits", defaultBits)
+	}
+
+	if _, err := Entropy(3, 2, 0); !errors.Is(err, ErrTooShort) {
+		t.Errorf("expected ErrTooShort, got %v", err)
+	}
+}
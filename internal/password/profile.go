@@ -0,0 +1,255 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	ClassLower  = "lower"
+	ClassUpper  = "upper"
+	ClassDigit  = "digit"
+	ClassSymbol = "symbol"
+)
+
+var profileClasses = []string{ClassLower, ClassUpper, ClassDigit, ClassSymbol}
+
+// Profile is a generation policy for sites with password rules. Empty fields
+// fall back to the defaults: all classes allowed and required, the default
+// symbols and the default length.
+type Profile struct {
+	MinLength int `json:"min_length,omitempty"`
+	MaxLength int `json:"max_length,omitempty"`
+	// Classes lists the allowed character classes: lower, upper, digit and
+	// symbol.
+	Classes []string `json:"classes,omitempty"`
+	// Require lists the classes that must appear at least once, all allowed
+	// classes by default.
+	Require []string `json:"require,omitempty"`
+	Symbols string   `json:"symbols,omitempty"`
+	Exclude string   `json:"exclude,omitempty"`
+	// Prefixes attach the profile to key names, e.g. "/finance".
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+func (p *Profile) classes() []string {
+	if len(p.Classes) == 0 {
+		return profileClasses
+	}
+
+	return p.Classes
+}
+
+func (p *Profile) required() []string {
+	if len(p.Require) == 0 {
+		return p.classes()
+	}
+
+	return p.Require
+}
+
+func (p *Profile) charSet(class string) string {
+	var charSet string
+
+	switch class {
+	case ClassLower:
+		charSet = lowerCharSet
+	case ClassUpper:
+		charSet = upperCharSet
+	case ClassDigit:
+		charSet = numberSet
+	case ClassSymbol:
+		charSet = p.Symbols
+		if charSet == "" {
+			charSet = specialCharSet
+		}
+	}
+
+	var result strings.Builder
+
+	for _, char := range charSet {
+		if strings.ContainsRune(p.Exclude, char) || strings.ContainsRune(result.String(), char) {
+			continue
+		}
+
+		result.WriteRune(char)
+	}
+
+	return result.String()
+}
+
+func (p *Profile) Validate() error {
+	if p.MinLength < 0 || p.MaxLength < 0 {
+		return errors.New("profile length cannot be negative")
+	}
+
+	if p.MaxLength > 0 && p.MinLength > p.MaxLength {
+		return fmt.Errorf("profile min length %d is greater than max length %d", p.MinLength, p.MaxLength)
+	}
+
+	for i, class := range p.classes() {
+		if !slices.Contains(profileClasses, class) {
+			return fmt.Errorf("unknown character class: %s", class)
+		}
+
+		if slices.Contains(p.classes()[:i], class) {
+			return fmt.Errorf("character class %s is listed twice", class)
+		}
+
+		if p.charSet(class) == "" {
+			return fmt.Errorf("character class %s is empty after exclusions", class)
+		}
+	}
+
+	for i, class := range p.required() {
+		if !slices.Contains(p.classes(), class) {
+			return fmt.Errorf("required character class %s is not allowed", class)
+		}
+
+		if slices.Contains(p.required()[:i], class) {
+			return fmt.Errorf("required character class %s is listed twice", class)
+		}
+	}
+
+	for _, char := range p.Symbols {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) || char > unicode.MaxASCII {
+			return fmt.Errorf("invalid symbol: %q", char)
+		}
+	}
+
+	if p.MaxLength > 0 && len(p.required()) > p.MaxLength {
+		return fmt.Errorf("profile max length %d is too short for %d required classes", p.MaxLength, len(p.required()))
+	}
+
+	return nil
+}
+
+// Length returns the password length for the profile. A requested length of
+// DefaultLength picks the longest length the profile allows up to the
+// default password length.
+func (p *Profile) Length(requested int) (int, error) {
+	if requested <= DefaultLength {
+		requested = max(DefaultPasswordLength, p.MinLength, len(p.required()))
+		if p.MaxLength > 0 {
+			requested = min(requested, p.MaxLength)
+		}
+	}
+
+	if requested < p.MinLength || (p.MaxLength > 0 && requested > p.MaxLength) {
+		return 0, fmt.Errorf("length %d is outside of the profile limits (min %d, max %d)", requested, p.MinLength, p.MaxLength)
+	}
+
+	if requested < len(p.required()) {
+		return 0, fmt.Errorf("%w: %d required classes", ErrTooShort, len(p.required()))
+	}
+
+	return requested, nil
+}
+
+// MatchesKey reports whether one of the profile prefixes covers the key name
+// and returns the length of the longest matching prefix.
+func (p *Profile) MatchesKey(keyName string) (int, bool) {
+	best := -1
+
+	for _, prefix := range p.Prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+
+		if keyName == prefix || strings.HasPrefix(keyName, prefix+"/") {
+			best = max(best, len(prefix))
+		}
+	}
+
+	return best, best >= 0
+}
+
+// GenerateProfile creates a password of the given length from the allowed
+// classes. Candidates without every required class are rejected, so every
+// valid password has the same probability.
+func GenerateProfile(p *Profile, length int) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	length, err := p.Length(length)
+	if err != nil {
+		return "", err
+	}
+
+	var allowed strings.Builder
+
+	for _, class := range p.classes() {
+		allowed.WriteString(p.charSet(class))
+	}
+
+	charSet := allowed.String()
+
+	for {
+		password := make([]byte, length)
+
+		for i := range password {
+			password[i], err = randomChar(charSet)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if p.hasRequired(string(password)) {
+			return string(password), nil
+		}
+	}
+}
+
+func (p *Profile) hasRequired(password string) bool {
+	for _, class := range p.required() {
+		if !strings.ContainsAny(password, p.charSet(class)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ProfileEntropy returns the entropy in bits of a password generated by
+// GenerateProfile with the same arguments.
+func ProfileEntropy(p *Profile, length int) (float64, error) {
+	if err := p.Validate(); err != nil {
+		return 0, err
+	}
+
+	length, err := p.Length(length)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+
+	for _, class := range p.classes() {
+		total += len(p.charSet(class))
+	}
+
+	required := p.required()
+
+	// Inclusion-exclusion over the subsets of required classes that are
+	// missing, as a fraction of all strings over the allowed characters.
+	var valid float64
+
+	for mask := 0; mask < 1<<len(required); mask++ {
+		missing := 0
+		sign := 1.0
+
+		for i, class := range required {
+			if mask&(1<<i) != 0 {
+				missing += len(p.charSet(class))
+				sign = -sign
+			}
+		}
+
+		valid += sign * math.Pow(float64(total-missing)/float64(total), float64(length))
+	}
+
+	return float64(length)*math.Log2(float64(total)) + math.Log2(valid), nil
+}
@@ -0,0 +1,173 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		valid   bool
+	}{
+		{"empty profile", Profile{}, true},
+		{"custom symbols", Profile{Symbols: "!#"}, true},
+		{"min above max", Profile{MinLength: 20, MaxLength: 16}, false},
+		{"unknown class", Profile{Classes: []string{"emoji"}}, false},
+		{"duplicate class", Profile{Classes: []string{ClassLower, ClassLower}}, false},
+		{"required not allowed", Profile{Classes: []string{ClassLower}, Require: []string{ClassDigit}}, false},
+		{"duplicate required", Profile{Require: []string{ClassDigit, ClassDigit}}, false},
+		{"letter as symbol", Profile{Symbols: "a!"}, false},
+		{"all symbols excluded", Profile{Symbols: "!", Exclude: "!"}, false},
+		{"max length below required", Profile{MaxLength: 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.profile.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("expected valid profile, got %v", err)
+			} else if !tt.valid && err == nil {
+				t.Error("expected invalid profile")
+			}
+		})
+	}
+}
+
+func TestProfileLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   Profile
+		requested int
+		expected  int
+		valid     bool
+	}{
+		{"default", Profile{}, DefaultLength, DefaultPasswordLength, true},
+		{"capped by max", Profile{MaxLength: 12}, DefaultLength, 12, true},
+		{"raised to min", Profile{MinLength: 20}, DefaultLength, 20, true},
+		{"requested", Profile{MinLength: 8, MaxLength: 16}, 10, 10, true},
+		{"requested above max", Profile{MaxLength: 16}, 20, 0, false},
+		{"requested below min", Profile{MinLength: 8}, 6, 0, false},
+		{"requested below required", Profile{}, 3, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			length, err := tt.profile.Length(tt.requested)
+			if !tt.valid {
+				if err == nil {
+					t.Error("expected error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if length != tt.expected {
+				t.Errorf("expected length %d, got %d", tt.expected, length)
+			}
+		})
+	}
+}
+
+func TestProfileMatchesKey(t *testing.T) {
+	profile := Profile{Prefixes: []string{"/finance", "/finance/bank/"}}
+
+	if n, ok := profile.MatchesKey("/finance/bank/main"); !ok || n != len("/finance/bank") {
+		t.Errorf("expected longest prefix match, got %d %v", n, ok)
+	}
+
+	if _, ok := profile.MatchesKey("/finance"); !ok {
+		t.Error("expected exact match")
+	}
+
+	if _, ok := profile.MatchesKey("/financial/other"); ok {
+		t.Error("prefix must match whole path segments")
+	}
+}
+
+func TestGenerateProfile(t *testing.T) {
+	profile := &Profile{
+		MaxLength: 8,
+		Classes:   []string{ClassLower, ClassDigit, ClassSymbol},
+		Require:   []string{ClassDigit, ClassSymbol},
+		Symbols:   "!#^",
+		Exclude:   "^2",
+	}
+
+	for i := 0; i < 500; i++ {
+		password, err := GenerateProfile(profile, DefaultLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(password) != 8 {
+			t.Fatalf("expected length 8, got %q", password)
+		}
+
+		if strings.ContainsAny(password, "^2"+upperCharSet) {
+			t.Fatalf("password %q contains excluded characters", password)
+		}
+
+		if !strings.ContainsAny(password, numberSet) || !strings.ContainsAny(password, "!#") {
+			t.Fatalf("password %q misses a required class", password)
+		}
+	}
+}
+
+func TestGenerateProfileDistribution(t *testing.T) {
+	profile := &Profile{
+		Classes: []string{ClassDigit},
+		Exclude: "9",
+	}
+
+	counts := map[byte]int{}
+
+	for i := 0; i < 1000; i++ {
+		password, err := GenerateProfile(profile, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for j := 0; j < len(password); j++ {
+			counts[password[j]]++
+		}
+	}
+
+	if stat := chiSquare(counts, len(numberSet)-1); stat > chiSquareLimit(len(numberSet)-2) {
+		t.Errorf("chi-square %.1f, distribution is biased", stat)
+	}
+}
+
+func TestProfileEntropy(t *testing.T) {
+	// Two characters from {lower, digit}, both required: one lower and one
+	// digit in either order.
+	profile := &Profile{Classes: []string{ClassLower, ClassDigit}}
+
+	bits, err := ProfileEntropy(profile, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := math.Log2(float64(2 * len(lowerCharSet) * len(numberSet)))
+	if math.Abs(bits-expected) > 1e-9 {
+		t.Errorf("expected %.4f bits, got %.4f", expected, bits)
+	}
+
+	// Nothing required besides the single class.
+	profile = &Profile{Classes: []string{ClassDigit}}
+
+	bits, err = ProfileEntropy(profile, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := 10 * math.Log2(float64(len(numberSet))); math.Abs(bits-expected) > 1e-9 {
+		t.Errorf("expected %.4f bits, got %.4f", expected, bits)
+	}
+}
@@ -0,0 +1,533 @@
+package strength
+
+import (
+	"bufio"
+	_ "embed"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/vitalvas/gopass/internal/password"
+)
+
+const (
+	// maxLength caps the pattern search, longer passwords are estimated by
+	// brute force, which is more than enough for them.
+	maxLength = 64
+
+	minDictionaryLength = 3
+	minSequenceLength   = 3
+	minRepeatLength     = 3
+	minKeyboardLength   = 4
+
+	bruteforceCardinality = 10
+	// minGuessesGrowing is added per extra match, as in zxcvbn, so that
+	// splitting a password into many small patterns is not too cheap.
+	minGuessesGrowing = 10000
+	// minMatchGuesses keeps very common words from counting as free.
+	minMatchGuesses = 50
+
+	yearRangeStart = 1900
+	yearRangeEnd   = 2039
+)
+
+// Pattern names reported in Result.Patterns.
+const (
+	PatternDictionary = "dictionary"
+	PatternSequence   = "sequence"
+	PatternRepeat     = "repeat"
+	PatternKeyboard   = "keyboard"
+	PatternYear       = "year"
+	PatternBruteforce = "bruteforce"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+var keyboardRows = []string{
+	"1234567890",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+	"qazwsxedcrfvtgbyhnujmikolp",
+}
+
+var leetTable = map[rune]rune{
+	'4': 'a',
+	'@': 'a',
+	'8': 'b',
+	'(': 'c',
+	'3': 'e',
+	'6': 'g',
+	'1': 'i',
+	'!': 'i',
+	'|': 'l',
+	'0': 'o',
+	'5': 's',
+	'$': 's',
+	'7': 't',
+	'+': 't',
+	'2': 'z',
+}
+
+// dictionary maps lowercase words to their rank, common passwords come first
+// and the EFF word list follows.
+var dictionary = sync.OnceValue(func() map[string]int {
+	ranks := make(map[string]int)
+
+	scanner := bufio.NewScanner(strings.NewReader(commonPasswords))
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word != "" {
+			if _, ok := ranks[word]; !ok {
+				ranks[word] = len(ranks) + 1
+			}
+		}
+	}
+
+	for _, word := range password.EFFWordList() {
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = len(ranks) + 1
+		}
+	}
+
+	return ranks
+})
+
+type Result struct {
+	// Guesses is the log10 of the estimated number of guesses.
+	Guesses float64
+	// Entropy is the log2 of the estimated number of guesses.
+	Entropy float64
+	// Score goes from 0 (too guessable) to 4 (very unguessable).
+	Score int
+	// Patterns lists the patterns of the cheapest match sequence.
+	Patterns []string
+}
+
+type match struct {
+	start   int
+	end     int
+	pattern string
+	// guesses is log2 of the guesses for this part of the password.
+	guesses float64
+}
+
+// Estimate scores a password the way zxcvbn does: it finds dictionary words,
+// sequences, repeats, keyboard patterns and years, then takes the cheapest
+// way to build the password from them and brute force.
+func Estimate(pass string) Result {
+	runes := []rune(pass)
+	if len(runes) == 0 {
+		return Result{Patterns: []string{}}
+	}
+
+	if len(runes) > maxLength {
+		bits := float64(len(runes)) * math.Log2(bruteforceCardinality)
+
+		return newResult(bits, []string{PatternBruteforce})
+	}
+
+	matches := findMatches(runes)
+	bits, patterns := cheapestSequence(runes, matches)
+
+	return newResult(bits, patterns)
+}
+
+func newResult(bits float64, patterns []string) Result {
+	guesses := bits * math.Log10(2)
+
+	return Result{
+		Guesses:  guesses,
+		Entropy:  bits,
+		Score:    score(guesses),
+		Patterns: patterns,
+	}
+}
+
+// score uses the zxcvbn thresholds of 10^3, 10^6, 10^8 and 10^10 guesses.
+func score(guesses float64) int {
+	switch {
+	case guesses < 3:
+		return 0
+	case guesses < 6:
+		return 1
+	case guesses < 8:
+		return 2
+	case guesses < 10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func findMatches(runes []rune) []match {
+	var matches []match
+
+	matches = append(matches, dictionaryMatches(runes)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, repeatMatches(runes)...)
+	matches = append(matches, keyboardMatches(runes)...)
+	matches = append(matches, yearMatches(runes)...)
+
+	return matches
+}
+
+// cheapestSequence finds the segmentation with the lowest number of guesses,
+// with brute force filling the gaps between matches. The total for l matches
+// is l! * product(guesses) + minGuessesGrowing^(l-1).
+func cheapestSequence(runes []rune, matches []match) (float64, []string) {
+	n := len(runes)
+	inf := math.Inf(1)
+
+	// best[i][l] is the lowest log2 product for the prefix of length i built
+	// from l matches, prev keeps the last match for the reconstruction.
+	best := make([][]float64, n+1)
+	prev := make([][]*match, n+1)
+
+	for i := range best {
+		best[i] = make([]float64, n+1)
+		prev[i] = make([]*match, n+1)
+
+		for l := range best[i] {
+			best[i][l] = inf
+		}
+	}
+
+	best[0][0] = 0
+
+	byEnd := make([][]match, n+1)
+	for _, m := range matches {
+		byEnd[m.end] = append(byEnd[m.end], m)
+	}
+
+	for i := 1; i <= n; i++ {
+		candidates := byEnd[i]
+
+		for j := 0; j < i; j++ {
+			candidates = append(candidates, match{
+				start:   j,
+				end:     i,
+				pattern: PatternBruteforce,
+				guesses: float64(i-j) * math.Log2(bruteforceCardinality),
+			})
+		}
+
+		for k := range candidates {
+			m := candidates[k]
+
+			for l := 0; l < i; l++ {
+				if math.IsInf(best[m.start][l], 1) {
+					continue
+				}
+
+				guesses := m.guesses
+				if m.pattern != PatternBruteforce {
+					guesses = math.Max(guesses, math.Log2(minMatchGuesses))
+				}
+
+				if total := best[m.start][l] + guesses; total < best[i][l+1] {
+					best[i][l+1] = total
+					prev[i][l+1] = &m
+				}
+			}
+		}
+	}
+
+	bestBits := inf
+	bestCount := 0
+
+	for l := 1; l <= n; l++ {
+		if math.IsInf(best[n][l], 1) {
+			continue
+		}
+
+		factorial, _ := math.Lgamma(float64(l) + 1)
+		bits := addLog2(best[n][l]+factorial/math.Ln2, float64(l-1)*math.Log2(minGuessesGrowing))
+
+		if bits < bestBits {
+			bestBits = bits
+			bestCount = l
+		}
+	}
+
+	patterns := make([]string, bestCount)
+
+	for i, l := n, bestCount; l > 0; l-- {
+		m := prev[i][l]
+		patterns[l-1] = m.pattern
+		i = m.start
+	}
+
+	return bestBits, patterns
+}
+
+// addLog2 returns log2(2^a + 2^b).
+func addLog2(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+
+	return a + math.Log2(1+math.Exp2(b-a))
+}
+
+func dictionaryMatches(runes []rune) []match {
+	var matches []match
+
+	words := dictionary()
+	lower := []rune(strings.ToLower(string(runes)))
+	unleet := make([]rune, len(lower))
+
+	for i, r := range lower {
+		if sub, ok := leetTable[r]; ok {
+			unleet[i] = sub
+		} else {
+			unleet[i] = r
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		for j := i + minDictionaryLength; j <= len(runes); j++ {
+			word := string(lower[i:j])
+			leet := false
+
+			rank, ok := words[word]
+			if !ok {
+				word = string(unleet[i:j])
+				rank, ok = words[word]
+				leet = true
+			}
+
+			if !ok {
+				continue
+			}
+
+			bits := math.Log2(float64(rank)) + uppercaseVariations(runes[i:j])
+			if leet {
+				bits += leetVariations(lower[i:j])
+			}
+
+			matches = append(matches, match{
+				start:   i,
+				end:     j,
+				pattern: PatternDictionary,
+				guesses: bits,
+			})
+		}
+	}
+
+	return matches
+}
+
+// uppercaseVariations returns log2 of the number of ways to capitalize the
+// word, with capitalized and all upper case words counted as one extra bit.
+func uppercaseVariations(word []rune) float64 {
+	upper, lower := 0, 0
+
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+
+	if upper == 0 {
+		return 0
+	}
+
+	if lower == 0 || (upper == 1 && unicode.IsUpper(word[0])) || (upper == 1 && unicode.IsUpper(word[len(word)-1])) {
+		return 1
+	}
+
+	return math.Log2(binomialSum(upper+lower, min(upper, lower)))
+}
+
+// leetVariations returns log2 of the number of ways to substitute the
+// characters that were replaced in the word.
+func leetVariations(word []rune) float64 {
+	substituted := 0
+
+	for _, r := range word {
+		if _, ok := leetTable[r]; ok {
+			substituted++
+		}
+	}
+
+	if substituted == 0 {
+		return 0
+	}
+
+	return math.Max(1, math.Log2(binomialSum(len(word), substituted)))
+}
+
+// binomialSum returns the sum of C(n, i) for i from 1 to k.
+func binomialSum(n, k int) float64 {
+	var sum float64
+
+	for i := 1; i <= k; i++ {
+		lnN, _ := math.Lgamma(float64(n) + 1)
+		lnI, _ := math.Lgamma(float64(i) + 1)
+		lnNI, _ := math.Lgamma(float64(n-i) + 1)
+
+		sum += math.Exp(lnN - lnI - lnNI)
+	}
+
+	return sum
+}
+
+func sequenceMatches(runes []rune) []match {
+	var matches []match
+
+	for i := 0; i+minSequenceLength <= len(runes); {
+		delta := runes[i+1] - runes[i]
+		if delta != 1 && delta != -1 {
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j+1 < len(runes) && runes[j+1]-runes[j] == delta {
+			j++
+		}
+
+		if length := j - i + 1; length >= minSequenceLength {
+			bits := math.Log2(sequenceBase(runes[i]) * float64(length))
+			if delta < 0 {
+				bits++
+			}
+
+			matches = append(matches, match{
+				start:   i,
+				end:     j + 1,
+				pattern: PatternSequence,
+				guesses: bits,
+			})
+		}
+
+		i = j
+	}
+
+	return matches
+}
+
+func sequenceBase(first rune) float64 {
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		return 4
+	case unicode.IsDigit(first):
+		return 10
+	case unicode.IsLetter(first):
+		return 26
+	default:
+		return 95
+	}
+}
+
+func repeatMatches(runes []rune) []match {
+	var matches []match
+
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+
+		if length := j - i; length >= minRepeatLength {
+			matches = append(matches, match{
+				start:   i,
+				end:     j,
+				pattern: PatternRepeat,
+				guesses: math.Log2(charCardinality(runes[i]) * float64(length)),
+			})
+		}
+
+		i = j
+	}
+
+	return matches
+}
+
+func charCardinality(r rune) float64 {
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case unicode.IsLetter(r):
+		return 26
+	default:
+		return 33
+	}
+}
+
+func keyboardMatches(runes []rune) []match {
+	var matches []match
+
+	lower := []rune(strings.ToLower(string(runes)))
+
+	for _, row := range keyboardRows {
+		for _, line := range []string{row, reverse(row)} {
+			for i := 0; i < len(lower); i++ {
+				for j := len(lower); j >= i+minKeyboardLength; j-- {
+					if !strings.Contains(line, string(lower[i:j])) {
+						continue
+					}
+
+					// Roughly the number of starting keys times the number
+					// of directions and lengths.
+					bits := math.Log2(float64(len(line)) * 2 * float64(j-i))
+
+					matches = append(matches, match{
+						start:   i,
+						end:     j,
+						pattern: PatternKeyboard,
+						guesses: bits + uppercaseVariations(runes[i:j]),
+					})
+
+					break
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes)
+}
+
+func yearMatches(runes []rune) []match {
+	var matches []match
+
+	for i := 0; i+4 <= len(runes); i++ {
+		year := 0
+
+		for _, r := range runes[i : i+4] {
+			if r < '0' || r > '9' {
+				year = -1
+				break
+			}
+
+			year = year*10 + int(r-'0')
+		}
+
+		if year >= yearRangeStart && year <= yearRangeEnd {
+			matches = append(matches, match{
+				start:   i,
+				end:     i + 4,
+				pattern: PatternYear,
+				guesses: math.Log2(yearRangeEnd - yearRangeStart + 1),
+			})
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,86 @@
+package strength
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimate(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		result := Estimate("")
+
+		assert.Equal(t, 0, result.Score)
+		assert.Empty(t, result.Patterns)
+	})
+
+	t.Run("weak passwords", func(t *testing.T) {
+		for _, pass := range []string{"password", "P@ssw0rd", "qwerty123", "abcdef", "aaaaaaa", "zxcvbnm", "1990", "summer2019"} {
+			result := Estimate(pass)
+			assert.LessOrEqual(t, result.Score, 1, pass)
+		}
+	})
+
+	t.Run("strong passwords", func(t *testing.T) {
+		for _, pass := range []string{"k5bGtQu_6&7Vu%S", "kiwi-music-unlovable-bonehead-ducking-sleeve", "correcthorsebatterystaple"} {
+			result := Estimate(pass)
+			assert.Equal(t, 4, result.Score, pass)
+		}
+	})
+
+	t.Run("long password", func(t *testing.T) {
+		pass := make([]byte, maxLength+1)
+		for i := range pass {
+			pass[i] = 'a'
+		}
+
+		result := Estimate(string(pass))
+
+		assert.Equal(t, []string{PatternBruteforce}, result.Patterns)
+		assert.Equal(t, 4, result.Score)
+	})
+
+	t.Run("entropy and guesses agree", func(t *testing.T) {
+		result := Estimate("F7y2DU3B")
+
+		assert.InDelta(t, result.Entropy*0.30103, result.Guesses, 0.001)
+	})
+}
+
+func TestEstimatePatterns(t *testing.T) {
+	tests := []struct {
+		password string
+		pattern  string
+	}{
+		{"password", PatternDictionary},
+		{"p4ssw0rd", PatternDictionary},
+		{"abcdefg", PatternSequence},
+		{"9876543", PatternSequence},
+		{"zzzzzzz", PatternRepeat},
+		{"wertyui", PatternKeyboard},
+		{"1987", PatternYear},
+		{"x7#q", PatternBruteforce},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			result := Estimate(tt.password)
+			assert.Contains(t, result.Patterns, tt.pattern)
+		})
+	}
+}
+
+func TestUppercaseVariations(t *testing.T) {
+	assert.Equal(t, 0.0, uppercaseVariations([]rune("password")))
+	assert.Equal(t, 1.0, uppercaseVariations([]rune("Password")))
+	assert.Equal(t, 1.0, uppercaseVariations([]rune("PASSWORD")))
+	assert.Greater(t, uppercaseVariations([]rune("pAssWord")), 1.0)
+}
+
+func TestScore(t *testing.T) {
+	assert.Equal(t, 0, score(2.9))
+	assert.Equal(t, 1, score(3))
+	assert.Equal(t, 2, score(6))
+	assert.Equal(t, 3, score(8))
+	assert.Equal(t, 4, score(10))
+}
@@ -1,9 +1,24 @@
 package vault
 
-import "github.com/vitalvas/gopass/internal/encryptor"
+import (
+	"github.com/vitalvas/gopass/internal/encryptor"
+	"github.com/vitalvas/gopass/internal/password"
+)
 
 type Config struct {
-	Name    string          `json:"name"`
-	Address string          `json:"address"`
-	Keys    *encryptor.Keys `json:"keys"`
+	Name      string           `json:"name"`
+	Address   string           `json:"address"`
+	Keys      *encryptor.Keys  `json:"keys"`
+	History   *HistoryConfig   `json:"history,omitempty"`
+	Clipboard *ClipboardConfig `json:"clipboard,omitempty"`
+	// Profiles are named password generation policies.
+	Profiles map[string]*password.Profile `json:"profiles,omitempty"`
+}
+
+// ClipboardConfig overrides the detected clipboard tool and the time after
+// which a copied secret is cleared.
+type ClipboardConfig struct {
+	CopyCommand  string `json:"copy_command,omitempty"`
+	PasteCommand string `json:"paste_command,omitempty"`
+	Timeout      int    `json:"timeout,omitempty"`
 }
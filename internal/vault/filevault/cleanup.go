@@ -13,6 +13,10 @@ func cleanupStorage(storagePath string) error {
 				return err
 			}
 			if info.IsDir() {
+				if info.Name() == gitDir {
+					return filepath.SkipDir
+				}
+
 				entries, err := os.ReadDir(path)
 				if err != nil {
 					return err
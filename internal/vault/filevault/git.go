@@ -0,0 +1,406 @@
+package filevault
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+const (
+	gitDir           = ".git"
+	gitDefaultRemote = "origin"
+)
+
+// Commit messages never contain key names, which are encrypted.
+const (
+	gitMessageInit          = "Initialize vault"
+	gitMessageUpdate        = "Update entry"
+	gitMessageDelete        = "Delete entry"
+	gitMessageRename        = "Rename entry"
+	gitMessageUpdateHistory = "Update entry history"
+	gitMessagePurgeHistory  = "Delete entry history"
+	gitMessageTestKey       = "Update test key"
+)
+
+// GitEnabled reports whether the storage directory is a git repository, in
+// which case every change is committed.
+func (v *Vault) GitEnabled() bool {
+	info, err := os.Stat(filepath.Join(v.storagePath, gitDir))
+
+	return err == nil && info.IsDir()
+}
+
+func (v *Vault) GitInit(remote string) error {
+	if v.GitEnabled() {
+		return errors.New("git is already enabled")
+	}
+
+	if _, err := v.git("init", "-q"); err != nil {
+		return err
+	}
+
+	if err := v.gitCommit(gitMessageInit); err != nil {
+		return err
+	}
+
+	if remote != "" {
+		if _, err := v.git("remote", "add", gitDefaultRemote, remote); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *Vault) GitPush(remote string) error {
+	if err := v.requireGit(); err != nil {
+		return err
+	}
+
+	_, err := v.git("push", "-q", "-u", gitRemote(remote), "HEAD")
+
+	return err
+}
+
+func (v *Vault) GitPull(remote string) ([]vault.Conflict, error) {
+	if err := v.requireGit(); err != nil {
+		return nil, err
+	}
+
+	branch, err := v.git("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	// A deleted entry and its archived revision have the same content,
+	// rename detection would apply changes of the entry to the revision. Some
+	// git versions ignore no-renames with the ort strategy.
+	_, pullErr := v.git("pull", "-q", "--no-rebase", "--no-edit", "-s", "recursive", "-X", "no-renames", gitRemote(remote), strings.TrimSpace(string(branch)))
+	if pullErr == nil {
+		if err := v.renumberRevisions(); err != nil {
+			return nil, err
+		}
+
+		return nil, v.gitCommit(gitMessageUpdateHistory)
+	}
+
+	if !v.merging() {
+		return nil, pullErr
+	}
+
+	output, err := v.git("diff", "--name-only", "--diff-filter=U", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []vault.Conflict
+
+	for _, path := range strings.Split(strings.TrimRight(string(output), "\x00"), "\x00") {
+		switch {
+		case path == "":
+			continue
+
+		case path == testKeyName:
+			return nil, errors.Join(errors.New("the remote vault uses different keys"), v.AbortMerge())
+
+		case strings.Contains(path, historyExtension+"/"):
+			// Both sides archived a revision with the same number, the
+			// local history is kept.
+			if err := v.resolvePath(path, true); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		conflict, err := v.conflict(path)
+		if err != nil {
+			return nil, errors.Join(err, v.AbortMerge())
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	if len(conflicts) == 0 {
+		return nil, v.FinishMerge()
+	}
+
+	return conflicts, nil
+}
+
+func (v *Vault) conflict(path string) (vault.Conflict, error) {
+	name := strings.TrimSuffix(filepath.Base(path), fileExtension)
+
+	keyID, err := base32Encoding.DecodeString(strings.ToUpper(name))
+	if err != nil {
+		return vault.Conflict{}, fmt.Errorf("unexpected conflict in %s", path)
+	}
+
+	ours, err := v.conflictSide(path, 2)
+	if err != nil {
+		return vault.Conflict{}, err
+	}
+
+	theirs, err := v.conflictSide(path, 3)
+	if err != nil {
+		return vault.Conflict{}, err
+	}
+
+	return vault.Conflict{KeyID: keyID, Ours: ours, Theirs: theirs}, nil
+}
+
+// conflictSide reads one side of a conflicting file from the git index,
+// stage 2 holds the local and stage 3 the remote version.
+func (v *Vault) conflictSide(path string, stage int) (*vault.ConflictSide, error) {
+	ref := fmt.Sprintf(":%d:%s", stage, path)
+
+	if _, err := v.git("cat-file", "-e", ref); err != nil {
+		return nil, nil
+	}
+
+	content, err := v.git("show", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey, encryptedValue, err := DecodeEntry(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vault.ConflictSide{EncryptedKey: encryptedKey, EncryptedValue: encryptedValue}, nil
+}
+
+func (v *Vault) ResolveConflict(conflict vault.Conflict, resolution vault.Resolution) error {
+	filePath, _ := getKeyPath(conflict.KeyID)
+
+	switch resolution {
+	case vault.ResolveOurs:
+		return v.resolvePath(filepath.ToSlash(filePath), true)
+	case vault.ResolveTheirs:
+		return v.resolvePath(filepath.ToSlash(filePath), false)
+	}
+
+	return fmt.Errorf("invalid resolution: %d", resolution)
+}
+
+// resolvePath keeps one side of a conflicting file, removing the file if it
+// was deleted on that side.
+func (v *Vault) resolvePath(path string, ours bool) error {
+	stage, side := ":3:", "--theirs"
+	if ours {
+		stage, side = ":2:", "--ours"
+	}
+
+	if _, err := v.git("cat-file", "-e", stage+path); err != nil {
+		_, err := v.git("rm", "-q", "-f", "--", path)
+		return err
+	}
+
+	if _, err := v.git("checkout", side, "--", path); err != nil {
+		return err
+	}
+
+	_, err := v.git("add", "--", path)
+
+	return err
+}
+
+func (v *Vault) FinishMerge() error {
+	if err := v.renumberRevisions(); err != nil {
+		return err
+	}
+
+	if _, err := v.git("add", "-A"); err != nil {
+		return err
+	}
+
+	if _, err := v.git("commit", "-q", "--no-edit"); err != nil {
+		return err
+	}
+
+	return cleanupStorage(v.storagePath)
+}
+
+// renumberRevisions gives revisions archived with the same number on both
+// sides of a merge the next free numbers, ordered by time.
+func (v *Vault) renumberRevisions() error {
+	return filepath.WalkDir(v.storagePath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		if entry.Name() == gitDir {
+			return filepath.SkipDir
+		}
+
+		if !strings.HasSuffix(entry.Name(), historyExtension) {
+			return nil
+		}
+
+		revisions, err := listRevisions(path)
+		if err != nil {
+			return err
+		}
+
+		// Revision file names contain the creation time, renaming never
+		// overwrites another revision.
+		for i := 1; i < len(revisions); i++ {
+			if revisions[i].Number > revisions[i-1].Number {
+				continue
+			}
+
+			renamed := revisions[i]
+			renamed.Number = revisions[i-1].Number + 1
+
+			if err := os.Rename(filepath.Join(path, revisionFileName(revisions[i])), filepath.Join(path, revisionFileName(renamed))); err != nil {
+				return fmt.Errorf("failed to renumber revision: %w", err)
+			}
+
+			revisions[i] = renamed
+		}
+
+		return filepath.SkipDir
+	})
+}
+
+func (v *Vault) AbortMerge() error {
+	_, err := v.git("merge", "--abort")
+
+	return err
+}
+
+func (v *Vault) GitLog(limit int) ([]vault.Commit, error) {
+	if err := v.requireGit(); err != nil {
+		return nil, err
+	}
+
+	args := []string{"log", "--format=%H%x1f%an%x1f%at%x1f%s"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+
+	output, err := v.git(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []vault.Commit
+
+	for line := range strings.SplitSeq(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.Split(line, "\x1f")
+		if len(parts) != 4 {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit time: %w", err)
+		}
+
+		commits = append(commits, vault.Commit{
+			Hash:      parts[0],
+			Author:    parts[1],
+			CreatedAt: time.Unix(seconds, 0).UTC(),
+			Message:   parts[3],
+		})
+	}
+
+	return commits, nil
+}
+
+// gitCommit commits all changes in the storage directory if git is enabled.
+func (v *Vault) gitCommit(message string) error {
+	if !v.GitEnabled() || v.merging() {
+		return nil
+	}
+
+	if _, err := v.git("add", "-A"); err != nil {
+		return err
+	}
+
+	// Exits with 0 when nothing is staged.
+	if _, err := v.git("diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+
+	_, err := v.git("commit", "-q", "-m", message)
+
+	return err
+}
+
+func (v *Vault) merging() bool {
+	_, err := v.git("rev-parse", "-q", "--verify", "MERGE_HEAD")
+
+	return err == nil
+}
+
+func (v *Vault) requireGit() error {
+	if !v.GitEnabled() {
+		return errors.New("git is not enabled for this vault, run \"gopass git init\"")
+	}
+
+	return nil
+}
+
+func gitRemote(remote string) string {
+	if remote == "" {
+		return gitDefaultRemote
+	}
+
+	return remote
+}
+
+func (v *Vault) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = v.storagePath
+	cmd.Env = append(os.Environ(), v.gitIdentity()...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+
+		return stdout.Bytes(), fmt.Errorf("git %s: %s", args[0], message)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// gitIdentity sets a commit author for users without a git identity, an
+// existing identity is never overridden.
+func (v *Vault) gitIdentity() []string {
+	v.gitIdentityOnce.Do(func() {
+		cmd := exec.Command("git", "config", "user.email")
+		cmd.Dir = v.storagePath
+
+		if err := cmd.Run(); err != nil {
+			v.gitIdentityEnv = []string{
+				"GIT_AUTHOR_NAME=gopass",
+				"GIT_AUTHOR_EMAIL=gopass@localhost",
+				"GIT_COMMITTER_NAME=gopass",
+				"GIT_COMMITTER_EMAIL=gopass@localhost",
+			}
+		}
+	})
+
+	return v.gitIdentityEnv
+}
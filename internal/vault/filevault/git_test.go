@@ -0,0 +1,256 @@
+package filevault
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+// newGitVaults returns two vaults sharing a local bare repository.
+func newGitVaults(t *testing.T) (*Vault, *Vault) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+
+	runGit(t, root, "init", "-q", "--bare", remote)
+
+	local := New(filepath.Join(root, "local"))
+	require.NoError(t, os.MkdirAll(local.storagePath, 0700))
+	require.NoError(t, local.SetTestKey([]byte("test")))
+	require.NoError(t, local.GitInit(remote))
+	require.NoError(t, local.GitPush(""))
+
+	runGit(t, root, "clone", "-q", remote, "other")
+
+	return local, New(filepath.Join(root, "other"))
+}
+
+func TestGitInit(t *testing.T) {
+	local, _ := newGitVaults(t)
+
+	assert.True(t, local.GitEnabled())
+	assert.Error(t, local.GitInit(""))
+
+	commits, err := local.GitLog(0)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, gitMessageInit, commits[0].Message)
+	assert.Len(t, commits[0].Hash, 40)
+
+	v := New(t.TempDir())
+	assert.False(t, v.GitEnabled())
+
+	_, err = v.GitLog(0)
+	assert.Error(t, err)
+	assert.Error(t, v.GitPush(""))
+}
+
+func TestGitCommits(t *testing.T) {
+	local, _ := newGitVaults(t)
+
+	keyID := []byte{0x01, 0x02, 0x03}
+	newKeyID := []byte{0x04, 0x05, 0x06}
+
+	require.NoError(t, local.SetKey(keyID, []byte("key"), []byte("value")))
+	require.NoError(t, local.SetKey(keyID, []byte("key"), []byte("value2")))
+	require.NoError(t, local.RenameKey(keyID, newKeyID))
+	require.NoError(t, local.DeleteKey(newKeyID))
+	require.NoError(t, local.PurgeRevisions(newKeyID))
+
+	commits, err := local.GitLog(0)
+	require.NoError(t, err)
+
+	var messages []string
+	for _, commit := range commits {
+		messages = append(messages, commit.Message)
+	}
+
+	assert.Equal(t, []string{
+		gitMessagePurgeHistory,
+		gitMessageDelete,
+		gitMessageRename,
+		gitMessageUpdate,
+		gitMessageUpdate,
+		gitMessageInit,
+	}, messages)
+
+	commits, err = local.GitLog(2)
+	require.NoError(t, err)
+	assert.Len(t, commits, 2)
+
+	// Unchanged content does not create a commit.
+	require.NoError(t, local.SetTestKey([]byte("test")))
+
+	commits, err = local.GitLog(0)
+	require.NoError(t, err)
+	assert.Len(t, commits, 6)
+
+	// Cleanup must not touch the repository.
+	require.NoError(t, cleanupStorage(local.storagePath))
+	assert.DirExists(t, filepath.Join(local.storagePath, gitDir))
+
+	keys, err := local.ListKeys()
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestGitPushPull(t *testing.T) {
+	local, other := newGitVaults(t)
+
+	keyID := []byte{0x01, 0x02, 0x03}
+
+	require.NoError(t, local.SetKey(keyID, []byte("key"), []byte("value")))
+	require.NoError(t, local.GitPush(""))
+
+	conflicts, err := other.GitPull("")
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	_, value, err := other.GetKey(keyID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	// Changes of different keys are merged.
+	require.NoError(t, other.SetKey([]byte{0x07, 0x07, 0x07}, []byte("key"), []byte("other")))
+	require.NoError(t, other.GitPush(""))
+	require.NoError(t, local.SetKey([]byte{0x08, 0x08, 0x08}, []byte("key"), []byte("local")))
+
+	conflicts, err = local.GitPull("")
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	keys, err := local.ListKeys()
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+}
+
+func TestGitConflicts(t *testing.T) {
+	keyID := []byte{0x01, 0x02, 0x03}
+
+	setup := func(t *testing.T) (*Vault, []vault.Conflict) {
+		local, other := newGitVaults(t)
+
+		require.NoError(t, local.SetKey(keyID, []byte("key"), []byte("base")))
+		require.NoError(t, local.GitPush(""))
+
+		_, err := other.GitPull("")
+		require.NoError(t, err)
+
+		require.NoError(t, other.SetKey(keyID, []byte("key"), []byte("theirs")))
+		require.NoError(t, other.GitPush(""))
+		require.NoError(t, local.SetKey(keyID, []byte("key"), []byte("ours")))
+
+		conflicts, err := local.GitPull("")
+		require.NoError(t, err)
+		require.Len(t, conflicts, 1)
+
+		return local, conflicts
+	}
+
+	t.Run("sides", func(t *testing.T) {
+		_, conflicts := setup(t)
+
+		conflict := conflicts[0]
+		assert.Equal(t, keyID, conflict.KeyID)
+		require.NotNil(t, conflict.Ours)
+		require.NotNil(t, conflict.Theirs)
+		assert.Equal(t, []byte("ours"), conflict.Ours.EncryptedValue)
+		assert.Equal(t, []byte("theirs"), conflict.Theirs.EncryptedValue)
+	})
+
+	for name, tc := range map[string]struct {
+		resolution vault.Resolution
+		expected   string
+	}{
+		"ours":   {vault.ResolveOurs, "ours"},
+		"theirs": {vault.ResolveTheirs, "theirs"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			local, conflicts := setup(t)
+
+			require.NoError(t, local.ResolveConflict(conflicts[0], tc.resolution))
+			require.NoError(t, local.FinishMerge())
+
+			_, value, err := local.GetKey(keyID)
+			require.NoError(t, err)
+			assert.Equal(t, []byte(tc.expected), value)
+
+			// History of both sides is kept and renumbered.
+			revisions, err := local.ListRevisions(keyID)
+			require.NoError(t, err)
+			require.Len(t, revisions, 2)
+			assert.Equal(t, 1, revisions[0].Number)
+			assert.Equal(t, 2, revisions[1].Number)
+
+			require.NoError(t, local.GitPush(""))
+		})
+	}
+
+	t.Run("abort", func(t *testing.T) {
+		local, _ := setup(t)
+
+		require.NoError(t, local.AbortMerge())
+
+		_, value, err := local.GetKey(keyID)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("ours"), value)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		local, other := newGitVaults(t)
+
+		require.NoError(t, local.SetKey(keyID, []byte("key"), []byte("base")))
+		require.NoError(t, local.GitPush(""))
+
+		_, err := other.GitPull("")
+		require.NoError(t, err)
+
+		require.NoError(t, other.DeleteKey(keyID))
+		require.NoError(t, other.GitPush(""))
+		require.NoError(t, local.SetKey(keyID, []byte("key"), []byte("ours")))
+
+		conflicts, err := local.GitPull("")
+		require.NoError(t, err)
+		require.Len(t, conflicts, 1)
+		assert.NotNil(t, conflicts[0].Ours)
+		assert.Nil(t, conflicts[0].Theirs)
+
+		require.NoError(t, local.ResolveConflict(conflicts[0], vault.ResolveTheirs))
+		require.NoError(t, local.FinishMerge())
+
+		_, _, err = local.GetKey(keyID)
+		assert.Error(t, err)
+	})
+
+	t.Run("different keys", func(t *testing.T) {
+		local, other := newGitVaults(t)
+
+		require.NoError(t, other.SetTestKey([]byte("other")))
+		require.NoError(t, other.GitPush(""))
+		require.NoError(t, local.SetTestKey([]byte("local")))
+
+		_, err := local.GitPull("")
+		assert.Error(t, err)
+		assert.False(t, local.merging())
+	})
+}
@@ -0,0 +1,230 @@
+package filevault
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+const historyExtension = ".history"
+
+var timeNow = time.Now
+
+func (v *Vault) SetHistory(cfg *vault.HistoryConfig) {
+	v.history = cfg
+}
+
+func (v *Vault) historyPath(keyID []byte) string {
+	filePath, _ := getKeyPath(keyID)
+
+	return filepath.Join(v.storagePath, strings.TrimSuffix(filePath, fileExtension)+historyExtension)
+}
+
+func revisionFileName(rev vault.Revision) string {
+	return fmt.Sprintf("%08d-%d%s", rev.Number, rev.CreatedAt.UnixNano(), fileExtension)
+}
+
+func parseRevisionFileName(name string) (vault.Revision, error) {
+	number, created, ok := strings.Cut(strings.TrimSuffix(name, fileExtension), "-")
+	if !ok {
+		return vault.Revision{}, fmt.Errorf("invalid revision file name: %s", name)
+	}
+
+	num, err := strconv.Atoi(number)
+	if err != nil {
+		return vault.Revision{}, fmt.Errorf("invalid revision number: %w", err)
+	}
+
+	nanos, err := strconv.ParseInt(created, 10, 64)
+	if err != nil {
+		return vault.Revision{}, fmt.Errorf("invalid revision time: %w", err)
+	}
+
+	return vault.Revision{
+		Number:    num,
+		CreatedAt: time.Unix(0, nanos).UTC(),
+	}, nil
+}
+
+func (v *Vault) ListRevisions(keyID []byte) ([]vault.Revision, error) {
+	return listRevisions(v.historyPath(keyID))
+}
+
+func listRevisions(historyPath string) ([]vault.Revision, error) {
+	entries, err := os.ReadDir(historyPath)
+	if os.IsNotExist(err) {
+		return []vault.Revision{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+
+	revisions := make([]vault.Revision, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExtension) {
+			continue
+		}
+
+		rev, err := parseRevisionFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, rev)
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		if revisions[i].Number == revisions[j].Number {
+			return revisions[i].CreatedAt.Before(revisions[j].CreatedAt)
+		}
+
+		return revisions[i].Number < revisions[j].Number
+	})
+
+	return revisions, nil
+}
+
+func (v *Vault) findRevision(keyID []byte, number int) (string, error) {
+	revisions, err := v.ListRevisions(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, rev := range revisions {
+		if rev.Number == number {
+			return filepath.Join(v.historyPath(keyID), revisionFileName(rev)), nil
+		}
+	}
+
+	return "", errors.New("revision not found")
+}
+
+func (v *Vault) GetRevision(keyID []byte, number int) ([]byte, []byte, error) {
+	revisionPath, err := v.findRevision(keyID, number)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return readEntry(revisionPath)
+}
+
+// SetRevision replaces the content of an existing revision, keeping its
+// number and time. It is used to re-encrypt history on key rotation.
+func (v *Vault) SetRevision(keyID []byte, number int, encryptedKey []byte, encryptedValue []byte) error {
+	revisionPath, err := v.findRevision(keyID, number)
+	if err != nil {
+		return err
+	}
+
+	if err := writeEntry(revisionPath, encryptedKey, encryptedValue); err != nil {
+		return err
+	}
+
+	return v.gitCommit(gitMessageUpdateHistory)
+}
+
+// ReplaceKey overwrites the current value of an existing key without adding
+// it to the history. It is used to re-encrypt keys on key rotation.
+func (v *Vault) ReplaceKey(keyID []byte, encryptedKey []byte, encryptedValue []byte) error {
+	filePath, _ := getKeyPath(keyID)
+
+	fullFilePath := filepath.Join(v.storagePath, filePath)
+
+	if _, err := os.Stat(fullFilePath); os.IsNotExist(err) {
+		return vault.ErrKeyNotFound
+	}
+
+	if err := writeEntry(fullFilePath, encryptedKey, encryptedValue); err != nil {
+		return err
+	}
+
+	return v.gitCommit(gitMessageUpdate)
+}
+
+func (v *Vault) PurgeRevisions(keyID []byte) error {
+	if err := os.RemoveAll(v.historyPath(keyID)); err != nil {
+		return fmt.Errorf("failed to purge revisions: %w", err)
+	}
+
+	if err := cleanupStorage(v.storagePath); err != nil {
+		return fmt.Errorf("failed to cleanup storage: %w", err)
+	}
+
+	return v.gitCommit(gitMessagePurgeHistory)
+}
+
+// archiveKey copies the current value of a key into its history before it
+// is overwritten or deleted.
+func (v *Vault) archiveKey(keyID []byte) error {
+	if v.history != nil && v.history.Disabled {
+		return nil
+	}
+
+	filePath, _ := getKeyPath(keyID)
+	fullFilePath := filepath.Join(v.storagePath, filePath)
+
+	encryptedKey, encryptedValue, err := readEntry(fullFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	revisions, err := v.ListRevisions(keyID)
+	if err != nil {
+		return err
+	}
+
+	rev := vault.Revision{
+		Number:    1,
+		CreatedAt: timeNow().UTC(),
+	}
+
+	if len(revisions) > 0 {
+		rev.Number = revisions[len(revisions)-1].Number + 1
+	}
+
+	historyPath := v.historyPath(keyID)
+
+	if err := os.MkdirAll(historyPath, 0700); err != nil {
+		return err
+	}
+
+	if err := writeEntry(filepath.Join(historyPath, revisionFileName(rev)), encryptedKey, encryptedValue); err != nil {
+		return err
+	}
+
+	return v.pruneRevisions(keyID, append(revisions, rev))
+}
+
+func (v *Vault) pruneRevisions(keyID []byte, revisions []vault.Revision) error {
+	if v.history == nil {
+		return nil
+	}
+
+	historyPath := v.historyPath(keyID)
+
+	for i, rev := range revisions {
+		expired := v.history.MaxAgeDays > 0 &&
+			timeNow().Sub(rev.CreatedAt) > time.Duration(v.history.MaxAgeDays)*24*time.Hour
+
+		excess := v.history.MaxRevisions > 0 && len(revisions)-i > v.history.MaxRevisions
+
+		if !expired && !excess {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(historyPath, revisionFileName(rev))); err != nil {
+			return fmt.Errorf("failed to remove revision: %w", err)
+		}
+	}
+
+	return nil
+}
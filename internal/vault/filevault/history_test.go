@@ -0,0 +1,237 @@
+package filevault
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func TestRevisionFileName(t *testing.T) {
+	rev := vault.Revision{
+		Number:    12,
+		CreatedAt: time.Unix(1700000000, 123).UTC(),
+	}
+
+	parsed, err := parseRevisionFileName(revisionFileName(rev))
+	require.NoError(t, err)
+	assert.Equal(t, rev, parsed)
+
+	for _, name := range []string{"invalid.txt", "abc-123.txt", "1-abc.txt"} {
+		_, err := parseRevisionFileName(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	keyID := []byte{0x01, 0x02, 0x03, 0x04}
+
+	t.Run("set key keeps previous values", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		v := New(storagePath)
+
+		revisions, err := v.ListRevisions(keyID)
+		require.NoError(t, err)
+		assert.Empty(t, revisions)
+
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-1")))
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-2")))
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-3")))
+
+		revisions, err = v.ListRevisions(keyID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, 1, revisions[0].Number)
+		assert.Equal(t, 2, revisions[1].Number)
+
+		_, value, err := v.GetRevision(keyID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value-1"), value)
+
+		_, value, err = v.GetRevision(keyID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value-2"), value)
+
+		_, value, err = v.GetKey(keyID)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value-3"), value)
+
+		_, _, err = v.GetRevision(keyID, 3)
+		assert.Error(t, err)
+
+		// History entries are not listed as keys
+		keys, err := v.ListKeys()
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+	})
+
+	t.Run("delete key keeps last value", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		v := New(storagePath)
+
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-1")))
+		require.NoError(t, v.DeleteKey(keyID))
+
+		_, _, err = v.GetKey(keyID)
+		assert.Error(t, err)
+
+		_, value, err := v.GetRevision(keyID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value-1"), value)
+
+		require.NoError(t, v.PurgeRevisions(keyID))
+
+		revisions, err := v.ListRevisions(keyID)
+		require.NoError(t, err)
+		assert.Empty(t, revisions)
+
+		entries, err := os.ReadDir(storagePath)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("set revision", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		v := New(storagePath)
+
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-1")))
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-2")))
+
+		before, err := v.ListRevisions(keyID)
+		require.NoError(t, err)
+
+		require.NoError(t, v.SetRevision(keyID, 1, []byte("new-key"), []byte("new-value")))
+
+		after, err := v.ListRevisions(keyID)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+
+		encKey, value, err := v.GetRevision(keyID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("new-key"), encKey)
+		assert.Equal(t, []byte("new-value"), value)
+
+		assert.Error(t, v.SetRevision(keyID, 5, []byte("key"), []byte("value")))
+	})
+
+	t.Run("replace key", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		v := New(storagePath)
+
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-1")))
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-2")))
+
+		require.NoError(t, v.ReplaceKey(keyID, []byte("new-key"), []byte("new-value")))
+
+		revisions, err := v.ListRevisions(keyID)
+		require.NoError(t, err)
+		assert.Len(t, revisions, 1)
+
+		encKey, value, err := v.GetKey(keyID)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("new-key"), encKey)
+		assert.Equal(t, []byte("new-value"), value)
+
+		assert.ErrorIs(t, v.ReplaceKey([]byte("missing-key"), []byte("key"), []byte("value")), vault.ErrKeyNotFound)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		v := New(storagePath)
+		v.SetHistory(&vault.HistoryConfig{Disabled: true})
+
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-1")))
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-2")))
+
+		revisions, err := v.ListRevisions(keyID)
+		require.NoError(t, err)
+		assert.Empty(t, revisions)
+	})
+
+	t.Run("max revisions", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		v := New(storagePath)
+		v.SetHistory(&vault.HistoryConfig{MaxRevisions: 2})
+
+		for _, value := range []string{"value-1", "value-2", "value-3", "value-4"} {
+			require.NoError(t, v.SetKey(keyID, []byte("key"), []byte(value)))
+		}
+
+		revisions, err := v.ListRevisions(keyID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, 2, revisions[0].Number)
+		assert.Equal(t, 3, revisions[1].Number)
+	})
+
+	t.Run("max age", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		now := time.Now()
+		timeNow = func() time.Time { return now }
+		defer func() { timeNow = time.Now }()
+
+		v := New(storagePath)
+		v.SetHistory(&vault.HistoryConfig{MaxAgeDays: 30})
+
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-1")))
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-2")))
+
+		now = now.Add(31 * 24 * time.Hour)
+
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-3")))
+
+		revisions, err := v.ListRevisions(keyID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+		assert.Equal(t, 2, revisions[0].Number)
+
+		_, value, err := v.GetRevision(keyID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value-2"), value)
+	})
+
+	t.Run("rename moves history", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		v := New(storagePath)
+		newKeyID := []byte{0xaa, 0xbb, 0xcc, 0xdd}
+
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-1")))
+		require.NoError(t, v.SetKey(keyID, []byte("key"), []byte("value-2")))
+		require.NoError(t, v.RenameKey(keyID, newKeyID))
+
+		revisions, err := v.ListRevisions(keyID)
+		require.NoError(t, err)
+		assert.Empty(t, revisions)
+
+		_, value, err := v.GetRevision(newKeyID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value-1"), value)
+	})
+}
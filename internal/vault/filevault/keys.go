@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/vitalvas/gopass/internal/vault"
 )
 
 func (v *Vault) ListKeys() ([][]byte, error) {
@@ -19,6 +21,10 @@ func (v *Vault) ListKeys() ([][]byte, error) {
 	response := make([][]byte, 0, len(fileList))
 
 	for _, row := range fileList {
+		if strings.HasPrefix(row, filepath.Join(v.storagePath, gitDir)+string(filepath.Separator)) {
+			continue
+		}
+
 		name := strings.TrimSuffix(filepath.Base(row), fileExtension)
 
 		decoded, err := base32Encoding.DecodeString(strings.ToUpper(name))
@@ -38,34 +44,12 @@ func (v *Vault) GetKey(keyID []byte) ([]byte, []byte, error) {
 	fullFilePath := filepath.Join(v.storagePath, filePath)
 
 	if _, err := os.Stat(fullFilePath); os.IsNotExist(err) {
-		return nil, nil, errors.New("key not found")
+		return nil, nil, vault.ErrKeyNotFound
 	} else if err != nil {
 		return nil, nil, fmt.Errorf("failed to check file: %w", err)
 	}
 
-	encoded, err := os.ReadFile(fullFilePath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read file: %w", err)
-	}
-
-	data, err := base64.RawURLEncoding.DecodeString(string(encoded))
-	if err != nil {
-		return nil, nil, fmt.Errorf("corrupted file: failed to decode: %w", err)
-	}
-
-	if len(data) < 4 {
-		return nil, nil, errors.New("corrupted file: too short")
-	}
-
-	keyLen := binary.BigEndian.Uint32(data[:4])
-	if len(data) < int(4+keyLen) {
-		return nil, nil, errors.New("corrupted file: invalid key length")
-	}
-
-	encryptedKey := data[4 : 4+keyLen]
-	encryptedValue := data[4+keyLen:]
-
-	return encryptedKey, encryptedValue, nil
+	return readEntry(fullFilePath)
 }
 
 func (v *Vault) SetKey(keyID []byte, encryptedKey []byte, encryptedValue []byte) error {
@@ -87,20 +71,17 @@ func (v *Vault) SetKey(keyID []byte, encryptedKey []byte, encryptedValue []byte)
 		}
 	}
 
-	data := make([]byte, 4+len(encryptedKey)+len(encryptedValue))
-	binary.BigEndian.PutUint32(data[:4], uint32(len(encryptedKey)))
-	copy(data[4:], encryptedKey)
-	copy(data[4+len(encryptedKey):], encryptedValue)
-
-	encoded := base64.RawURLEncoding.EncodeToString(data)
-
 	fullFilePath := filepath.Join(v.storagePath, filePath)
 
-	if err := os.WriteFile(fullFilePath, []byte(encoded), 0600); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if err := v.archiveKey(keyID); err != nil {
+		return fmt.Errorf("failed to archive key: %w", err)
 	}
 
-	return nil
+	if err := writeEntry(fullFilePath, encryptedKey, encryptedValue); err != nil {
+		return err
+	}
+
+	return v.gitCommit(gitMessageUpdate)
 }
 
 func (v *Vault) DeleteKey(keyID []byte) error {
@@ -109,7 +90,11 @@ func (v *Vault) DeleteKey(keyID []byte) error {
 	fullFilePath := filepath.Join(v.storagePath, filePath)
 
 	if _, err := os.Stat(fullFilePath); os.IsNotExist(err) {
-		return errors.New("key not found")
+		return vault.ErrKeyNotFound
+	}
+
+	if err := v.archiveKey(keyID); err != nil {
+		return fmt.Errorf("failed to archive key: %w", err)
 	}
 
 	if err := os.Remove(fullFilePath); err != nil {
@@ -120,5 +105,94 @@ func (v *Vault) DeleteKey(keyID []byte) error {
 		return fmt.Errorf("failed to cleanup storage: %w", err)
 	}
 
+	return v.gitCommit(gitMessageDelete)
+}
+
+func (v *Vault) RenameKey(oldKeyID []byte, newKeyID []byte) error {
+	oldFilePath, _ := getKeyPath(oldKeyID)
+	fullOldFilePath := filepath.Join(v.storagePath, oldFilePath)
+
+	if _, err := os.Stat(fullOldFilePath); os.IsNotExist(err) {
+		return vault.ErrKeyNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to check file: %w", err)
+	}
+
+	newFilePath, newFileDir := getKeyPath(newKeyID)
+	fullNewFilePath := filepath.Join(v.storagePath, newFilePath)
+
+	if _, err := os.Stat(fullNewFilePath); err == nil {
+		return errors.New("key already exists")
+	}
+
+	if err := os.MkdirAll(filepath.Join(v.storagePath, newFileDir), 0700); err != nil {
+		return err
+	}
+
+	if err := os.Rename(fullOldFilePath, fullNewFilePath); err != nil {
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+
+	oldHistoryPath := v.historyPath(oldKeyID)
+	if _, err := os.Stat(oldHistoryPath); err == nil {
+		if err := os.Rename(oldHistoryPath, v.historyPath(newKeyID)); err != nil {
+			return fmt.Errorf("failed to rename history: %w", err)
+		}
+	}
+
+	if err := cleanupStorage(v.storagePath); err != nil {
+		return fmt.Errorf("failed to cleanup storage: %w", err)
+	}
+
+	return v.gitCommit(gitMessageRename)
+}
+
+func readEntry(fullFilePath string) ([]byte, []byte, error) {
+	encoded, err := os.ReadFile(fullFilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return DecodeEntry(encoded)
+}
+
+// DecodeEntry decodes the content of a key file.
+func DecodeEntry(encoded []byte) ([]byte, []byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return nil, nil, fmt.Errorf("corrupted file: failed to decode: %w", err)
+	}
+
+	if len(data) < 4 {
+		return nil, nil, errors.New("corrupted file: too short")
+	}
+
+	keyLen := binary.BigEndian.Uint32(data[:4])
+	if len(data) < int(4+keyLen) {
+		return nil, nil, errors.New("corrupted file: invalid key length")
+	}
+
+	encryptedKey := data[4 : 4+keyLen]
+	encryptedValue := data[4+keyLen:]
+
+	return encryptedKey, encryptedValue, nil
+}
+
+func writeEntry(fullFilePath string, encryptedKey []byte, encryptedValue []byte) error {
+	if err := os.WriteFile(fullFilePath, EncodeEntry(encryptedKey, encryptedValue), 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
 	return nil
 }
+
+// EncodeEntry encodes the content of a key file, the length prefixed
+// encrypted key followed by the encrypted value.
+func EncodeEntry(encryptedKey []byte, encryptedValue []byte) []byte {
+	data := make([]byte, 4+len(encryptedKey)+len(encryptedValue))
+	binary.BigEndian.PutUint32(data[:4], uint32(len(encryptedKey)))
+	copy(data[4:], encryptedKey)
+	copy(data[4+len(encryptedKey):], encryptedValue)
+
+	return []byte(base64.RawURLEncoding.EncodeToString(data))
+}
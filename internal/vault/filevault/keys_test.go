@@ -452,3 +452,68 @@ func TestDeleteKey(t *testing.T) {
 		assert.NoDirExists(t, fullFileDir)
 	})
 }
+
+func TestRenameKey(t *testing.T) {
+	t.Run("successful rename", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		v := New(storagePath)
+		oldKeyID := []byte{0x01, 0x02, 0x03, 0x04}
+		newKeyID := []byte{0xaa, 0xbb, 0xcc, 0xdd}
+		encKey := []byte("enc-key")
+		encValue := []byte("enc-value")
+
+		err = v.SetKey(oldKeyID, encKey, encValue)
+		require.NoError(t, err)
+
+		err = v.RenameKey(oldKeyID, newKeyID)
+		require.NoError(t, err)
+
+		_, _, err = v.GetKey(oldKeyID)
+		assert.Error(t, err)
+
+		retrievedKey, retrievedValue, err := v.GetKey(newKeyID)
+		require.NoError(t, err)
+		assert.Equal(t, encKey, retrievedKey)
+		assert.Equal(t, encValue, retrievedValue)
+
+		// Old directories are cleaned up
+		_, oldFileDir := getKeyPath(oldKeyID)
+		assert.NoDirExists(t, filepath.Join(storagePath, oldFileDir))
+	})
+
+	t.Run("missing source key", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		v := New(storagePath)
+
+		err = v.RenameKey([]byte{0x01, 0x02}, []byte{0x03, 0x04})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "key not found")
+	})
+
+	t.Run("destination exists", func(t *testing.T) {
+		storagePath, err := os.MkdirTemp("", "gopass")
+		require.NoError(t, err)
+		defer os.RemoveAll(storagePath)
+
+		v := New(storagePath)
+		oldKeyID := []byte{0x01, 0x02, 0x03, 0x04}
+		newKeyID := []byte{0xaa, 0xbb, 0xcc, 0xdd}
+
+		require.NoError(t, v.SetKey(oldKeyID, []byte("old-key"), []byte("old-value")))
+		require.NoError(t, v.SetKey(newKeyID, []byte("new-key"), []byte("new-value")))
+
+		err = v.RenameKey(oldKeyID, newKeyID)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "key already exists")
+
+		retrievedKey, _, err := v.GetKey(newKeyID)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("new-key"), retrievedKey)
+	})
+}
@@ -39,5 +39,9 @@ func (v *Vault) SetTestKey(value []byte) error {
 		return err
 	}
 
-	return nil
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return v.gitCommit(gitMessageTestKey)
 }
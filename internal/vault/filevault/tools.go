@@ -28,3 +28,27 @@ func getKeyPath(key []byte) (string, string) {
 
 	return fmt.Sprintf("%s%s", filePath, fileExtension), fileDir
 }
+
+// KeyPath returns the slash separated path of a key in the storage layout,
+// for backends that share it.
+func KeyPath(keyID []byte) string {
+	filePath, _ := getKeyPath(keyID)
+
+	return filepath.ToSlash(filePath)
+}
+
+// ParseKeyPath returns the key ID of a path returned by KeyPath, or false for
+// any other path.
+func ParseKeyPath(keyPath string) ([]byte, bool) {
+	parts := strings.Split(keyPath, "/")
+	if len(parts) != 3 || !strings.HasSuffix(parts[2], fileExtension) {
+		return nil, false
+	}
+
+	keyID, err := base32Encoding.DecodeString(strings.ToUpper(strings.TrimSuffix(parts[2], fileExtension)))
+	if err != nil || len(keyID) < 2 || KeyPath(keyID) != keyPath {
+		return nil, false
+	}
+
+	return keyID, true
+}
@@ -88,3 +88,25 @@ func TestGetKeyPath(t *testing.T) {
 func TestFileExtension(t *testing.T) {
 	assert.Equal(t, ".txt", fileExtension)
 }
+
+func TestKeyPath(t *testing.T) {
+	keyID := []byte{0x01, 0x02, 0x03, 0x04}
+
+	assert.Equal(t, "aq/bq/aebagba.txt", KeyPath(keyID))
+
+	parsed, ok := ParseKeyPath(KeyPath(keyID))
+	assert.True(t, ok)
+	assert.Equal(t, keyID, parsed)
+
+	for _, keyPath := range []string{
+		"aebagba.txt",
+		"aq/bq/aebagba",
+		"aa/bb/aebagba.txt",
+		"aq/bq/aebagba.history/00000001-1.txt",
+		"aq/bq/!!.txt",
+		".test_key",
+	} {
+		_, ok := ParseKeyPath(keyPath)
+		assert.False(t, ok, keyPath)
+	}
+}
@@ -1,14 +1,25 @@
 package filevault
 
 import (
+	"sync"
+
 	"github.com/vitalvas/gopass/internal/vault"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
-var _ vault.Vault = (*Vault)(nil)
+var (
+	_ vault.Vault        = (*Vault)(nil)
+	_ vault.KeyRenamer   = (*Vault)(nil)
+	_ vault.HistoryVault = (*Vault)(nil)
+	_ vault.GitVault     = (*Vault)(nil)
+)
 
 type Vault struct {
 	storagePath string
+	history     *vault.HistoryConfig
+
+	gitIdentityOnce sync.Once
+	gitIdentityEnv  []string
 }
 
 func New(storagePath string) *Vault {
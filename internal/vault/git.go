@@ -0,0 +1,47 @@
+package vault
+
+import "time"
+
+// Commit is an entry of the storage history of a git backed vault.
+type Commit struct {
+	Hash      string
+	Author    string
+	CreatedAt time.Time
+	Message   string
+}
+
+// Conflict is a key changed on both sides of a pull. A nil side means the
+// key was deleted on that side.
+type Conflict struct {
+	KeyID  []byte
+	Ours   *ConflictSide
+	Theirs *ConflictSide
+}
+
+type ConflictSide struct {
+	EncryptedKey   []byte
+	EncryptedValue []byte
+}
+
+type Resolution int
+
+const (
+	ResolveOurs Resolution = iota + 1
+	ResolveTheirs
+)
+
+// GitVault is implemented by backends that can keep the storage in a git
+// repository. Every change is committed when git is enabled.
+type GitVault interface {
+	GitEnabled() bool
+	GitInit(remote string) error
+	GitPush(remote string) error
+	// GitPull merges the remote changes and returns the keys that conflict.
+	// Conflicts are resolved with ResolveConflict and the merge completed
+	// with FinishMerge, or undone with AbortMerge.
+	GitPull(remote string) ([]Conflict, error)
+	ResolveConflict(conflict Conflict, resolution Resolution) error
+	FinishMerge() error
+	AbortMerge() error
+	GitLog(limit int) ([]Commit, error)
+}
@@ -0,0 +1,25 @@
+package vault
+
+import "time"
+
+type Revision struct {
+	Number    int
+	CreatedAt time.Time
+}
+
+type HistoryConfig struct {
+	Disabled     bool `json:"disabled,omitempty"`
+	MaxRevisions int  `json:"max_revisions,omitempty"`
+	MaxAgeDays   int  `json:"max_age_days,omitempty"`
+}
+
+// HistoryVault is implemented by backends that keep the previous encrypted
+// values of a key on every SetKey and DeleteKey.
+type HistoryVault interface {
+	SetHistory(cfg *HistoryConfig)
+	ListRevisions(keyID []byte) ([]Revision, error)
+	GetRevision(keyID []byte, number int) (encryptedKey []byte, encryptedValue []byte, err error)
+	SetRevision(keyID []byte, number int, encryptedKey []byte, encryptedValue []byte) error
+	ReplaceKey(keyID []byte, encryptedKey []byte, encryptedValue []byte) error
+	PurgeRevisions(keyID []byte) error
+}
@@ -0,0 +1,42 @@
+// Package httpvault serves a vault backend over HTTPS and provides the
+// matching client backend. Only key IDs and encrypted blobs are exchanged,
+// the server never sees plaintext.
+package httpvault
+
+import "encoding/base64"
+
+const (
+	apiPrefix = "/v1"
+
+	// maxBodySize limits request and response bodies, stored values are
+	// far smaller.
+	maxBodySize = 8 << 20
+)
+
+// Byte slices are encoded as standard base64 in JSON.
+
+type keyList struct {
+	Keys [][]byte `json:"keys"`
+}
+
+type keyEntry struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type testKey struct {
+	Value []byte `json:"value"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// encodeKeyID encodes a key ID for use in a URL path.
+func encodeKeyID(keyID []byte) string {
+	return base64.RawURLEncoding.EncodeToString(keyID)
+}
+
+func decodeKeyID(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,213 @@
+package httpvault
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+// Server exposes a vault backend over HTTP. Requests are served one write at
+// a time, as the backends are not safe for concurrent writers.
+type Server struct {
+	store vault.Vault
+	token string
+	mux   *http.ServeMux
+	mu    sync.RWMutex
+}
+
+// NewServer returns a server for store. With a token, requests must carry it
+// as a bearer token.
+func NewServer(store vault.Vault, token string) *Server {
+	s := &Server{
+		store: store,
+		token: token,
+		mux:   http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET "+apiPrefix+"/keys", s.listKeys)
+	s.mux.HandleFunc("GET "+apiPrefix+"/keys/{id}", s.getKey)
+	s.mux.HandleFunc("PUT "+apiPrefix+"/keys/{id}", s.setKey)
+	s.mux.HandleFunc("DELETE "+apiPrefix+"/keys/{id}", s.deleteKey)
+	s.mux.HandleFunc("GET "+apiPrefix+"/test-key", s.getTestKey)
+	s.mux.HandleFunc("PUT "+apiPrefix+"/test-key", s.setTestKey)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *Server) listKeys(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	keys, err := s.store.ListKeys()
+	s.mu.RUnlock()
+
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	if keys == nil {
+		keys = [][]byte{}
+	}
+
+	writeJSON(w, http.StatusOK, keyList{Keys: keys})
+}
+
+func (s *Server) getKey(w http.ResponseWriter, r *http.Request) {
+	keyID, ok := pathKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.RLock()
+	encryptedKey, encryptedValue, err := s.store.GetKey(keyID)
+	s.mu.RUnlock()
+
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, keyEntry{Key: encryptedKey, Value: encryptedValue})
+}
+
+func (s *Server) setKey(w http.ResponseWriter, r *http.Request) {
+	keyID, ok := pathKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	var entry keyEntry
+	if !readJSON(w, r, &entry) {
+		return
+	}
+
+	s.mu.Lock()
+	err := s.store.SetKey(keyID, entry.Key, entry.Value)
+	s.mu.Unlock()
+
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteKey(w http.ResponseWriter, r *http.Request) {
+	keyID, ok := pathKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	err := s.store.DeleteKey(keyID)
+	s.mu.Unlock()
+
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getTestKey(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	value, err := s.store.GetTestKey()
+	s.mu.RUnlock()
+
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, testKey{Value: value})
+}
+
+func (s *Server) setTestKey(w http.ResponseWriter, r *http.Request) {
+	var key testKey
+	if !readJSON(w, r, &key) {
+		return
+	}
+
+	s.mu.Lock()
+	err := s.store.SetTestKey(key.Value)
+	s.mu.Unlock()
+
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func pathKeyID(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	keyID, err := decodeKeyID(r.PathValue("id"))
+	if err != nil || len(keyID) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid key id")
+		return nil, false
+	}
+
+	return keyID, true
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+
+	return true
+}
+
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, vault.ErrKeyNotFound) {
+		writeError(w, http.StatusNotFound, vault.ErrKeyNotFound.Error())
+		return
+	}
+
+	// Backend errors may contain server paths, clients only get a generic
+	// message.
+	log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+
+	writeError(w, http.StatusInternalServerError, "internal error")
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(v)
+}
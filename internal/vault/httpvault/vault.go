@@ -0,0 +1,204 @@
+package httpvault
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ vault.Vault = (*Vault)(nil)
+)
+
+// TokenEnv holds the bearer token when the address sets no token_file.
+const TokenEnv = "GOPASS_SERVER_TOKEN"
+
+const requestTimeout = 30 * time.Second
+
+// Vault is the client of a vault served by "gopass serve".
+type Vault struct {
+	endpoint *url.URL
+	token    string
+	http     *http.Client
+}
+
+// New opens the vault of an https://host[:port][/path] address. The query may
+// set token_file, ca, and cert and key for a client certificate.
+func New(address *url.URL) (*Vault, error) {
+	query := address.Query()
+
+	token := os.Getenv(TokenEnv)
+
+	if tokenFile := query.Get("token_file"); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token file: %w", err)
+		}
+
+		token = strings.TrimSpace(string(data))
+	}
+
+	tlsConfig, err := clientTLSConfig(query.Get("ca"), query.Get("cert"), query.Get("key"))
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{
+		Timeout:   requestTimeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+
+	return newVault(address, token, httpClient)
+}
+
+func newVault(address *url.URL, token string, httpClient *http.Client) (*Vault, error) {
+	if address.Scheme != "https" || address.Host == "" {
+		return nil, fmt.Errorf("invalid server address: %s", address.Redacted())
+	}
+
+	endpoint := &url.URL{
+		Scheme: address.Scheme,
+		Host:   address.Host,
+		Path:   strings.TrimSuffix(address.Path, "/") + apiPrefix,
+	}
+
+	return &Vault{
+		endpoint: endpoint,
+		token:    token,
+		http:     httpClient,
+	}, nil
+}
+
+func (v *Vault) Close() error {
+	v.http.CloseIdleConnections()
+
+	return nil
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response
+// into out. Error responses are turned into errors.
+func (v *Vault) do(method, path string, in, out any) error {
+	var body io.Reader
+
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	u := *v.endpoint
+	u.Path += path
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if v.token != "" {
+		req.Header.Set("Authorization", "Bearer "+v.token)
+	}
+
+	resp, err := v.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	reader := io.LimitReader(resp.Body, maxBodySize)
+
+	if resp.StatusCode >= 300 {
+		if resp.StatusCode == http.StatusNotFound {
+			return vault.ErrKeyNotFound
+		}
+
+		var errResp errorResponse
+		if json.NewDecoder(reader).Decode(&errResp) == nil && errResp.Error != "" {
+			return fmt.Errorf("server: %s", errResp.Error)
+		}
+
+		return fmt.Errorf("server: unexpected status: %s", resp.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(reader).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+func (v *Vault) ListKeys() ([][]byte, error) {
+	var list keyList
+	if err := v.do(http.MethodGet, "/keys", nil, &list); err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	return list.Keys, nil
+}
+
+func (v *Vault) GetKey(keyID []byte) ([]byte, []byte, error) {
+	var entry keyEntry
+
+	err := v.do(http.MethodGet, "/keys/"+encodeKeyID(keyID), nil, &entry)
+	if errors.Is(err, vault.ErrKeyNotFound) {
+		return nil, nil, err
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	return entry.Key, entry.Value, nil
+}
+
+func (v *Vault) SetKey(keyID []byte, encryptedKey []byte, encryptedValue []byte) error {
+	entry := keyEntry{Key: encryptedKey, Value: encryptedValue}
+
+	if err := v.do(http.MethodPut, "/keys/"+encodeKeyID(keyID), entry, nil); err != nil {
+		return fmt.Errorf("failed to write key: %w", err)
+	}
+
+	return nil
+}
+
+func (v *Vault) DeleteKey(keyID []byte) error {
+	err := v.do(http.MethodDelete, "/keys/"+encodeKeyID(keyID), nil, nil)
+	if errors.Is(err, vault.ErrKeyNotFound) {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("failed to delete key: %w", err)
+	}
+
+	return nil
+}
+
+func (v *Vault) GetTestKey() ([]byte, error) {
+	var key testKey
+	if err := v.do(http.MethodGet, "/test-key", nil, &key); err != nil {
+		return nil, err
+	}
+
+	return key.Value, nil
+}
+
+func (v *Vault) SetTestKey(value []byte) error {
+	return v.do(http.MethodPut, "/test-key", testKey{Value: value}, nil)
+}
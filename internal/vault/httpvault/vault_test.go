@@ -0,0 +1,328 @@
+package httpvault
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+	"github.com/vitalvas/gopass/internal/vault/filevault"
+)
+
+// startServer serves a file vault over TLS and returns the address of the
+// client, which trusts the server certificate.
+func startServer(t *testing.T, token string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	ts := httptest.NewTLSServer(NewServer(filevault.New(t.TempDir()), token))
+	t.Cleanup(ts.Close)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", ts.Certificate().Raw)
+
+	return ts.URL + "/?ca=" + url.QueryEscape(caFile)
+}
+
+func openVault(t *testing.T, address string) *Vault {
+	t.Helper()
+
+	parsed, err := url.Parse(address)
+	require.NoError(t, err)
+
+	v, err := New(parsed)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { v.Close() })
+
+	return v
+}
+
+func writeTokenFile(t *testing.T, token string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte(token+"\n"), 0600))
+
+	return path
+}
+
+func writePEM(t *testing.T, path, blockType string, data []byte) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0600))
+}
+
+func TestVault(t *testing.T) {
+	t.Setenv(TokenEnv, "")
+
+	v := openVault(t, startServer(t, ""))
+
+	t.Run("test key", func(t *testing.T) {
+		_, err := v.GetTestKey()
+		assert.Error(t, err)
+
+		require.NoError(t, v.SetTestKey([]byte("test-value")))
+
+		value, err := v.GetTestKey()
+		require.NoError(t, err)
+		assert.Equal(t, []byte("test-value"), value)
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		keys, err := v.ListKeys()
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+
+		require.NoError(t, v.SetKey([]byte{0xfb, 0xff, 0x01}, []byte("key1"), []byte("value1")))
+		require.NoError(t, v.SetKey([]byte{0x02, 0x03, 0x04}, []byte("key2"), []byte("value2")))
+
+		keys, err = v.ListKeys()
+		require.NoError(t, err)
+		assert.ElementsMatch(t, [][]byte{{0xfb, 0xff, 0x01}, {0x02, 0x03, 0x04}}, keys)
+
+		key, value, err := v.GetKey([]byte{0xfb, 0xff, 0x01})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("key1"), key)
+		assert.Equal(t, []byte("value1"), value)
+
+		require.NoError(t, v.DeleteKey([]byte{0xfb, 0xff, 0x01}))
+
+		_, _, err = v.GetKey([]byte{0xfb, 0xff, 0x01})
+		assert.ErrorIs(t, err, vault.ErrKeyNotFound)
+
+		assert.ErrorIs(t, v.DeleteKey([]byte{0xfb, 0xff, 0x01}), vault.ErrKeyNotFound)
+	})
+}
+
+func TestVaultToken(t *testing.T) {
+	address := startServer(t, "secret")
+
+	t.Run("token file", func(t *testing.T) {
+		t.Setenv(TokenEnv, "")
+
+		v := openVault(t, address+"&token_file="+url.QueryEscape(writeTokenFile(t, "secret")))
+
+		require.NoError(t, v.SetTestKey([]byte("test")))
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv(TokenEnv, "secret")
+
+		v := openVault(t, address)
+
+		_, err := v.GetTestKey()
+		assert.NoError(t, err)
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		t.Setenv(TokenEnv, "wrong")
+
+		v := openVault(t, address)
+
+		_, err := v.ListKeys()
+		assert.EqualError(t, err, "failed to list keys: server: unauthorized")
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		t.Setenv(TokenEnv, "")
+
+		v := openVault(t, address)
+
+		_, err := v.GetTestKey()
+		assert.EqualError(t, err, "server: unauthorized")
+	})
+}
+
+func TestVaultMutualTLS(t *testing.T) {
+	t.Setenv(TokenEnv, "")
+
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", caDER)
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		writePEM(t, filepath.Join(dir, name+".pem"), "CERTIFICATE", der)
+		writePEM(t, filepath.Join(dir, name+"-key.pem"), "EC PRIVATE KEY", keyDER)
+	}
+
+	issue("server", 2, x509.ExtKeyUsageServerAuth)
+	issue("client", 3, x509.ExtKeyUsageClientAuth)
+
+	tlsConfig, err := ServerTLSConfig(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), filepath.Join(dir, "ca.pem"))
+	require.NoError(t, err)
+
+	ts := httptest.NewUnstartedServer(NewServer(filevault.New(t.TempDir()), ""))
+	ts.TLS = tlsConfig
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+
+	address := ts.URL + "/?ca=" + url.QueryEscape(filepath.Join(dir, "ca.pem"))
+
+	t.Run("with client certificate", func(t *testing.T) {
+		v := openVault(t, address+
+			"&cert="+url.QueryEscape(filepath.Join(dir, "client.pem"))+
+			"&key="+url.QueryEscape(filepath.Join(dir, "client-key.pem")))
+
+		require.NoError(t, v.SetTestKey([]byte("test")))
+	})
+
+	t.Run("without client certificate", func(t *testing.T) {
+		v := openVault(t, address)
+
+		_, err := v.GetTestKey()
+		assert.Error(t, err)
+	})
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		errMsg  string
+	}{
+		{
+			name:    "plain http",
+			address: "http://localhost:8443",
+			errMsg:  "invalid server address: http://localhost:8443",
+		},
+		{
+			name:    "missing host",
+			address: "https:///vault",
+			errMsg:  "invalid server address: https:///vault",
+		},
+		{
+			name:    "certificate without key",
+			address: "https://localhost:8443?cert=/tmp/client.pem",
+			errMsg:  "client certificate and key must be set together",
+		},
+		{
+			name:    "missing token file",
+			address: "https://localhost:8443?token_file=/nonexistent/token",
+			errMsg:  "failed to read token file: open /nonexistent/token: no such file or directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := url.Parse(tt.address)
+			require.NoError(t, err)
+
+			_, err = New(parsed)
+			assert.EqualError(t, err, tt.errMsg)
+		})
+	}
+
+	t.Run("path prefix", func(t *testing.T) {
+		parsed, err := url.Parse("https://localhost:8443/gopass/team/")
+		require.NoError(t, err)
+
+		v, err := New(parsed)
+		require.NoError(t, err)
+
+		assert.Equal(t, "https://localhost:8443/gopass/team/v1", v.endpoint.String())
+	})
+}
+
+func TestServerInvalidRequests(t *testing.T) {
+	server := NewServer(filevault.New(t.TempDir()), "")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "invalid key id", method: http.MethodGet, path: "/v1/keys/!!", status: http.StatusBadRequest},
+		{name: "invalid body", method: http.MethodPut, path: "/v1/keys/AQID", body: "{", status: http.StatusBadRequest},
+		{name: "unknown path", method: http.MethodGet, path: "/v1/unknown", status: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodPost, path: "/v1/test-key", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			server.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+// failingVault fails every call with an error that names a server path.
+type failingVault struct {
+	vault.Vault
+}
+
+func (failingVault) ListKeys() ([][]byte, error) {
+	return nil, errors.New("open /srv/gopass/vault: permission denied")
+}
+
+func TestServerInternalError(t *testing.T) {
+	server := NewServer(failingVault{}, "")
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/keys", nil)
+	rec := httptest.NewRecorder()
+
+	server.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"error":"internal error"}`, rec.Body.String())
+}
@@ -2,14 +2,38 @@ package vault
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 )
 
+const (
+	FieldPassword = "password"
+	FieldUsername = "username"
+	FieldURL      = "url"
+	FieldNotes    = "notes"
+)
+
+var validateFieldNameRegex = regexp.MustCompile("^[0-9a-zA-Z-_.]{1,64}$")
+
 type Payload struct {
-	Data    string   `json:"d"`
-	OTP     *OTP     `json:"otp,omitempty"`
-	Passkey *Passkey `json:"passkey,omitempty"`
-	GPGKey  *GPGKey  `json:"gpg,omitempty"`
+	Data     string            `json:"d"`
+	Username string            `json:"u,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Notes    string            `json:"n,omitempty"`
+	Fields   map[string]string `json:"f,omitempty"`
+	OTP      *OTP              `json:"otp,omitempty"`
+	Passkey  *Passkey          `json:"passkey,omitempty"`
+	GPGKey   *GPGKey           `json:"gpg,omitempty"`
+
+	CreatedAt  time.Time `json:"ct,omitzero"`
+	ModifiedAt time.Time `json:"mt,omitzero"`
+	// ExpiresAt and MaxAgeDays are optional, with both set the earlier
+	// expiry applies.
+	ExpiresAt  time.Time `json:"exp,omitzero"`
+	MaxAgeDays int       `json:"max_age,omitempty"`
 }
 
 type OTP struct {
@@ -43,6 +67,44 @@ func (p *Payload) Marshal() ([]byte, error) {
 	return json.Marshal(p)
 }
 
+// Touch records a write of the payload.
+func (p *Payload) Touch(now time.Time) {
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+	}
+
+	p.ModifiedAt = now
+}
+
+// Expiry returns when the payload expires. The max age counts from the last
+// modification, entries without timestamps never expire by age.
+func (p *Payload) Expiry() (time.Time, bool) {
+	expiry := p.ExpiresAt
+
+	if p.MaxAgeDays > 0 {
+		changed := p.ModifiedAt
+		if changed.IsZero() {
+			changed = p.CreatedAt
+		}
+
+		if !changed.IsZero() {
+			maxAge := changed.AddDate(0, 0, p.MaxAgeDays)
+
+			if expiry.IsZero() || maxAge.Before(expiry) {
+				expiry = maxAge
+			}
+		}
+	}
+
+	return expiry, !expiry.IsZero()
+}
+
+func (p *Payload) Expired(now time.Time) bool {
+	expiry, ok := p.Expiry()
+
+	return ok && !now.Before(expiry)
+}
+
 func PayloadUnmarshal(data []byte) (*Payload, error) {
 	var p = &Payload{}
 
@@ -53,3 +115,110 @@ func PayloadUnmarshal(data []byte) (*Payload, error) {
 
 	return p, nil
 }
+
+func ValidateFieldName(name string) error {
+	if !validateFieldNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid field name: %s", name)
+	}
+
+	return nil
+}
+
+// ParseField splits a "name=value" field definition.
+func ParseField(field string) (string, string, error) {
+	name, value, ok := strings.Cut(field, "=")
+	if !ok {
+		return "", "", fmt.Errorf("invalid field: %s (expected name=value)", field)
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if err := ValidateFieldName(name); err != nil {
+		return "", "", err
+	}
+
+	return name, value, nil
+}
+
+func (p *Payload) GetField(name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case FieldPassword:
+		return p.Data, p.Data != ""
+
+	case FieldUsername:
+		return p.Username, p.Username != ""
+
+	case FieldURL:
+		return p.URL, p.URL != ""
+
+	case FieldNotes:
+		return p.Notes, p.Notes != ""
+	}
+
+	value, ok := p.Fields[strings.ToLower(name)]
+
+	return value, ok
+}
+
+// SetField sets a typed or custom field, an empty value removes it.
+func (p *Payload) SetField(name, value string) error {
+	name = strings.ToLower(name)
+
+	if err := ValidateFieldName(name); err != nil {
+		return err
+	}
+
+	switch name {
+	case FieldPassword:
+		p.Data = value
+
+	case FieldUsername:
+		p.Username = value
+
+	case FieldURL:
+		p.URL = value
+
+	case FieldNotes:
+		p.Notes = value
+
+	default:
+		if value == "" {
+			delete(p.Fields, name)
+
+			if len(p.Fields) == 0 {
+				p.Fields = nil
+			}
+
+			return nil
+		}
+
+		if p.Fields == nil {
+			p.Fields = make(map[string]string)
+		}
+
+		p.Fields[name] = value
+	}
+
+	return nil
+}
+
+// FieldNames returns the names of all non-empty fields except the password,
+// typed fields first and custom fields sorted by name.
+func (p *Payload) FieldNames() []string {
+	names := make([]string, 0, 3+len(p.Fields))
+
+	for _, name := range []string{FieldUsername, FieldURL, FieldNotes} {
+		if _, ok := p.GetField(name); ok {
+			names = append(names, name)
+		}
+	}
+
+	custom := make([]string, 0, len(p.Fields))
+	for name := range p.Fields {
+		custom = append(custom, name)
+	}
+
+	sort.Strings(custom)
+
+	return append(names, custom...)
+}
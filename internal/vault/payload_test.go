@@ -3,7 +3,9 @@ package vault
 import (
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPayloadMarshal(t *testing.T) {
@@ -53,3 +55,195 @@ func TestPayloadUnmarshal(t *testing.T) {
 	})
 
 }
+
+func TestPayloadUnmarshalLegacy(t *testing.T) {
+	payload, err := PayloadUnmarshal([]byte(`{"d":"secret","otp":{"s":"JBSWY3DPEHPK3PXP"}}`))
+	if err != nil {
+		t.Fatalf("PayloadUnmarshal() returned error: %v", err)
+	}
+
+	if payload.Data != "secret" || payload.Username != "" || payload.Fields != nil {
+		t.Errorf("PayloadUnmarshal() returned unexpected payload: %+v", payload)
+	}
+
+	if payload.OTP == nil || payload.OTP.Secret != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("PayloadUnmarshal() lost OTP section: %+v", payload.OTP)
+	}
+}
+
+func TestPayloadFields(t *testing.T) {
+	payload := &Payload{Data: "secret"}
+
+	for _, tc := range []struct {
+		name  string
+		value string
+	}{
+		{"username", "alice"},
+		{"URL", "https://example.com"},
+		{"notes", "line one\nline two"},
+		{"pin", "1234"},
+		{"security.question", "blue"},
+	} {
+		if err := payload.SetField(tc.name, tc.value); err != nil {
+			t.Fatalf("SetField(%q) returned error: %v", tc.name, err)
+		}
+	}
+
+	if payload.Username != "alice" || payload.URL != "https://example.com" || payload.Notes != "line one\nline two" {
+		t.Errorf("SetField() did not set typed fields: %+v", payload)
+	}
+
+	if value, ok := payload.GetField("password"); !ok || value != "secret" {
+		t.Errorf("GetField(password) = %q, %v", value, ok)
+	}
+
+	if value, ok := payload.GetField("PIN"); !ok || value != "1234" {
+		t.Errorf("GetField(PIN) = %q, %v", value, ok)
+	}
+
+	if _, ok := payload.GetField("missing"); ok {
+		t.Errorf("GetField(missing) reported existing field")
+	}
+
+	expectedNames := []string{"username", "url", "notes", "pin", "security.question"}
+	if names := payload.FieldNames(); !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("FieldNames() = %v, expected %v", names, expectedNames)
+	}
+
+	data, err := payload.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() returned error: %v", err)
+	}
+
+	decoded, err := PayloadUnmarshal(data)
+	if err != nil {
+		t.Fatalf("PayloadUnmarshal() returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded, payload) {
+		t.Errorf("roundtrip mismatch. Expected: %+v, Got: %+v", payload, decoded)
+	}
+
+	if err := payload.SetField("pin", ""); err != nil {
+		t.Fatalf("SetField(pin, \"\") returned error: %v", err)
+	}
+
+	if err := payload.SetField("security.question", ""); err != nil {
+		t.Fatalf("SetField(security.question, \"\") returned error: %v", err)
+	}
+
+	if payload.Fields != nil {
+		t.Errorf("SetField() with empty values did not remove custom fields: %v", payload.Fields)
+	}
+
+	if err := payload.SetField("bad name", "value"); err == nil {
+		t.Errorf("SetField() accepted invalid field name")
+	}
+}
+
+func TestParseField(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		name  string
+		value string
+		valid bool
+	}{
+		{"username=alice", "username", "alice", true},
+		{"URL=https://example.com/?a=b", "url", "https://example.com/?a=b", true},
+		{"empty=", "empty", "", true},
+		{"novalue", "", "", false},
+		{"=value", "", "", false},
+		{"bad name=value", "", "", false},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			name, value, err := ParseField(tc.input)
+			if tc.valid && err != nil {
+				t.Fatalf("expected valid field, got error: %v", err)
+			}
+
+			if !tc.valid {
+				if err == nil {
+					t.Errorf("expected invalid field, got no error")
+				}
+
+				return
+			}
+
+			if name != tc.name || value != tc.value {
+				t.Errorf("ParseField() = %q, %q, expected %q, %q", name, value, tc.name, tc.value)
+			}
+		})
+	}
+}
+
+func TestPayloadTouch(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	modified := created.Add(time.Hour)
+
+	payload := &Payload{Data: "test"}
+	payload.Touch(created)
+	payload.Touch(modified)
+
+	if !payload.CreatedAt.Equal(created) {
+		t.Errorf("CreatedAt = %v, want %v", payload.CreatedAt, created)
+	}
+
+	if !payload.ModifiedAt.Equal(modified) {
+		t.Errorf("ModifiedAt = %v, want %v", payload.ModifiedAt, modified)
+	}
+
+	data, err := payload.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() returned error: %v", err)
+	}
+
+	if strings.Contains(string(data), `"exp"`) {
+		t.Errorf("Marshal() should omit the zero expiry: %s", data)
+	}
+
+	decoded, err := PayloadUnmarshal(data)
+	if err != nil {
+		t.Fatalf("PayloadUnmarshal() returned error: %v", err)
+	}
+
+	if !decoded.ModifiedAt.Equal(modified) {
+		t.Errorf("decoded ModifiedAt = %v, want %v", decoded.ModifiedAt, modified)
+	}
+}
+
+func TestPayloadExpiry(t *testing.T) {
+	modified := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		payload Payload
+		expiry  time.Time
+		ok      bool
+	}{
+		{"no expiry", Payload{ModifiedAt: modified}, time.Time{}, false},
+		{"expires at", Payload{ExpiresAt: modified.AddDate(0, 6, 0)}, modified.AddDate(0, 6, 0), true},
+		{"max age", Payload{ModifiedAt: modified, MaxAgeDays: 90}, modified.AddDate(0, 0, 90), true},
+		{"max age from created", Payload{CreatedAt: modified, MaxAgeDays: 30}, modified.AddDate(0, 0, 30), true},
+		{"max age without timestamps", Payload{MaxAgeDays: 30}, time.Time{}, false},
+		{"earlier of both", Payload{ModifiedAt: modified, MaxAgeDays: 90, ExpiresAt: modified.AddDate(0, 0, 10)}, modified.AddDate(0, 0, 10), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiry, ok := tt.payload.Expiry()
+			if ok != tt.ok || !expiry.Equal(tt.expiry) {
+				t.Errorf("Expiry() = %v, %v, want %v, %v", expiry, ok, tt.expiry, tt.ok)
+			}
+		})
+	}
+
+	payload := &Payload{ExpiresAt: modified}
+
+	if !payload.Expired(modified) {
+		t.Error("Expired() should be true at the expiry time")
+	}
+
+	if payload.Expired(modified.Add(-time.Second)) {
+		t.Error("Expired() should be false before the expiry time")
+	}
+}
@@ -0,0 +1,100 @@
+// Package pluginvault runs vault backends as external programs. A
+// plugin+name:// address starts gopass-backend-name, which speaks JSON-RPC
+// 2.0 over its stdin and stdout, one message per line.
+//
+// The first request is initialize with the protocol version and the vault
+// address, the other methods mirror vault.Vault. Byte fields are standard
+// base64. A missing key is reported with error code 1, other backend errors
+// with code 2.
+package pluginvault
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion is the version of the plugin protocol. Plugins must reply
+// to initialize with the same version.
+const ProtocolVersion = 1
+
+const (
+	methodInitialize = "initialize"
+	methodListKeys   = "list_keys"
+	methodGetKey     = "get_key"
+	methodSetKey     = "set_key"
+	methodDeleteKey  = "delete_key"
+	methodRenameKey  = "rename_key"
+	methodGetTestKey = "get_test_key"
+	methodSetTestKey = "set_test_key"
+	methodClose      = "close"
+)
+
+// capabilityRenameKey is reported by plugins that implement rename_key.
+const capabilityRenameKey = "rename_key"
+
+// Error codes, besides the JSON-RPC ones for invalid params and unknown
+// methods.
+const (
+	codeKeyNotFound   = 1
+	codeBackendError  = 2
+	codeUnknownMethod = -32601
+	codeInvalidParams = -32602
+)
+
+const jsonrpcVersion = "2.0"
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("plugin: %s", e.Message)
+}
+
+type initializeParams struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Address         string `json:"address"`
+}
+
+type initializeResult struct {
+	ProtocolVersion int      `json:"protocol_version"`
+	Capabilities    []string `json:"capabilities"`
+}
+
+type keyIDParams struct {
+	KeyID []byte `json:"key_id"`
+}
+
+type keyList struct {
+	Keys [][]byte `json:"keys"`
+}
+
+type keyEntry struct {
+	KeyID          []byte `json:"key_id,omitempty"`
+	EncryptedKey   []byte `json:"encrypted_key"`
+	EncryptedValue []byte `json:"encrypted_value"`
+}
+
+type renameKeyParams struct {
+	OldKeyID []byte `json:"old_key_id"`
+	NewKeyID []byte `json:"new_key_id"`
+}
+
+type testKey struct {
+	Value []byte `json:"value"`
+}
@@ -0,0 +1,220 @@
+package pluginvault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+// OpenFunc opens the backend of a plugin for the vault address.
+type OpenFunc func(address string) (vault.Vault, error)
+
+type invalidParamsError struct {
+	err error
+}
+
+func (e invalidParamsError) Error() string {
+	return fmt.Sprintf("invalid params: %v", e.err)
+}
+
+type unknownMethodError string
+
+func (e unknownMethodError) Error() string {
+	return fmt.Sprintf("unknown method: %s", string(e))
+}
+
+// Serve implements the plugin side of the protocol over the backend returned
+// by open. It returns after close, or when in ends.
+func Serve(in io.Reader, out io.Writer, open OpenFunc) error {
+	s := &server{open: open}
+
+	defer func() {
+		if s.store != nil {
+			s.store.Close()
+		}
+	}()
+
+	decoder := json.NewDecoder(in)
+	encoder := json.NewEncoder(out)
+
+	for {
+		var req request
+
+		if err := decoder.Decode(&req); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+
+		resp := response{JSONRPC: jsonrpcVersion, ID: req.ID}
+
+		result, err := s.handle(req)
+		if err == nil {
+			resp.Result, err = json.Marshal(result)
+		}
+
+		if err != nil {
+			resp.Error = &rpcError{Code: errorCode(err), Message: err.Error()}
+		}
+
+		if err := encoder.Encode(resp); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+
+		if req.Method == methodClose {
+			return nil
+		}
+	}
+}
+
+func errorCode(err error) int {
+	var paramsErr invalidParamsError
+	var methodErr unknownMethodError
+
+	switch {
+	case errors.Is(err, vault.ErrKeyNotFound):
+		return codeKeyNotFound
+	case errors.As(err, &paramsErr):
+		return codeInvalidParams
+	case errors.As(err, &methodErr):
+		return codeUnknownMethod
+	default:
+		return codeBackendError
+	}
+}
+
+type server struct {
+	open  OpenFunc
+	store vault.Vault
+}
+
+func (s *server) handle(req request) (any, error) {
+	if req.Method == methodInitialize {
+		return s.initialize(req.Params)
+	}
+
+	if s.store == nil {
+		return nil, errors.New("not initialized")
+	}
+
+	switch req.Method {
+	case methodListKeys:
+		keys, err := s.store.ListKeys()
+		if err != nil {
+			return nil, err
+		}
+
+		if keys == nil {
+			keys = [][]byte{}
+		}
+
+		return keyList{Keys: keys}, nil
+
+	case methodGetKey:
+		var params keyIDParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		encryptedKey, encryptedValue, err := s.store.GetKey(params.KeyID)
+		if err != nil {
+			return nil, err
+		}
+
+		return keyEntry{EncryptedKey: encryptedKey, EncryptedValue: encryptedValue}, nil
+
+	case methodSetKey:
+		var params keyEntry
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return nil, s.store.SetKey(params.KeyID, params.EncryptedKey, params.EncryptedValue)
+
+	case methodDeleteKey:
+		var params keyIDParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return nil, s.store.DeleteKey(params.KeyID)
+
+	case methodRenameKey:
+		renamer, ok := s.store.(vault.KeyRenamer)
+		if !ok {
+			return nil, unknownMethodError(req.Method)
+		}
+
+		var params renameKeyParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return nil, renamer.RenameKey(params.OldKeyID, params.NewKeyID)
+
+	case methodGetTestKey:
+		value, err := s.store.GetTestKey()
+		if err != nil {
+			return nil, err
+		}
+
+		return testKey{Value: value}, nil
+
+	case methodSetTestKey:
+		var params testKey
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return nil, s.store.SetTestKey(params.Value)
+
+	case methodClose:
+		store := s.store
+		s.store = nil
+
+		return nil, store.Close()
+
+	default:
+		return nil, unknownMethodError(req.Method)
+	}
+}
+
+func (s *server) initialize(raw json.RawMessage) (any, error) {
+	if s.store != nil {
+		return nil, errors.New("already initialized")
+	}
+
+	var params initializeParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	if params.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("unsupported protocol version: %d", params.ProtocolVersion)
+	}
+
+	store, err := s.open(params.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	s.store = store
+
+	capabilities := []string{}
+	if _, ok := store.(vault.KeyRenamer); ok {
+		capabilities = append(capabilities, capabilityRenameKey)
+	}
+
+	return initializeResult{ProtocolVersion: ProtocolVersion, Capabilities: capabilities}, nil
+}
+
+func decodeParams(raw json.RawMessage, v any) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return invalidParamsError{err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package pluginvault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ vault.Vault      = (*Vault)(nil)
+	_ vault.KeyRenamer = renamingVault{}
+)
+
+const (
+	schemePrefix     = "plugin+"
+	executablePrefix = "gopass-backend-"
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Vault is the client of a plugin process.
+type Vault struct {
+	stdin   io.WriteCloser
+	decoder *json.Decoder
+	wait    func() error
+
+	mu     sync.Mutex
+	nextID uint64
+	closed bool
+}
+
+// renamingVault is returned for plugins with the rename_key capability.
+type renamingVault struct {
+	*Vault
+}
+
+// IsAddress reports whether the address selects a plugin.
+func IsAddress(address *url.URL) bool {
+	return strings.HasPrefix(address.Scheme, schemePrefix)
+}
+
+// New starts the plugin of a plugin+name:// address, the gopass-backend-name
+// executable in PATH, and passes it the address.
+func New(address *url.URL) (vault.Vault, error) {
+	name := strings.TrimPrefix(address.Scheme, schemePrefix)
+	if !IsAddress(address) || !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid plugin address: %s", address.Redacted())
+	}
+
+	path, err := exec.LookPath(executablePrefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s not found: %w", name, err)
+	}
+
+	cmd := exec.Command(path)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	return connect(stdin, stdout, cmd.Wait, address.String())
+}
+
+// connect initializes the plugin on the other end of stdin and stdout. wait
+// returns when the plugin has exited.
+func connect(stdin io.WriteCloser, stdout io.Reader, wait func() error, address string) (vault.Vault, error) {
+	v := &Vault{
+		stdin:   stdin,
+		decoder: json.NewDecoder(stdout),
+		wait:    wait,
+	}
+
+	var result initializeResult
+
+	err := v.call(methodInitialize, initializeParams{ProtocolVersion: ProtocolVersion, Address: address}, &result)
+	if err == nil && result.ProtocolVersion != ProtocolVersion {
+		err = fmt.Errorf("unsupported protocol version: %d", result.ProtocolVersion)
+	}
+
+	if err != nil {
+		v.stdin.Close()
+		v.wait()
+
+		return nil, fmt.Errorf("failed to initialize plugin: %w", err)
+	}
+
+	if slices.Contains(result.Capabilities, capabilityRenameKey) {
+		return renamingVault{v}, nil
+	}
+
+	return v, nil
+}
+
+// call sends a request and decodes the result of its response into result.
+// Requests are sent one at a time.
+func (v *Vault) call(method string, params, result any) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.closed {
+		return errors.New("plugin is closed")
+	}
+
+	v.nextID++
+
+	req := request{JSONRPC: jsonrpcVersion, ID: v.nextID, Method: method}
+
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+
+		req.Params = data
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	if _, err := v.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp response
+	if err := v.decoder.Decode(&resp); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.ID != req.ID {
+		return fmt.Errorf("unexpected response id: %d", resp.ID)
+	}
+
+	if resp.Error != nil {
+		if resp.Error.Code == codeKeyNotFound {
+			return vault.ErrKeyNotFound
+		}
+
+		return resp.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// Close asks the plugin to close its backend and waits for it to exit.
+func (v *Vault) Close() error {
+	v.mu.Lock()
+	closed := v.closed
+	v.mu.Unlock()
+
+	if closed {
+		return nil
+	}
+
+	err := v.call(methodClose, nil, nil)
+
+	v.mu.Lock()
+	v.closed = true
+	v.mu.Unlock()
+
+	v.stdin.Close()
+
+	if waitErr := v.wait(); waitErr != nil && err == nil {
+		err = fmt.Errorf("plugin failed: %w", waitErr)
+	}
+
+	return err
+}
+
+func (v *Vault) ListKeys() ([][]byte, error) {
+	var list keyList
+	if err := v.call(methodListKeys, nil, &list); err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	return list.Keys, nil
+}
+
+func (v *Vault) GetKey(keyID []byte) ([]byte, []byte, error) {
+	var entry keyEntry
+
+	err := v.call(methodGetKey, keyIDParams{KeyID: keyID}, &entry)
+	if errors.Is(err, vault.ErrKeyNotFound) {
+		return nil, nil, err
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	return entry.EncryptedKey, entry.EncryptedValue, nil
+}
+
+func (v *Vault) SetKey(keyID []byte, encryptedKey []byte, encryptedValue []byte) error {
+	entry := keyEntry{KeyID: keyID, EncryptedKey: encryptedKey, EncryptedValue: encryptedValue}
+
+	if err := v.call(methodSetKey, entry, nil); err != nil {
+		return fmt.Errorf("failed to write key: %w", err)
+	}
+
+	return nil
+}
+
+func (v *Vault) DeleteKey(keyID []byte) error {
+	err := v.call(methodDeleteKey, keyIDParams{KeyID: keyID}, nil)
+	if errors.Is(err, vault.ErrKeyNotFound) {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("failed to delete key: %w", err)
+	}
+
+	return nil
+}
+
+func (v *Vault) GetTestKey() ([]byte, error) {
+	var key testKey
+	if err := v.call(methodGetTestKey, nil, &key); err != nil {
+		return nil, err
+	}
+
+	return key.Value, nil
+}
+
+func (v *Vault) SetTestKey(value []byte) error {
+	return v.call(methodSetTestKey, testKey{Value: value}, nil)
+}
+
+func (v renamingVault) RenameKey(oldKeyID []byte, newKeyID []byte) error {
+	err := v.call(methodRenameKey, renameKeyParams{OldKeyID: oldKeyID, NewKeyID: newKeyID}, nil)
+	if errors.Is(err, vault.ErrKeyNotFound) {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("failed to rename key: %w", err)
+	}
+
+	return nil
+}
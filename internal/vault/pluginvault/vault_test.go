@@ -0,0 +1,321 @@
+package pluginvault
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/gopass/internal/vault"
+	"github.com/vitalvas/gopass/internal/vault/filevault"
+)
+
+// connectServer connects a client to Serve running in a goroutine.
+func connectServer(t *testing.T, open OpenFunc) (vault.Vault, error) {
+	t.Helper()
+
+	requestReader, requestWriter := io.Pipe()
+	responseReader, responseWriter := io.Pipe()
+
+	done := make(chan error, 1)
+
+	go func() {
+		err := Serve(requestReader, responseWriter, open)
+		responseWriter.Close()
+		done <- err
+	}()
+
+	return connect(requestWriter, responseReader, func() error { return <-done }, "plugin+test:///vault")
+}
+
+func openFileVault(t *testing.T) OpenFunc {
+	dir := t.TempDir()
+
+	return func(address string) (vault.Vault, error) {
+		if address != "plugin+test:///vault" {
+			return nil, errors.New("unexpected address")
+		}
+
+		return filevault.New(dir), nil
+	}
+}
+
+// testVault runs the vault operations on a plugin backed by a file vault.
+func testVault(t *testing.T, v vault.Vault) {
+	t.Helper()
+
+	_, err := v.GetTestKey()
+	assert.Error(t, err)
+
+	require.NoError(t, v.SetTestKey([]byte("test-value")))
+
+	value, err := v.GetTestKey()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("test-value"), value)
+
+	keys, err := v.ListKeys()
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	require.NoError(t, v.SetKey([]byte{0x01, 0x02, 0x03}, []byte("key1"), []byte("value1")))
+	require.NoError(t, v.SetKey([]byte{0x04, 0x05, 0x06}, []byte("key2"), []byte("value2")))
+
+	keys, err = v.ListKeys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, [][]byte{{0x01, 0x02, 0x03}, {0x04, 0x05, 0x06}}, keys)
+
+	key, value, err := v.GetKey([]byte{0x01, 0x02, 0x03})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key1"), key)
+	assert.Equal(t, []byte("value1"), value)
+
+	require.NoError(t, v.DeleteKey([]byte{0x01, 0x02, 0x03}))
+
+	_, _, err = v.GetKey([]byte{0x01, 0x02, 0x03})
+	assert.ErrorIs(t, err, vault.ErrKeyNotFound)
+
+	assert.ErrorIs(t, v.DeleteKey([]byte{0x01, 0x02, 0x03}), vault.ErrKeyNotFound)
+}
+
+func TestVault(t *testing.T) {
+	v, err := connectServer(t, openFileVault(t))
+	require.NoError(t, err)
+
+	testVault(t, v)
+
+	t.Run("rename", func(t *testing.T) {
+		renamer, ok := v.(vault.KeyRenamer)
+		require.True(t, ok)
+
+		require.NoError(t, renamer.RenameKey([]byte{0x04, 0x05, 0x06}, []byte{0x07, 0x08, 0x09}))
+
+		key, _, err := v.GetKey([]byte{0x07, 0x08, 0x09})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("key2"), key)
+
+		assert.ErrorIs(t, renamer.RenameKey([]byte{0x04, 0x05, 0x06}, []byte{0x0a, 0x0b, 0x0c}), vault.ErrKeyNotFound)
+	})
+
+	require.NoError(t, v.Close())
+	require.NoError(t, v.Close())
+
+	_, err = v.ListKeys()
+	assert.EqualError(t, err, "failed to list keys: plugin is closed")
+}
+
+func TestVaultWithoutRename(t *testing.T) {
+	dir := t.TempDir()
+
+	v, err := connectServer(t, func(_ string) (vault.Vault, error) {
+		// Hides RenameKey of the file vault.
+		return struct{ vault.Vault }{filevault.New(dir)}, nil
+	})
+	require.NoError(t, err)
+
+	defer v.Close()
+
+	_, ok := v.(vault.KeyRenamer)
+	assert.False(t, ok)
+}
+
+func TestConnectErrors(t *testing.T) {
+	t.Run("open failure", func(t *testing.T) {
+		_, err := connectServer(t, func(_ string) (vault.Vault, error) {
+			return nil, errors.New("storage unavailable")
+		})
+		assert.EqualError(t, err, "failed to initialize plugin: plugin: storage unavailable")
+	})
+
+	t.Run("protocol version mismatch", func(t *testing.T) {
+		requestReader, requestWriter := io.Pipe()
+		responseReader, responseWriter := io.Pipe()
+
+		go func() {
+			defer responseWriter.Close()
+
+			var req request
+			if json.NewDecoder(requestReader).Decode(&req) != nil {
+				return
+			}
+
+			json.NewEncoder(responseWriter).Encode(response{
+				JSONRPC: jsonrpcVersion,
+				ID:      req.ID,
+				Result:  json.RawMessage(`{"protocol_version":2,"capabilities":[]}`),
+			})
+
+			io.Copy(io.Discard, requestReader)
+		}()
+
+		_, err := connect(requestWriter, responseReader, func() error { return nil }, "plugin+test:///vault")
+		assert.EqualError(t, err, "failed to initialize plugin: unsupported protocol version: 2")
+	})
+
+	t.Run("plugin exits", func(t *testing.T) {
+		requestReader, requestWriter := io.Pipe()
+		responseReader, responseWriter := io.Pipe()
+
+		go func() {
+			requestReader.Close()
+			responseWriter.Close()
+		}()
+
+		_, err := connect(requestWriter, responseReader, func() error { return nil }, "plugin+test:///vault")
+		assert.ErrorContains(t, err, "failed to initialize plugin:")
+	})
+}
+
+func TestServe(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests []string
+		errors   []int
+	}{
+		{
+			name:     "not initialized",
+			requests: []string{`{"jsonrpc":"2.0","id":1,"method":"list_keys"}`},
+			errors:   []int{codeBackendError},
+		},
+		{
+			name: "unsupported protocol version",
+			requests: []string{
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocol_version":99,"address":"plugin+test:///vault"}}`,
+			},
+			errors: []int{codeBackendError},
+		},
+		{
+			name: "unknown method",
+			requests: []string{
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocol_version":1,"address":"plugin+test:///vault"}}`,
+				`{"jsonrpc":"2.0","id":2,"method":"unknown"}`,
+			},
+			errors: []int{0, codeUnknownMethod},
+		},
+		{
+			name: "invalid params",
+			requests: []string{
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocol_version":1,"address":"plugin+test:///vault"}}`,
+				`{"jsonrpc":"2.0","id":2,"method":"get_key","params":{"key_id":"!"}}`,
+			},
+			errors: []int{0, codeInvalidParams},
+		},
+		{
+			name: "key not found",
+			requests: []string{
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocol_version":1,"address":"plugin+test:///vault"}}`,
+				`{"jsonrpc":"2.0","id":2,"method":"get_key","params":{"key_id":"AQID"}}`,
+				`{"jsonrpc":"2.0","id":3,"method":"close"}`,
+				`{"jsonrpc":"2.0","id":4,"method":"list_keys"}`,
+			},
+			errors: []int{0, codeKeyNotFound, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+
+			in := strings.NewReader(strings.Join(tt.requests, "\n") + "\n")
+			require.NoError(t, Serve(in, &out, openFileVault(t)))
+
+			scanner := bufio.NewScanner(strings.NewReader(out.String()))
+
+			var codes []int
+
+			for scanner.Scan() {
+				var resp response
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &resp))
+
+				assert.Equal(t, jsonrpcVersion, resp.JSONRPC)
+				assert.Equal(t, uint64(len(codes)+1), resp.ID)
+
+				if resp.Error != nil {
+					codes = append(codes, resp.Error.Code)
+				} else {
+					codes = append(codes, 0)
+				}
+			}
+
+			assert.Equal(t, tt.errors, codes)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	tests := []struct {
+		name    string
+		address string
+		errMsg  string
+	}{
+		{
+			name:    "not a plugin",
+			address: "file:///vault",
+			errMsg:  "invalid plugin address: file:///vault",
+		},
+		{
+			name:    "invalid name",
+			address: "plugin+my.store:///vault",
+			errMsg:  "invalid plugin address: plugin+my.store:///vault",
+		},
+		{
+			name:    "missing plugin",
+			address: "plugin+missing:///vault",
+			errMsg:  `plugin missing not found: exec: "gopass-backend-missing": executable file not found in $PATH`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := url.Parse(tt.address)
+			require.NoError(t, err)
+
+			_, err = New(parsed)
+			assert.EqualError(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestReferencePlugin(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+
+	binDir := t.TempDir()
+
+	build := exec.Command(goBin, "build", "-o", filepath.Join(binDir, "gopass-backend-file"), "github.com/vitalvas/gopass/cmd/gopass-backend-file")
+	build.Stderr = os.Stderr
+	require.NoError(t, build.Run())
+
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	storagePath := filepath.Join(t.TempDir(), "vault")
+
+	address, err := url.Parse("plugin+file://" + storagePath)
+	require.NoError(t, err)
+
+	v, err := New(address)
+	require.NoError(t, err)
+
+	testVault(t, v)
+
+	_, ok := v.(vault.KeyRenamer)
+	assert.True(t, ok)
+
+	require.NoError(t, v.Close())
+
+	// The keys are stored by the file vault.
+	keys, err := filevault.New(storagePath).ListKeys()
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{{0x04, 0x05, 0x06}}, keys)
+}
@@ -0,0 +1,307 @@
+package s3vault
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+)
+
+var (
+	errNotFound = errors.New("object not found")
+
+	// ErrConflict is returned when an object was changed by another writer
+	// since it was read.
+	ErrConflict = errors.New("key was modified by another writer")
+)
+
+// client is a minimal S3 client for the object operations of the vault,
+// signing requests with AWS Signature Version 4.
+type client struct {
+	endpoint    *url.URL
+	bucket      string
+	pathStyle   bool
+	credentials Credentials
+	http        *http.Client
+	now         func() time.Time
+}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type listResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// objectURL returns the URL of an object, or of the bucket for an empty key.
+func (c *client) objectURL(key string) *url.URL {
+	u := *c.endpoint
+
+	if c.pathStyle {
+		u.Path = "/" + c.bucket
+		if key != "" {
+			u.Path += "/" + key
+		}
+	} else {
+		u.Host = c.bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+
+	return &u
+}
+
+// getObject returns the content and ETag of an object.
+func (c *client) getObject(key string) ([]byte, string, error) {
+	resp, err := c.do(http.MethodGet, c.objectURL(key), nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read object: %w", err)
+	}
+
+	return data, resp.Header.Get("ETag"), nil
+}
+
+// headObject returns the ETag of an object.
+func (c *client) headObject(key string) (string, error) {
+	resp, err := c.do(http.MethodHead, c.objectURL(key), nil, nil)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	return resp.Header.Get("ETag"), nil
+}
+
+// putObject writes an object and returns its new ETag. The conditions are
+// If-Match and If-None-Match headers.
+func (c *client) putObject(key string, data []byte, conditions http.Header) (string, error) {
+	resp, err := c.do(http.MethodPut, c.objectURL(key), data, conditions)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *client) deleteObject(key string, conditions http.Header) error {
+	resp, err := c.do(http.MethodDelete, c.objectURL(key), nil, conditions)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// listObjects returns the keys of all objects with the prefix.
+func (c *client) listObjects(prefix string) ([]string, error) {
+	var keys []string
+
+	token := ""
+
+	for {
+		u := c.objectURL("")
+		if !c.pathStyle {
+			u.Path = "/"
+		}
+
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		// Sent in canonical form, so the signed query matches the sent one.
+		u.RawQuery = canonicalQuery(query)
+
+		resp, err := c.do(http.MethodGet, u, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listResult
+
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse object list: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+
+		token = result.NextContinuationToken
+	}
+}
+
+// do sends a signed request and turns error responses into errors.
+func (c *client) do(method string, u *url.URL, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	c.sign(req, body, c.now())
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, errNotFound
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return nil, ErrConflict
+	}
+
+	var s3Err s3Error
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return nil, fmt.Errorf("s3: %s: %s", s3Err.Code, s3Err.Message)
+	}
+
+	return nil, fmt.Errorf("s3: unexpected status: %s", resp.Status)
+}
+
+// sign adds the AWS Signature Version 4 headers. All headers set on the
+// request are signed.
+func (c *client) sign(req *http.Request, body []byte, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	date := now.Format("20060102")
+
+	payloadHash := sha256.Sum256(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	if c.credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	names := slices.Sorted(maps.Keys(headers))
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(headers[name]))
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, c.credentials.Region)
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := strings.Join([]string{signingAlgorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, c.credentials.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, c.credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+func canonicalQuery(query url.Values) string {
+	parts := make([]string, 0, len(query))
+
+	for name, values := range query {
+		for _, value := range values {
+			parts = append(parts, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+
+	slices.Sort(parts)
+
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything except unreserved characters, as
+// required for the canonical request.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+
+	for _, c := range []byte(s) {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,177 @@
+package s3vault
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const defaultRegion = "us-east-1"
+
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+}
+
+// config is the connection configuration resolved from the vault address,
+// the environment and the shared AWS config files.
+type config struct {
+	credentials Credentials
+	endpoint    string
+}
+
+// resolveConfig follows the AWS conventions: the profile and region in the
+// address take precedence, then AWS_* variables, then the profile in
+// ~/.aws/credentials and ~/.aws/config.
+func resolveConfig(query url.Values, getenv func(string) string) (config, error) {
+	var cfg config
+
+	profile := query.Get("profile")
+	fromAddress := profile != ""
+
+	if profile == "" {
+		profile = getenv("AWS_PROFILE")
+	}
+
+	if profile == "" {
+		profile = "default"
+	}
+
+	home := getenv("HOME")
+
+	credentialsFile := getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = home + "/.aws/credentials"
+	}
+
+	configFile := getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = home + "/.aws/config"
+	}
+
+	credentialsINI, err := readINI(credentialsFile)
+	if err != nil {
+		return cfg, err
+	}
+
+	configINI, err := readINI(configFile)
+	if err != nil {
+		return cfg, err
+	}
+
+	profileCredentials := credentialsINI[profile]
+
+	configSection := "profile " + profile
+	if profile == "default" {
+		configSection = profile
+	}
+
+	profileConfig := configINI[configSection]
+
+	switch {
+	case !fromAddress && getenv("AWS_ACCESS_KEY_ID") != "":
+		cfg.credentials = Credentials{
+			AccessKeyID:     getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    getenv("AWS_SESSION_TOKEN"),
+		}
+
+	case profileCredentials["aws_access_key_id"] != "":
+		cfg.credentials = Credentials{
+			AccessKeyID:     profileCredentials["aws_access_key_id"],
+			SecretAccessKey: profileCredentials["aws_secret_access_key"],
+			SessionToken:    profileCredentials["aws_session_token"],
+		}
+
+	case profileConfig["aws_access_key_id"] != "":
+		cfg.credentials = Credentials{
+			AccessKeyID:     profileConfig["aws_access_key_id"],
+			SecretAccessKey: profileConfig["aws_secret_access_key"],
+			SessionToken:    profileConfig["aws_session_token"],
+		}
+	}
+
+	if cfg.credentials.AccessKeyID == "" || cfg.credentials.SecretAccessKey == "" {
+		return cfg, errors.New("no S3 credentials found, set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or configure a profile")
+	}
+
+	cfg.credentials.Region = firstNonEmpty(
+		query.Get("region"),
+		getenv("AWS_REGION"),
+		getenv("AWS_DEFAULT_REGION"),
+		profileConfig["region"],
+		defaultRegion,
+	)
+
+	cfg.endpoint = firstNonEmpty(
+		query.Get("endpoint"),
+		getenv("AWS_ENDPOINT_URL_S3"),
+		getenv("AWS_ENDPOINT_URL"),
+		profileConfig["endpoint_url"],
+	)
+
+	return cfg, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// readINI reads the sections of an AWS config file. A missing file has no
+// sections.
+func readINI(path string) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return sections, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var section map[string]string
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+			continue
+
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+
+			section = sections[name]
+			if section == nil {
+				section = make(map[string]string)
+				sections[name] = section
+			}
+
+		case section != nil:
+			name, value, ok := strings.Cut(line, "=")
+			if ok {
+				section[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return sections, nil
+}
@@ -0,0 +1,113 @@
+package s3vault
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	credentialsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte(`
+[default]
+aws_access_key_id = DEFAULTKEY
+aws_secret_access_key = defaultsecret
+
+# team profile
+[team]
+aws_access_key_id=TEAMKEY
+aws_secret_access_key=teamsecret
+aws_session_token=teamtoken
+`), 0600))
+
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+[default]
+region = eu-central-1
+
+[profile team]
+region = eu-west-1
+endpoint_url = http://127.0.0.1:9000
+
+[profile inline]
+aws_access_key_id = INLINEKEY
+aws_secret_access_key = inlinesecret
+`), 0600))
+
+	files := map[string]string{
+		"AWS_SHARED_CREDENTIALS_FILE": credentialsFile,
+		"AWS_CONFIG_FILE":             configFile,
+	}
+
+	getenv := func(env map[string]string) func(string) string {
+		return func(name string) string {
+			if value, ok := env[name]; ok {
+				return value
+			}
+
+			return files[name]
+		}
+	}
+
+	t.Run("environment", func(t *testing.T) {
+		cfg, err := resolveConfig(url.Values{}, getenv(map[string]string{
+			"AWS_ACCESS_KEY_ID":     "ENVKEY",
+			"AWS_SECRET_ACCESS_KEY": "envsecret",
+			"AWS_REGION":            "us-west-2",
+			"AWS_ENDPOINT_URL_S3":   "http://localhost:9000",
+		}))
+		require.NoError(t, err)
+
+		assert.Equal(t, Credentials{AccessKeyID: "ENVKEY", SecretAccessKey: "envsecret", Region: "us-west-2"}, cfg.credentials)
+		assert.Equal(t, "http://localhost:9000", cfg.endpoint)
+	})
+
+	t.Run("default profile", func(t *testing.T) {
+		cfg, err := resolveConfig(url.Values{}, getenv(nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, Credentials{AccessKeyID: "DEFAULTKEY", SecretAccessKey: "defaultsecret", Region: "eu-central-1"}, cfg.credentials)
+		assert.Empty(t, cfg.endpoint)
+	})
+
+	t.Run("profile from environment", func(t *testing.T) {
+		cfg, err := resolveConfig(url.Values{}, getenv(map[string]string{"AWS_PROFILE": "team"}))
+		require.NoError(t, err)
+
+		assert.Equal(t, Credentials{AccessKeyID: "TEAMKEY", SecretAccessKey: "teamsecret", SessionToken: "teamtoken", Region: "eu-west-1"}, cfg.credentials)
+		assert.Equal(t, "http://127.0.0.1:9000", cfg.endpoint)
+	})
+
+	t.Run("address overrides environment", func(t *testing.T) {
+		query := url.Values{"profile": {"inline"}, "region": {"ap-south-1"}, "endpoint": {"https://minio.example.com"}}
+
+		cfg, err := resolveConfig(query, getenv(map[string]string{
+			"AWS_ACCESS_KEY_ID":     "ENVKEY",
+			"AWS_SECRET_ACCESS_KEY": "envsecret",
+		}))
+		require.NoError(t, err)
+
+		assert.Equal(t, Credentials{AccessKeyID: "INLINEKEY", SecretAccessKey: "inlinesecret", Region: "ap-south-1"}, cfg.credentials)
+		assert.Equal(t, "https://minio.example.com", cfg.endpoint)
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		_, err := resolveConfig(url.Values{"profile": {"missing"}}, getenv(nil))
+		assert.Error(t, err)
+
+		cfg, err := resolveConfig(url.Values{}, getenv(map[string]string{
+			"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(dir, "missing"),
+			"AWS_CONFIG_FILE":             filepath.Join(dir, "missing"),
+			"AWS_ACCESS_KEY_ID":           "ENVKEY",
+			"AWS_SECRET_ACCESS_KEY":       "envsecret",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, defaultRegion, cfg.credentials.Region)
+	})
+}
@@ -0,0 +1,90 @@
+package s3vault
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vitalvas/gopass/internal/vault"
+	"github.com/vitalvas/gopass/internal/vault/filevault"
+)
+
+func (v *Vault) objectKey(keyID []byte) string {
+	return v.prefix + filevault.KeyPath(keyID)
+}
+
+func (v *Vault) ListKeys() ([][]byte, error) {
+	objects, err := v.client.listObjects(v.prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	response := make([][]byte, 0, len(objects))
+
+	for _, object := range objects {
+		if keyID, ok := filevault.ParseKeyPath(strings.TrimPrefix(object, v.prefix)); ok {
+			response = append(response, keyID)
+		}
+	}
+
+	return response, nil
+}
+
+func (v *Vault) GetKey(keyID []byte) ([]byte, []byte, error) {
+	key := v.objectKey(keyID)
+
+	data, etag, err := v.client.getObject(key)
+	if errors.Is(err, errNotFound) {
+		v.setETag(key, "")
+		return nil, nil, vault.ErrKeyNotFound
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	v.setETag(key, etag)
+
+	return filevault.DecodeEntry(data)
+}
+
+func (v *Vault) SetKey(keyID []byte, encryptedKey []byte, encryptedValue []byte) error {
+	key := v.objectKey(keyID)
+
+	etag, err := v.client.putObject(key, filevault.EncodeEntry(encryptedKey, encryptedValue), v.conditions(key))
+	if err != nil {
+		v.forgetETag(key)
+		return err
+	}
+
+	v.setETag(key, etag)
+
+	return nil
+}
+
+func (v *Vault) DeleteKey(keyID []byte) error {
+	key := v.objectKey(keyID)
+
+	etag, seen := v.etag(key)
+
+	if !seen {
+		var err error
+
+		etag, err = v.client.headObject(key)
+		if err != nil && !errors.Is(err, errNotFound) {
+			return fmt.Errorf("failed to check key: %w", err)
+		}
+	}
+
+	if etag == "" {
+		return vault.ErrKeyNotFound
+	}
+
+	if err := v.client.deleteObject(key, http.Header{"If-Match": []string{etag}}); err != nil {
+		v.forgetETag(key)
+		return err
+	}
+
+	v.setETag(key, "")
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package s3vault
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type fakeObject struct {
+	data []byte
+	etag string
+}
+
+// fakeS3 is a local stand-in for an S3 compatible server such as MinIO,
+// with path style addressing, conditional writes and paged listing.
+type fakeS3 struct {
+	bucket    string
+	accessKey string
+	pageSize  int
+
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+func newFakeS3(bucket string, accessKey string) *fakeS3 {
+	return &fakeS3{
+		bucket:    bucket,
+		accessKey: accessKey,
+		pageSize:  2,
+		objects:   make(map[string]fakeObject),
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, http.StatusText(status))
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Authorization"), "Credential="+s.accessKey+"/") {
+		writeS3Error(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	bodyHash := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(bodyHash[:]) {
+		writeS3Error(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch")
+		return
+	}
+
+	rest, ok := strings.CutPrefix(r.URL.Path, "/"+s.bucket)
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	key := strings.TrimPrefix(rest, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, exists := s.objects[key]
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		s.list(w, r)
+
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		if !exists {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+
+		w.Header().Set("ETag", object.etag)
+
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+
+	case r.Method == http.MethodPut:
+		if match := r.Header.Get("If-Match"); match != "" && (!exists || match != object.etag) {
+			writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+
+		sum := md5.Sum(body)
+		etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+		s.objects[key] = fakeObject{data: body, etag: etag}
+		w.Header().Set("ETag", etag)
+
+	case r.Method == http.MethodDelete:
+		if match := r.Header.Get("If-Match"); match != "" {
+			if !exists {
+				writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+				return
+			}
+
+			if match != object.etag {
+				writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+				return
+			}
+		}
+
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func (s *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("list-type") != "2" {
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+		return
+	}
+
+	var keys []string
+
+	for key := range s.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > query.Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	type content struct {
+		Key string `xml:"Key"`
+	}
+
+	result := struct {
+		XMLName               xml.Name  `xml:"ListBucketResult"`
+		Contents              []content `xml:"Contents"`
+		IsTruncated           bool      `xml:"IsTruncated"`
+		NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+	}{}
+
+	if len(keys) > s.pageSize {
+		keys = keys[:s.pageSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+
+	for _, key := range keys {
+		result.Contents = append(result.Contents, content{Key: key})
+	}
+
+	xml.NewEncoder(w).Encode(result)
+}
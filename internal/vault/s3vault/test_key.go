@@ -0,0 +1,30 @@
+package s3vault
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const testKeyName = ".test_key"
+
+func (v *Vault) GetTestKey() ([]byte, error) {
+	data, _, err := v.client.getObject(v.prefix + testKeyName)
+	if errors.Is(err, errNotFound) {
+		return nil, errors.New("test key not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return base64.RawURLEncoding.DecodeString(string(data))
+}
+
+func (v *Vault) SetTestKey(value []byte) error {
+	encoded := base64.RawURLEncoding.EncodeToString(value)
+
+	if _, err := v.client.putObject(v.prefix+testKeyName, []byte(encoded), nil); err != nil {
+		return fmt.Errorf("failed to set test key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+// Package s3vault stores a vault in an S3 compatible bucket, with the key
+// layout of the file backend. Writes are conditional on the ETag of the last
+// read, so concurrent writers are detected instead of overwritten.
+package s3vault
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ vault.Vault = (*Vault)(nil)
+)
+
+type Vault struct {
+	client *client
+	prefix string
+
+	mu sync.Mutex
+	// etags holds the ETag of every object read or written, an empty ETag
+	// means the object is known not to exist.
+	etags map[string]string
+}
+
+// New opens the vault of an s3://bucket/prefix address. The query may set
+// region, endpoint and profile.
+func New(address *url.URL) (*Vault, error) {
+	cfg, err := resolveConfig(address.Query(), os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	return newVault(address, cfg, http.DefaultClient)
+}
+
+func newVault(address *url.URL, cfg config, httpClient *http.Client) (*Vault, error) {
+	if address.Host == "" {
+		return nil, errors.New("s3 address has no bucket")
+	}
+
+	// Custom endpoints such as MinIO use path style URLs, AWS virtual hosted
+	// ones.
+	pathStyle := cfg.endpoint != ""
+
+	endpoint := cfg.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.credentials.Region)
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") || endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint: %s", endpoint)
+	}
+
+	prefix := strings.Trim(address.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &Vault{
+		client: &client{
+			endpoint:    endpointURL,
+			bucket:      address.Host,
+			pathStyle:   pathStyle,
+			credentials: cfg.credentials,
+			http:        httpClient,
+			now:         time.Now,
+		},
+		prefix: prefix,
+		etags:  make(map[string]string),
+	}, nil
+}
+
+func (v *Vault) Close() error {
+	return nil
+}
+
+func (v *Vault) etag(key string) (string, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	etag, seen := v.etags[key]
+
+	return etag, seen
+}
+
+// conditions returns the headers that make a write fail if the object changed
+// since it was last seen.
+func (v *Vault) conditions(key string) http.Header {
+	etag, seen := v.etag(key)
+
+	switch {
+	case !seen:
+		return nil
+	case etag == "":
+		return http.Header{"If-None-Match": []string{"*"}}
+	default:
+		return http.Header{"If-Match": []string{etag}}
+	}
+}
+
+func (v *Vault) setETag(key string, etag string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.etags[key] = etag
+}
+
+func (v *Vault) forgetETag(key string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	delete(v.etags, key)
+}
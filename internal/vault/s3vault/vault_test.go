@@ -0,0 +1,193 @@
+package s3vault
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestVaults returns two independent writers of the same vault. The tests
+// run against a real S3 compatible server such as MinIO if
+// GOPASS_TEST_S3_ENDPOINT and GOPASS_TEST_S3_BUCKET are set, with credentials
+// from the AWS_* variables.
+func newTestVaults(t *testing.T) (*Vault, *Vault) {
+	t.Helper()
+
+	prefix := fmt.Sprintf("gopass-test-%d", time.Now().UnixNano())
+
+	if endpoint := os.Getenv("GOPASS_TEST_S3_ENDPOINT"); endpoint != "" {
+		address := &url.URL{
+			Scheme:   "s3",
+			Host:     os.Getenv("GOPASS_TEST_S3_BUCKET"),
+			Path:     "/" + prefix,
+			RawQuery: url.Values{"endpoint": {endpoint}}.Encode(),
+		}
+
+		first, err := New(address)
+		require.NoError(t, err)
+
+		second, err := New(address)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			objects, _ := first.client.listObjects(first.prefix)
+			for _, object := range objects {
+				first.client.deleteObject(object, nil)
+			}
+		})
+
+		return first, second
+	}
+
+	server := httptest.NewServer(newFakeS3("vaults", "TESTKEY"))
+	t.Cleanup(server.Close)
+
+	cfg := config{
+		credentials: Credentials{AccessKeyID: "TESTKEY", SecretAccessKey: "testsecret", Region: defaultRegion},
+		endpoint:    server.URL,
+	}
+
+	address := &url.URL{Scheme: "s3", Host: "vaults", Path: "/" + prefix}
+
+	first, err := newVault(address, cfg, server.Client())
+	require.NoError(t, err)
+
+	second, err := newVault(address, cfg, server.Client())
+	require.NoError(t, err)
+
+	return first, second
+}
+
+func TestNew(t *testing.T) {
+	cfg := config{credentials: Credentials{AccessKeyID: "key", SecretAccessKey: "secret", Region: "eu-west-1"}}
+
+	v, err := newVault(&url.URL{Scheme: "s3", Host: "team", Path: "/shared/vault/"}, cfg, http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, "shared/vault/", v.prefix)
+	assert.False(t, v.client.pathStyle)
+	assert.Equal(t, "https://team.s3.eu-west-1.amazonaws.com/shared/vault/.test_key", v.client.objectURL(v.prefix+testKeyName).String())
+
+	v, err = newVault(&url.URL{Scheme: "s3", Host: "team"}, cfg, http.DefaultClient)
+	require.NoError(t, err)
+	assert.Empty(t, v.prefix)
+
+	_, err = newVault(&url.URL{Scheme: "s3", Path: "/vault"}, cfg, http.DefaultClient)
+	assert.Error(t, err)
+
+	cfg.endpoint = "localhost:9000"
+	_, err = newVault(&url.URL{Scheme: "s3", Host: "team"}, cfg, http.DefaultClient)
+	assert.Error(t, err)
+}
+
+func TestKeys(t *testing.T) {
+	v, other := newTestVaults(t)
+
+	_, err := v.GetTestKey()
+	assert.Error(t, err)
+
+	require.NoError(t, v.SetTestKey([]byte("test")))
+
+	testKey, err := v.GetTestKey()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("test"), testKey)
+
+	keyIDs := [][]byte{
+		{0x01, 0x02, 0x03},
+		{0x04, 0x05, 0x06},
+		{0xaa, 0xbb, 0xcc, 0xdd},
+	}
+
+	for _, keyID := range keyIDs {
+		require.NoError(t, v.SetKey(keyID, []byte("name"), keyID))
+	}
+
+	// Objects that are not keys and objects outside the prefix are ignored.
+	_, err = v.client.putObject(v.prefix+"README", []byte("readme"), nil)
+	require.NoError(t, err)
+	_, err = v.client.putObject(v.prefix+"x", []byte("x"), nil)
+	require.NoError(t, err)
+
+	keys, err := v.ListKeys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, keyIDs, keys)
+
+	encryptedKey, encryptedValue, err := v.GetKey(keyIDs[0])
+	require.NoError(t, err)
+	assert.Equal(t, []byte("name"), encryptedKey)
+	assert.Equal(t, keyIDs[0], encryptedValue)
+
+	require.NoError(t, v.SetKey(keyIDs[0], []byte("name"), []byte("updated")))
+
+	_, encryptedValue, err = v.GetKey(keyIDs[0])
+	require.NoError(t, err)
+	assert.Equal(t, []byte("updated"), encryptedValue)
+
+	require.NoError(t, v.DeleteKey(keyIDs[0]))
+	assert.EqualError(t, v.DeleteKey(keyIDs[0]), "key not found")
+
+	_, _, err = v.GetKey(keyIDs[0])
+	assert.EqualError(t, err, "key not found")
+
+	// Deleting a key that was never read checks that it exists.
+	require.NoError(t, other.DeleteKey(keyIDs[1]))
+	assert.EqualError(t, other.DeleteKey([]byte{0x07, 0x08, 0x09}), "key not found")
+
+	keys, err = v.ListKeys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, keyIDs[2:], keys)
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	keyID := []byte{0x01, 0x02, 0x03}
+
+	t.Run("update", func(t *testing.T) {
+		first, second := newTestVaults(t)
+
+		require.NoError(t, first.SetKey(keyID, []byte("name"), []byte("base")))
+
+		_, _, err := second.GetKey(keyID)
+		require.NoError(t, err)
+
+		require.NoError(t, first.SetKey(keyID, []byte("name"), []byte("first")))
+		assert.ErrorIs(t, second.SetKey(keyID, []byte("name"), []byte("second")), ErrConflict)
+
+		_, value, err := second.GetKey(keyID)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("first"), value)
+
+		require.NoError(t, second.SetKey(keyID, []byte("name"), []byte("second")))
+	})
+
+	t.Run("create", func(t *testing.T) {
+		first, second := newTestVaults(t)
+
+		_, _, err := first.GetKey(keyID)
+		require.Error(t, err)
+
+		require.NoError(t, second.SetKey(keyID, []byte("name"), []byte("second")))
+		assert.ErrorIs(t, first.SetKey(keyID, []byte("name"), []byte("first")), ErrConflict)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		first, second := newTestVaults(t)
+
+		require.NoError(t, first.SetKey(keyID, []byte("name"), []byte("base")))
+
+		_, _, err := second.GetKey(keyID)
+		require.NoError(t, err)
+
+		require.NoError(t, first.SetKey(keyID, []byte("name"), []byte("first")))
+		assert.ErrorIs(t, second.DeleteKey(keyID), ErrConflict)
+
+		_, value, err := first.GetKey(keyID)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("first"), value)
+	})
+}
@@ -0,0 +1,100 @@
+package sqlitevault
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/vitalvas/gopass/internal/vault"
+)
+
+func (v *Vault) ListKeys() ([][]byte, error) {
+	rows, err := v.db.Query("SELECT id FROM keys ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+	defer rows.Close()
+
+	response := make([][]byte, 0)
+
+	for rows.Next() {
+		var keyID []byte
+
+		if err := rows.Scan(&keyID); err != nil {
+			return nil, fmt.Errorf("failed to list keys: %w", err)
+		}
+
+		response = append(response, keyID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	return response, nil
+}
+
+func (v *Vault) GetKey(keyID []byte) ([]byte, []byte, error) {
+	var encryptedKey, encryptedValue []byte
+
+	err := v.db.QueryRow("SELECT name, value FROM keys WHERE id = ?", keyID).Scan(&encryptedKey, &encryptedValue)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, vault.ErrKeyNotFound
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	return encryptedKey, encryptedValue, nil
+}
+
+func (v *Vault) SetKey(keyID []byte, encryptedKey []byte, encryptedValue []byte) error {
+	_, err := v.db.Exec(
+		"INSERT INTO keys (id, name, value) VALUES (?, ?, ?) ON CONFLICT (id) DO UPDATE SET name = excluded.name, value = excluded.value",
+		keyID, blob(encryptedKey), blob(encryptedValue),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set key: %w", err)
+	}
+
+	return nil
+}
+
+func (v *Vault) DeleteKey(keyID []byte) error {
+	result, err := v.db.Exec("DELETE FROM keys WHERE id = ?", keyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete key: %w", err)
+	}
+
+	if count, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete key: %w", err)
+	} else if count == 0 {
+		return vault.ErrKeyNotFound
+	}
+
+	return nil
+}
+
+func (v *Vault) RenameKey(oldKeyID []byte, newKeyID []byte) error {
+	return v.tx(func(tx *sql.Tx) error {
+		var exists bool
+
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM keys WHERE id = ?)", newKeyID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check key: %w", err)
+		} else if exists {
+			return errors.New("key already exists")
+		}
+
+		result, err := tx.Exec("UPDATE keys SET id = ? WHERE id = ?", newKeyID, oldKeyID)
+		if err != nil {
+			return fmt.Errorf("failed to rename key: %w", err)
+		}
+
+		if count, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to rename key: %w", err)
+		} else if count == 0 {
+			return vault.ErrKeyNotFound
+		}
+
+		return nil
+	})
+}
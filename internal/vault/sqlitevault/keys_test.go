@@ -0,0 +1,79 @@
+package sqlitevault
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeys(t *testing.T) {
+	keyID := []byte{0x01, 0x02, 0x03}
+
+	t.Run("set get list delete", func(t *testing.T) {
+		v, _ := newTestVault(t)
+
+		keys, err := v.ListKeys()
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+
+		require.NoError(t, v.SetKey(keyID, []byte("name"), []byte("value")))
+		require.NoError(t, v.SetKey([]byte{0xff}, []byte("other"), []byte("other")))
+
+		encryptedKey, encryptedValue, err := v.GetKey(keyID)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("name"), encryptedKey)
+		assert.Equal(t, []byte("value"), encryptedValue)
+
+		require.NoError(t, v.SetKey(keyID, []byte("name"), []byte("updated")))
+
+		_, encryptedValue, err = v.GetKey(keyID)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("updated"), encryptedValue)
+
+		keys, err = v.ListKeys()
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{keyID, {0xff}}, keys)
+
+		require.NoError(t, v.DeleteKey(keyID))
+
+		_, _, err = v.GetKey(keyID)
+		assert.EqualError(t, err, "key not found")
+		assert.EqualError(t, v.DeleteKey(keyID), "key not found")
+	})
+
+	t.Run("empty value", func(t *testing.T) {
+		v, _ := newTestVault(t)
+
+		require.NoError(t, v.SetKey(keyID, []byte("name"), nil))
+
+		_, encryptedValue, err := v.GetKey(keyID)
+		require.NoError(t, err)
+		assert.Empty(t, encryptedValue)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		v, _ := newTestVault(t)
+
+		newKeyID := []byte{0x04, 0x05, 0x06}
+
+		require.NoError(t, v.SetKey(keyID, []byte("name"), []byte("value")))
+		require.NoError(t, v.SetKey([]byte{0xff}, []byte("other"), []byte("other")))
+
+		require.NoError(t, v.RenameKey(keyID, newKeyID))
+
+		_, _, err := v.GetKey(keyID)
+		assert.Error(t, err)
+
+		_, encryptedValue, err := v.GetKey(newKeyID)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), encryptedValue)
+
+		assert.EqualError(t, v.RenameKey(keyID, []byte{0x07}), "key not found")
+		assert.EqualError(t, v.RenameKey(newKeyID, []byte{0xff}), "key already exists")
+
+		_, encryptedValue, err = v.GetKey([]byte{0xff})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("other"), encryptedValue)
+	})
+}
@@ -0,0 +1,34 @@
+package sqlitevault
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+const testKeyName = "test_key"
+
+func (v *Vault) GetTestKey() ([]byte, error) {
+	var value []byte
+
+	err := v.db.QueryRow("SELECT value FROM meta WHERE name = ?", testKeyName).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("test key not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (v *Vault) SetTestKey(value []byte) error {
+	_, err := v.db.Exec(
+		"INSERT INTO meta (name, value) VALUES (?, ?) ON CONFLICT (name) DO UPDATE SET value = excluded.value",
+		testKeyName, blob(value),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set test key: %w", err)
+	}
+
+	return nil
+}
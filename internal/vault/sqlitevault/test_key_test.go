@@ -0,0 +1,26 @@
+package sqlitevault
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestKey(t *testing.T) {
+	v, _ := newTestVault(t)
+
+	_, err := v.GetTestKey()
+	assert.Error(t, err)
+
+	require.NoError(t, v.SetTestKey([]byte("first")))
+	require.NoError(t, v.SetTestKey([]byte("second")))
+
+	value, err := v.GetTestKey()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), value)
+
+	keys, err := v.ListKeys()
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
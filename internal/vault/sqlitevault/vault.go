@@ -0,0 +1,98 @@
+// Package sqlitevault stores a vault in a single SQLite database.
+package sqlitevault
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/vitalvas/gopass/internal/vault"
+	_ "modernc.org/sqlite"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ vault.Vault      = (*Vault)(nil)
+	_ vault.KeyRenamer = (*Vault)(nil)
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS keys (
+	id    BLOB PRIMARY KEY,
+	name  BLOB NOT NULL,
+	value BLOB NOT NULL
+) WITHOUT ROWID;
+
+CREATE TABLE IF NOT EXISTS meta (
+	name  TEXT PRIMARY KEY,
+	value BLOB NOT NULL
+) WITHOUT ROWID;
+`
+
+type Vault struct {
+	db *sql.DB
+}
+
+// New opens the database at path, creating it if it does not exist.
+func New(path string) (*Vault, error) {
+	// SQLite creates the database and its WAL files with the permissions of
+	// this file.
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
+
+	query := url.Values{}
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &Vault{db: db}, nil
+}
+
+func (v *Vault) Close() error {
+	return v.db.Close()
+}
+
+// tx runs fn in a transaction, which is rolled back if fn fails.
+func (v *Vault) tx(fn func(tx *sql.Tx) error) error {
+	tx, err := v.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// blob keeps empty values from being stored as NULL.
+func blob(data []byte) []byte {
+	if data == nil {
+		return []byte{}
+	}
+
+	return data
+}
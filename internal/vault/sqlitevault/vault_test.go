@@ -0,0 +1,57 @@
+package sqlitevault
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVault(t *testing.T) (*Vault, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	v, err := New(path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { v.Close() })
+
+	return v, path
+}
+
+func TestNew(t *testing.T) {
+	t.Run("creates database", func(t *testing.T) {
+		v, path := newTestVault(t)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		var mode string
+		require.NoError(t, v.db.QueryRow("PRAGMA journal_mode").Scan(&mode))
+		assert.Equal(t, "wal", mode)
+	})
+
+	t.Run("reopen keeps data", func(t *testing.T) {
+		v, path := newTestVault(t)
+
+		require.NoError(t, v.SetKey([]byte{0x01, 0x02}, []byte("name"), []byte("value")))
+		require.NoError(t, v.Close())
+
+		reopened, err := New(path)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		_, value, err := reopened.GetKey([]byte{0x01, 0x02})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), value)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := New(filepath.Join(t.TempDir(), "missing", "vault.db"))
+		assert.Error(t, err)
+	})
+}
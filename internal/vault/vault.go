@@ -1,5 +1,10 @@
 package vault
 
+import "errors"
+
+// ErrKeyNotFound is returned by backends for a key ID that is not stored.
+var ErrKeyNotFound = errors.New("key not found")
+
 type Vault interface {
 	ListKeys() ([][]byte, error)
 	GetKey(keyID []byte) (encryptedKey []byte, encryptedValue []byte, err error)
@@ -11,3 +16,9 @@ type Vault interface {
 
 	Close() error
 }
+
+// KeyRenamer is implemented by backends that can move a stored key to a new
+// key ID without rewriting its content.
+type KeyRenamer interface {
+	RenameKey(oldKeyID []byte, newKeyID []byte) error
+}